@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// IngressPoolInfo is the status of the ingress controller of a YurtIngress in a pool.
+type IngressPoolInfo struct {
+	YurtIngress string   `json:"yurtIngress"`
+	Pool        string   `json:"pool"`
+	Ready       bool     `json:"ready"`
+	Image       string   `json:"image,omitempty"`
+	Endpoints   []string `json:"endpoints,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Message     string   `json:"message,omitempty"`
+}
+
+func newCmdIngress(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ingress",
+		Short: "Inspect the YurtIngresses",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status [NAME...]",
+		Short: "Show the per-pool ingress controllers of the YurtIngresses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			infos, err := o.getIngressStatus(context.TODO(), args)
+			if err != nil {
+				return err
+			}
+			return o.print(infos, func(w io.Writer) {
+				fmt.Fprintln(w, "YURTINGRESS\tPOOL\tREADY\tIMAGE\tENDPOINTS\tREASON")
+				for _, i := range infos {
+					endpoints := strings.Join(i.Endpoints, ",")
+					if endpoints == "" {
+						endpoints = "<none>"
+					}
+					reason := i.Reason
+					if reason == "" {
+						reason = "<none>"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\n", i.YurtIngress, i.Pool, i.Ready, i.Image, endpoints, reason)
+				}
+			})
+		},
+	})
+	return cmd
+}
+
+// getIngressStatus returns the per-pool status of the named YurtIngresses, or of all the YurtIngresses if names
+// is empty.
+func (o *Options) getIngressStatus(ctx context.Context, names []string) ([]IngressPoolInfo, error) {
+	var yings []appsv1alpha1.YurtIngress
+	if len(names) == 0 {
+		list, err := o.AppsClient.AppsV1alpha1().YurtIngresses().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		yings = list.Items
+	} else {
+		for _, name := range names {
+			ying, err := o.AppsClient.AppsV1alpha1().YurtIngresses().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			yings = append(yings, *ying)
+		}
+	}
+
+	infos := []IngressPoolInfo{}
+	for _, ying := range yings {
+		pools := map[string]appsv1alpha1.IngressPoolStatus{}
+		for _, p := range ying.Status.Pools {
+			pools[p.Name] = p
+		}
+		newInfo := func(pool string, ready bool) IngressPoolInfo {
+			info := IngressPoolInfo{YurtIngress: ying.Name, Pool: pool, Ready: ready, Image: pools[pool].Image}
+			for _, e := range pools[pool].Endpoints {
+				info.Endpoints = append(info.Endpoints, fmt.Sprintf("%s:%d", e.Address, e.Port))
+			}
+			return info
+		}
+		for _, p := range ying.Status.Conditions.IngressReadyPools {
+			infos = append(infos, newInfo(p.Name, true))
+		}
+		for _, p := range ying.Status.Conditions.IngressNotReadyPools {
+			info := newInfo(p.Pool.Name, false)
+			if p.Info != nil {
+				info.Reason = p.Info.Reason
+				info.Message = p.Info.Message
+			}
+			infos = append(infos, info)
+		}
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].YurtIngress != infos[j].YurtIngress {
+			return infos[i].YurtIngress < infos[j].YurtIngress
+		}
+		return infos[i].Pool < infos[j].Pool
+	})
+	return infos, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func newCmdNode(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Manage the NodePools of the nodes",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "assign NODE POOL",
+		Short: "Assign a node to a NodePool by its desired-nodepool label",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.assignNode(context.TODO(), args[0], args[1])
+		},
+	})
+	return cmd
+}
+
+// assignNode sets the desired-nodepool label of the node, the NodePool controller moves the node to the pool.
+func (o *Options) assignNode(ctx context.Context, node, pool string) error {
+	if _, err := o.AppsClient.AppsV1alpha1().NodePools().Get(ctx, pool, metav1.GetOptions{}); err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{appsv1alpha1.LabelDesiredNodePool: pool},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	obj, err := o.KubeClient.CoreV1().Nodes().Patch(ctx, node, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return err
+	}
+	return o.printResult(obj, fmt.Sprintf("node/%s assigned to nodepool %s", node, pool))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// PoolInfo is the membership, readiness and capacity of a NodePool.
+type PoolInfo struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Nodes         []string `json:"nodes"`
+	ReadyNodes    int      `json:"readyNodes"`
+	NotReadyNodes int      `json:"notReadyNodes"`
+	// Capacity is the sum of the allocatable resources of the nodes in the pool.
+	Capacity corev1.ResourceList `json:"capacity"`
+}
+
+func newCmdPools(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pools [NAME...]",
+		Short: "Show the nodes, readiness and capacity of the NodePools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pools, err := o.getPools(context.TODO(), args)
+			if err != nil {
+				return err
+			}
+			return o.print(pools, func(w io.Writer) {
+				fmt.Fprintln(w, "NAME\tTYPE\tREADY\tNOT-READY\tCPU\tMEMORY\tPODS\tNODES")
+				for _, p := range pools {
+					nodes := strings.Join(p.Nodes, ",")
+					if nodes == "" {
+						nodes = "<none>"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n", p.Name, p.Type, p.ReadyNodes, p.NotReadyNodes,
+						quantityString(p.Capacity, corev1.ResourceCPU), quantityString(p.Capacity, corev1.ResourceMemory),
+						quantityString(p.Capacity, corev1.ResourcePods), nodes)
+				}
+			})
+		},
+	}
+}
+
+// getPools returns the PoolInfos of the named NodePools, or of all the NodePools if names is empty.
+// The members of a pool are the nodes labeled with the pool.
+func (o *Options) getPools(ctx context.Context, names []string) ([]PoolInfo, error) {
+	var nps []appsv1alpha1.NodePool
+	if len(names) == 0 {
+		list, err := o.AppsClient.AppsV1alpha1().NodePools().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		nps = list.Items
+	} else {
+		for _, name := range names {
+			np, err := o.AppsClient.AppsV1alpha1().NodePools().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			nps = append(nps, *np)
+		}
+	}
+	nodes, err := o.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: appsv1alpha1.LabelCurrentNodePool})
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make([]PoolInfo, 0, len(nps))
+	for _, np := range nps {
+		p := PoolInfo{Name: np.Name, Type: string(np.Spec.Type), Nodes: []string{}, Capacity: corev1.ResourceList{}}
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if node.Labels[appsv1alpha1.LabelCurrentNodePool] != np.Name {
+				continue
+			}
+			p.Nodes = append(p.Nodes, node.Name)
+			if isNodeReady(node) {
+				p.ReadyNodes++
+			} else {
+				p.NotReadyNodes++
+			}
+			for name, q := range node.Status.Allocatable {
+				sum := p.Capacity[name]
+				sum.Add(q)
+				p.Capacity[name] = sum
+			}
+		}
+		sort.Strings(p.Nodes)
+		pools = append(pools, p)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Name < pools[j].Name })
+	return pools, nil
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func quantityString(list corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return "0"
+	}
+	return q.String()
+}
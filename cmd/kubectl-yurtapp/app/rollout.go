@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// RevisionInfo is a revision of the workload template of a YurtAppSet or YurtAppDaemon.
+type RevisionInfo struct {
+	Revision          int64       `json:"revision"`
+	Name              string      `json:"name"`
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+}
+
+func newCmdRollout(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Manage the rollout of the workload template of a YurtAppSet or YurtAppDaemon",
+	}
+
+	history := &cobra.Command{
+		Use:   "history (yurtappset|yurtappdaemon) NAME",
+		Short: "Show the revisions of the workload template",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			own, err := o.getOwner(context.TODO(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			revisions, err := o.getRevisions(context.TODO(), own)
+			if err != nil {
+				return err
+			}
+			infos := make([]RevisionInfo, 0, len(revisions))
+			for _, r := range revisions {
+				infos = append(infos, RevisionInfo{Revision: r.Revision, Name: r.Name, CreationTimestamp: r.CreationTimestamp})
+			}
+			return o.print(infos, func(w io.Writer) {
+				fmt.Fprintln(w, "REVISION\tNAME\tAGE")
+				for _, r := range infos {
+					fmt.Fprintf(w, "%d\t%s\t%s\n", r.Revision, r.Name, duration.HumanDuration(time.Since(r.CreationTimestamp.Time)))
+				}
+			})
+		},
+	}
+
+	var toRevision int64
+	undo := &cobra.Command{
+		Use:   "undo (yurtappset|yurtappdaemon) NAME",
+		Short: "Roll the workload template back to a previous revision",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.undo(context.TODO(), args[0], args[1], toRevision)
+		},
+	}
+	undo.Flags().Int64Var(&toRevision, "to-revision", 0, "The revision to roll back to, defaults to the previous revision.")
+
+	pause := &cobra.Command{
+		Use:   "pause (yurtappset|yurtappdaemon) NAME",
+		Short: "Pause the rollout of the per-pool Deployments",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.setPaused(context.TODO(), args[0], args[1], true)
+		},
+	}
+	resume := &cobra.Command{
+		Use:   "resume (yurtappset|yurtappdaemon) NAME",
+		Short: "Resume the paused rollout of the per-pool Deployments",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.setPaused(context.TODO(), args[0], args[1], false)
+		},
+	}
+
+	cmd.AddCommand(history, undo, pause, resume)
+	return cmd
+}
+
+// undo replaces the workload template with the one saved in the revision, which is the previous revision if
+// toRevision is 0.
+func (o *Options) undo(ctx context.Context, resource, name string, toRevision int64) error {
+	own, err := o.getOwner(ctx, resource, name)
+	if err != nil {
+		return err
+	}
+	revisions, err := o.getRevisions(ctx, own)
+	if err != nil {
+		return err
+	}
+	var target *appsv1.ControllerRevision
+	if toRevision == 0 {
+		if len(revisions) < 2 {
+			return errors.New("no previous revision to roll back to")
+		}
+		target = &revisions[len(revisions)-2]
+	} else {
+		for i := range revisions {
+			if revisions[i].Revision == toRevision {
+				target = &revisions[i]
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("revision %d is not found", toRevision)
+		}
+	}
+
+	template, err := revisionTemplate(target)
+	if err != nil {
+		return err
+	}
+	// the revisions do not record paused, so a paused rollout stays paused
+	if dt := own.template.DeploymentTemplate; dt != nil && dt.Spec.Paused {
+		if _, ok := template["deploymentTemplate"]; ok {
+			if err := unstructured.SetNestedField(template, true, "deploymentTemplate", "spec", "paused"); err != nil {
+				return err
+			}
+		}
+	}
+	patch, err := json.Marshal([]map[string]interface{}{{"op": "replace", "path": "/spec/workloadTemplate", "value": template}})
+	if err != nil {
+		return err
+	}
+	obj, err := o.patch(ctx, own, types.JSONPatchType, patch)
+	if err != nil {
+		return err
+	}
+	return o.printResult(obj, fmt.Sprintf("%s %s/%s rolled back to revision %d", own.kind, own.namespace, own.name, target.Revision))
+}
+
+// revisionTemplate returns the workload template saved in the revision, whose data is the patch of the
+// YurtAppSet or YurtAppDaemon replacing the workload template.
+func revisionTemplate(revision *appsv1.ControllerRevision) (map[string]interface{}, error) {
+	var data struct {
+		Spec struct {
+			WorkloadTemplate map[string]interface{} `json:"workloadTemplate"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(revision.Data.Raw, &data); err != nil {
+		return nil, fmt.Errorf("fail to decode revision %s: %v", revision.Name, err)
+	}
+	if data.Spec.WorkloadTemplate == nil {
+		return nil, fmt.Errorf("revision %s has no workload template", revision.Name)
+	}
+	delete(data.Spec.WorkloadTemplate, "$patch")
+	return data.Spec.WorkloadTemplate, nil
+}
+
+// setPaused pauses or resumes the per-pool Deployments through the paused field of the Deployment template,
+// like kubectl rollout pause, the StatefulSet and custom templates can not be paused. The paused field is not a part
+// of the revisions, so pausing or resuming neither restarts the pods nor adds a revision.
+func (o *Options) setPaused(ctx context.Context, resource, name string, paused bool) error {
+	own, err := o.getOwner(ctx, resource, name)
+	if err != nil {
+		return err
+	}
+	if own.template.DeploymentTemplate == nil {
+		return fmt.Errorf("%s %s/%s can not be paused or resumed, only the Deployment template is supported", own.kind, own.namespace, own.name)
+	}
+	action := "resumed"
+	if paused {
+		action = "paused"
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"workloadTemplate": map[string]interface{}{
+				"deploymentTemplate": map[string]interface{}{
+					"spec": map[string]interface{}{"paused": paused},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	obj, err := o.patch(ctx, own, types.MergePatchType, patch)
+	if err != nil {
+		return err
+	}
+	return o.printResult(obj, fmt.Sprintf("%s %s/%s %s", own.kind, own.namespace, own.name, action))
+}
+
+func (o *Options) patch(ctx context.Context, own *owner, pt types.PatchType, data []byte) (runtime.Object, error) {
+	if own.kind == KindYurtAppSet {
+		return o.AppsClient.AppsV1alpha1().YurtAppSets(own.namespace).Patch(ctx, own.name, pt, data, metav1.PatchOptions{})
+	}
+	return o.AppsClient.AppsV1alpha1().YurtAppDaemons(own.namespace).Patch(ctx, own.name, pt, data, metav1.PatchOptions{})
+}
+
+// printResult prints the message in the table output, or else the changed object.
+func (o *Options) printResult(obj runtime.Object, message string) error {
+	if o.Output == OutputTable {
+		_, err := fmt.Fprintln(o.Out, message)
+		return err
+	}
+	return o.print(obj, nil)
+}
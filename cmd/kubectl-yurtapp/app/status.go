@@ -0,0 +1,216 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// The kinds which own the per-pool workloads.
+const (
+	KindYurtAppSet    = "YurtAppSet"
+	KindYurtAppDaemon = "YurtAppDaemon"
+)
+
+// RolloutStatus is the rollout status of the per-pool workloads of a YurtAppSet or YurtAppDaemon.
+type RolloutStatus struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// UpdatedRevision is the latest revision, which all the workloads are updated to.
+	UpdatedRevision string              `json:"updatedRevision"`
+	Pools           []PoolRolloutStatus `json:"pools"`
+}
+
+// PoolRolloutStatus is the rollout status of the workload of a pool.
+type PoolRolloutStatus struct {
+	Pool            string `json:"pool"`
+	Workload        string `json:"workload"`
+	Replicas        int32  `json:"replicas"`
+	ReadyReplicas   int32  `json:"readyReplicas"`
+	UpdatedReplicas int32  `json:"updatedReplicas"`
+	Revision        string `json:"revision"`
+	UpToDate        bool   `json:"upToDate"`
+}
+
+// owner is the YurtAppSet or YurtAppDaemon which owns the per-pool workloads and the revisions.
+type owner struct {
+	kind      string
+	namespace string
+	name      string
+	uid       types.UID
+	selector  *metav1.LabelSelector
+	template  appsv1alpha1.WorkloadTemplate
+}
+
+func newCmdStatus(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status (yurtappset|yurtappdaemon) NAME",
+		Short: "Show the rollout status of the per-pool workloads of a YurtAppSet or YurtAppDaemon",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := o.getRolloutStatus(context.TODO(), args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return o.print(status, func(w io.Writer) {
+				fmt.Fprintf(w, "%s %s/%s updated revision: %s\n", status.Kind, status.Namespace, status.Name, status.UpdatedRevision)
+				fmt.Fprintln(w, "POOL\tWORKLOAD\tDESIRED\tREADY\tUPDATED\tREVISION\tUP-TO-DATE")
+				for _, p := range status.Pools {
+					fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\t%t\n", p.Pool, p.Workload, p.Replicas, p.ReadyReplicas,
+						p.UpdatedReplicas, p.Revision, p.UpToDate)
+				}
+			})
+		},
+	}
+}
+
+// parseKind returns the kind of the resource argument, such as yurtappset, yurtappsets or yas.
+func parseKind(resource string) (string, error) {
+	switch strings.ToLower(resource) {
+	case "yurtappset", "yurtappsets", "yas":
+		return KindYurtAppSet, nil
+	case "yurtappdaemon", "yurtappdaemons", "yad":
+		return KindYurtAppDaemon, nil
+	}
+	return "", fmt.Errorf("unsupported resource %q, must be yurtappset or yurtappdaemon", resource)
+}
+
+func (o *Options) getOwner(ctx context.Context, resource, name string) (*owner, error) {
+	kind, err := parseKind(resource)
+	if err != nil {
+		return nil, err
+	}
+	if kind == KindYurtAppSet {
+		yas, err := o.AppsClient.AppsV1alpha1().YurtAppSets(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return &owner{kind: kind, namespace: yas.Namespace, name: yas.Name, uid: yas.UID,
+			selector: yas.Spec.Selector, template: yas.Spec.WorkloadTemplate}, nil
+	}
+	yad, err := o.AppsClient.AppsV1alpha1().YurtAppDaemons(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &owner{kind: kind, namespace: yad.Namespace, name: yad.Name, uid: yad.UID,
+		selector: yad.Spec.Selector, template: yad.Spec.WorkloadTemplate}, nil
+}
+
+// isOwnedBy returns whether the object is controlled by the owner.
+func isOwnedBy(obj metav1.Object, own *owner) bool {
+	ref := metav1.GetControllerOf(obj)
+	return ref != nil && ref.UID == own.uid
+}
+
+// getRevisions returns the ControllerRevisions of the owner sorted by their revision numbers.
+func (o *Options) getRevisions(ctx context.Context, own *owner) ([]appsv1.ControllerRevision, error) {
+	list, err := o.KubeClient.AppsV1().ControllerRevisions(own.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var revisions []appsv1.ControllerRevision
+	for i := range list.Items {
+		if isOwnedBy(&list.Items[i], own) {
+			revisions = append(revisions, list.Items[i])
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision < revisions[j].Revision })
+	return revisions, nil
+}
+
+func (o *Options) getRolloutStatus(ctx context.Context, resource, name string) (*RolloutStatus, error) {
+	own, err := o.getOwner(ctx, resource, name)
+	if err != nil {
+		return nil, err
+	}
+	if own.template.CustomTemplate != nil {
+		return nil, errors.New("the status of custom workloads is not supported")
+	}
+	revisions, err := o.getRevisions(ctx, own)
+	if err != nil {
+		return nil, err
+	}
+	status := &RolloutStatus{Kind: own.kind, Namespace: own.namespace, Name: own.name, Pools: []PoolRolloutStatus{}}
+	if len(revisions) > 0 {
+		status.UpdatedRevision = revisions[len(revisions)-1].Name
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(own.selector)
+	if err != nil {
+		return nil, err
+	}
+	opts := metav1.ListOptions{LabelSelector: selector.String()}
+	if own.template.StatefulSetTemplate != nil {
+		list, err := o.KubeClient.AppsV1().StatefulSets(own.namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			sts := &list.Items[i]
+			if !isOwnedBy(sts, own) {
+				continue
+			}
+			status.Pools = append(status.Pools, newPoolRolloutStatus(sts, "statefulset", sts.Spec.Replicas,
+				sts.Status.ReadyReplicas, sts.Status.UpdatedReplicas, status.UpdatedRevision))
+		}
+	} else {
+		list, err := o.KubeClient.AppsV1().Deployments(own.namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			deploy := &list.Items[i]
+			if !isOwnedBy(deploy, own) {
+				continue
+			}
+			status.Pools = append(status.Pools, newPoolRolloutStatus(deploy, "deployment", deploy.Spec.Replicas,
+				deploy.Status.ReadyReplicas, deploy.Status.UpdatedReplicas, status.UpdatedRevision))
+		}
+	}
+	sort.Slice(status.Pools, func(i, j int) bool { return status.Pools[i].Pool < status.Pools[j].Pool })
+	return status, nil
+}
+
+func newPoolRolloutStatus(obj metav1.Object, resource string, replicas *int32, ready, updated int32, updatedRevision string) PoolRolloutStatus {
+	s := PoolRolloutStatus{
+		Pool:            obj.GetLabels()[appsv1alpha1.PoolNameLabelKey],
+		Workload:        resource + "/" + obj.GetName(),
+		ReadyReplicas:   ready,
+		UpdatedReplicas: updated,
+		Revision:        obj.GetLabels()[appsv1alpha1.ControllerRevisionHashLabelKey],
+	}
+	if replicas != nil {
+		s.Replicas = *replicas
+	} else {
+		s.Replicas = 1
+	}
+	s.UpToDate = s.Revision == updatedRevision && s.UpdatedReplicas == s.Replicas && s.ReadyReplicas == s.Replicas
+	return s
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/client/clientset/versioned"
+)
+
+// The output formats of the commands.
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// Options is the options shared by all the commands of kubectl yurtapp.
+type Options struct {
+	Kubeconfig string
+	Context    string
+	Namespace  string
+	Output     string
+
+	Out        io.Writer
+	KubeClient kubernetes.Interface
+	AppsClient versioned.Interface
+}
+
+// NewCmdYurtApp creates the kubectl yurtapp command, which writes its output to out.
+func NewCmdYurtApp(out io.Writer) *cobra.Command {
+	return newCmdYurtApp(&Options{Out: out})
+}
+
+func newCmdYurtApp(o *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "kubectl-yurtapp",
+		Short:        "Inspect and operate the NodePools, YurtAppSets, YurtAppDaemons and YurtIngresses",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete()
+		},
+	}
+	cmd.PersistentFlags().StringVar(&o.Kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, defaults to $KUBECONFIG or ~/.kube/config.")
+	cmd.PersistentFlags().StringVar(&o.Context, "context", "", "The kubeconfig context to use.")
+	cmd.PersistentFlags().StringVarP(&o.Namespace, "namespace", "n", "", "The namespace of the YurtAppSets and YurtAppDaemons, defaults to the namespace of the context.")
+	cmd.PersistentFlags().StringVarP(&o.Output, "output", "o", OutputTable, "Output format, table, json or yaml.")
+
+	cmd.AddCommand(
+		newCmdPools(o),
+		newCmdStatus(o),
+		newCmdRollout(o),
+		newCmdIngress(o),
+		newCmdNode(o),
+	)
+	return cmd
+}
+
+// Complete validates the output format, and creates the clients from the kubeconfig unless they are set.
+func (o *Options) Complete() error {
+	switch o.Output {
+	case OutputTable, OutputJSON, OutputYAML:
+	default:
+		return fmt.Errorf("unsupported output format %q", o.Output)
+	}
+	if o.KubeClient != nil && o.AppsClient != nil {
+		if o.Namespace == "" {
+			o.Namespace = "default"
+		}
+		return nil
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.Kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: o.Context})
+	if o.Namespace == "" {
+		ns, _, err := clientConfig.Namespace()
+		if err != nil {
+			return err
+		}
+		o.Namespace = ns
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(cfg); err != nil {
+		return err
+	}
+	if o.AppsClient, err = versioned.NewForConfig(cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// print writes obj in the output format, the table is written by table.
+func (o *Options) print(obj interface{}, table func(w io.Writer)) error {
+	switch o.Output {
+	case OutputJSON:
+		encoder := json.NewEncoder(o.Out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(obj)
+	case OutputYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(data)
+		return err
+	default:
+		w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
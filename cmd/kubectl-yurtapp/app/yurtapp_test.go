@@ -0,0 +1,254 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	utilpointer "k8s.io/utils/pointer"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	appsfake "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/client/clientset/versioned/fake"
+)
+
+var ownerRef = metav1.OwnerReference{APIVersion: "apps.openyurt.io/v1alpha1", Kind: "YurtAppSet", Name: "yas",
+	UID: "yas-uid", Controller: utilpointer.BoolPtr(true)}
+
+func newNode(name, pool string, ready bool, cpu string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{appsv1alpha1.LabelCurrentNodePool: pool}},
+		Status: corev1.NodeStatus{
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+			Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+		},
+	}
+}
+
+func newYurtAppSet(image string) *appsv1alpha1.YurtAppSet {
+	return &appsv1alpha1.YurtAppSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "yas", UID: "yas-uid"},
+		Spec: appsv1alpha1.YurtAppSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "demo"}},
+			WorkloadTemplate: appsv1alpha1.WorkloadTemplate{
+				DeploymentTemplate: &appsv1alpha1.DeploymentTemplateSpec{
+					Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "demo", Image: image}}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func newRevision(name string, revision int64, image string) *appsv1.ControllerRevision {
+	template := newYurtAppSet(image).Spec.WorkloadTemplate
+	raw, _ := json.Marshal(template)
+	var data map[string]interface{}
+	_ = json.Unmarshal(raw, &data)
+	data["$patch"] = "replace"
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"workloadTemplate": data}})
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "demo"},
+			OwnerReferences: []metav1.OwnerReference{ownerRef}},
+		Revision: revision,
+		Data:     runtime.RawExtension{Raw: patch},
+	}
+}
+
+func newDeployment(name, pool, revision string, replicas, ready int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name,
+			Labels: map[string]string{"app": "demo", appsv1alpha1.PoolNameLabelKey: pool,
+				appsv1alpha1.ControllerRevisionHashLabelKey: revision},
+			OwnerReferences: []metav1.OwnerReference{ownerRef}},
+		Spec:   appsv1.DeploymentSpec{Replicas: utilpointer.Int32Ptr(replicas)},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready, UpdatedReplicas: ready},
+	}
+}
+
+// run runs the command with the fake clients and returns its output.
+func run(t *testing.T, o *Options, args ...string) string {
+	out := &bytes.Buffer{}
+	o.Out = out
+	o.Output = OutputTable
+	cmd := newCmdYurtApp(o)
+	cmd.SetArgs(args)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("fail to run %v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestPools(t *testing.T) {
+	o := &Options{
+		KubeClient: kubefake.NewSimpleClientset(newNode("n1", "beijing", true, "2"), newNode("n2", "beijing", false, "4"),
+			newNode("n3", "hangzhou", true, "1")),
+		AppsClient: appsfake.NewSimpleClientset(
+			&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "beijing"}, Spec: appsv1alpha1.NodePoolSpec{Type: appsv1alpha1.Edge}},
+			&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "empty"}, Spec: appsv1alpha1.NodePoolSpec{Type: appsv1alpha1.Cloud}}),
+	}
+
+	var pools []PoolInfo
+	if err := json.Unmarshal([]byte(run(t, o, "pools", "-o", "json")), &pools); err != nil {
+		t.Fatalf("fail to decode the pools: %v", err)
+	}
+	if len(pools) != 2 || pools[0].Name != "beijing" || pools[1].Name != "empty" {
+		t.Fatalf("unexpected pools %v", pools)
+	}
+	if p := pools[0]; strings.Join(p.Nodes, ",") != "n1,n2" || p.ReadyNodes != 1 || p.NotReadyNodes != 1 ||
+		quantityString(p.Capacity, corev1.ResourceCPU) != "6" {
+		t.Fatalf("unexpected pool beijing %v", p)
+	}
+	if p := pools[1]; len(p.Nodes) != 0 || quantityString(p.Capacity, corev1.ResourceCPU) != "0" {
+		t.Fatalf("unexpected pool empty %v", p)
+	}
+
+	out := run(t, o, "pools", "beijing")
+	if !strings.Contains(out, "NAME") || !strings.Contains(out, "n1,n2") || strings.Contains(out, "empty") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if !strings.Contains(run(t, o, "pools", "empty", "-o", "yaml"), "name: empty") {
+		t.Fatalf("expected the pool in yaml")
+	}
+}
+
+func TestStatus(t *testing.T) {
+	o := &Options{
+		Namespace: "default",
+		KubeClient: kubefake.NewSimpleClientset(newRevision("yas-1", 1, "demo:v1"), newRevision("yas-2", 2, "demo:v2"),
+			newDeployment("yas-beijing", "beijing", "yas-2", 2, 2), newDeployment("yas-hangzhou", "hangzhou", "yas-1", 3, 1)),
+		AppsClient: appsfake.NewSimpleClientset(newYurtAppSet("demo:v2")),
+	}
+
+	status, err := o.getRolloutStatus(context.TODO(), "yas", "yas")
+	if err != nil {
+		t.Fatalf("fail to get the rollout status: %v", err)
+	}
+	if status.UpdatedRevision != "yas-2" || len(status.Pools) != 2 {
+		t.Fatalf("unexpected rollout status %v", status)
+	}
+	if p := status.Pools[0]; p.Pool != "beijing" || p.Workload != "deployment/yas-beijing" || !p.UpToDate {
+		t.Fatalf("expected pool beijing up to date, got %v", p)
+	}
+	if p := status.Pools[1]; p.Pool != "hangzhou" || p.Revision != "yas-1" || p.UpToDate {
+		t.Fatalf("expected pool hangzhou not up to date, got %v", p)
+	}
+	if out := run(t, o, "status", "yurtappset", "yas"); !strings.Contains(out, "updated revision: yas-2") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+	if _, err := o.getRolloutStatus(context.TODO(), "deployment", "yas"); err == nil {
+		t.Fatalf("expected an error for the unsupported resource")
+	}
+}
+
+func TestRollout(t *testing.T) {
+	o := &Options{
+		Namespace:  "default",
+		KubeClient: kubefake.NewSimpleClientset(newRevision("yas-1", 1, "demo:v1"), newRevision("yas-2", 2, "demo:v2")),
+		AppsClient: appsfake.NewSimpleClientset(newYurtAppSet("demo:v2")),
+	}
+
+	if out := run(t, o, "rollout", "history", "yas", "yas"); !strings.Contains(out, "yas-1") || !strings.Contains(out, "yas-2") {
+		t.Fatalf("unexpected history:\n%s", out)
+	}
+
+	if out := run(t, o, "rollout", "undo", "yurtappset", "yas"); !strings.Contains(out, "rolled back to revision 1") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+	yas, err := o.AppsClient.AppsV1alpha1().YurtAppSets("default").Get(context.TODO(), "yas", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("fail to get the yurtappset: %v", err)
+	}
+	if image := yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Spec.Containers[0].Image; image != "demo:v1" {
+		t.Fatalf("expected the template rolled back to demo:v1, got %s", image)
+	}
+	if err := o.undo(context.TODO(), "yas", "yas", 3); err == nil {
+		t.Fatalf("expected an error for the missing revision")
+	}
+
+	run(t, o, "rollout", "pause", "yas", "yas")
+	yas, _ = o.AppsClient.AppsV1alpha1().YurtAppSets("default").Get(context.TODO(), "yas", metav1.GetOptions{})
+	if !yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Paused {
+		t.Fatalf("expected the deployment template paused")
+	}
+	run(t, o, "rollout", "resume", "yas", "yas")
+	yas, _ = o.AppsClient.AppsV1alpha1().YurtAppSets("default").Get(context.TODO(), "yas", metav1.GetOptions{})
+	if yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Paused {
+		t.Fatalf("expected the deployment template resumed")
+	}
+}
+
+func TestIngressStatus(t *testing.T) {
+	ying := &appsv1alpha1.YurtIngress{ObjectMeta: metav1.ObjectMeta{Name: "ying"}}
+	ying.Status.Conditions.IngressReadyPools = []appsv1alpha1.IngressPool{{Name: "beijing"}}
+	ying.Status.Conditions.IngressNotReadyPools = []appsv1alpha1.IngressNotReadyPool{{
+		Pool: appsv1alpha1.IngressPool{Name: "hangzhou"},
+		Info: &appsv1alpha1.IngressNotReadyConditionInfo{Reason: appsv1alpha1.IngressControllerUnavailable},
+	}}
+	ying.Status.Pools = []appsv1alpha1.IngressPoolStatus{{Name: "beijing", Image: "nginx:v1",
+		Endpoints: []appsv1alpha1.IngressPoolEndpoint{{Name: "http", Address: "10.0.0.1", Port: 80}}}}
+	o := &Options{KubeClient: kubefake.NewSimpleClientset(), AppsClient: appsfake.NewSimpleClientset(ying)}
+
+	infos, err := o.getIngressStatus(context.TODO(), nil)
+	if err != nil {
+		t.Fatalf("fail to get the ingress status: %v", err)
+	}
+	if len(infos) != 2 || !infos[0].Ready || infos[0].Image != "nginx:v1" || strings.Join(infos[0].Endpoints, ",") != "10.0.0.1:80" {
+		t.Fatalf("unexpected ingress status %v", infos)
+	}
+	if infos[1].Ready || infos[1].Reason != appsv1alpha1.IngressControllerUnavailable {
+		t.Fatalf("unexpected ingress status of pool hangzhou %v", infos[1])
+	}
+	if out := run(t, o, "ingress", "status", "ying"); !strings.Contains(out, "ControllerUnavailable") {
+		t.Fatalf("unexpected table:\n%s", out)
+	}
+}
+
+func TestNodeAssign(t *testing.T) {
+	o := &Options{
+		KubeClient: kubefake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}}),
+		AppsClient: appsfake.NewSimpleClientset(&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "beijing"}}),
+	}
+
+	run(t, o, "node", "assign", "n1", "beijing")
+	node, err := o.KubeClient.CoreV1().Nodes().Get(context.TODO(), "n1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("fail to get the node: %v", err)
+	}
+	if node.Labels[appsv1alpha1.LabelDesiredNodePool] != "beijing" {
+		t.Fatalf("expected the desired nodepool label, got %v", node.Labels)
+	}
+	if err := o.assignNode(context.TODO(), "n1", "missing"); err == nil {
+		t.Fatalf("expected an error for the missing pool")
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"reflect"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/openyurtio/yurt-app-manager/cmd/yurt-app-manager/options"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
+)
+
+// configReloadPeriod is the period to check the configuration file for changes
+const configReloadPeriod = 30 * time.Second
+
+// configWatcher reloads the configuration file once it changes. The enabled custom resources take effect
+// without a restart, the other settings take effect after a restart.
+type configWatcher struct {
+	opts *options.YurtAppOptions
+	// current is the effective configuration in use
+	current *configv1alpha1.YurtAppManagerConfiguration
+	data    []byte
+}
+
+func newConfigWatcher(opts *options.YurtAppOptions) (*configWatcher, error) {
+	data, err := ioutil.ReadFile(opts.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return &configWatcher{opts: opts, current: opts.Config, data: data}, nil
+}
+
+// Start checks the configuration file periodically until ctx is done.
+func (w *configWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(configReloadPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+// NeedLeaderElection is false, every replica reloads its configuration.
+func (w *configWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *configWatcher) reload() {
+	data, err := ioutil.ReadFile(w.opts.ConfigFile)
+	if err != nil {
+		klog.Errorf("fail to read the configuration file %s: %v", w.opts.ConfigFile, err)
+		return
+	}
+	if bytes.Equal(data, w.data) {
+		return
+	}
+	w.data = data
+
+	cfg, err := w.opts.Reload()
+	if err != nil {
+		klog.Errorf("fail to reload the configuration file %s, keep the current configuration: %v", w.opts.ConfigFile, err)
+		return
+	}
+
+	if !reflect.DeepEqual(cfg.CustomResourceEnable, w.current.CustomResourceEnable) {
+		klog.Infof("enabled custom resources are changed from %v to %v", w.current.CustomResourceEnable, cfg.CustomResourceEnable)
+		gate.SetEnabledResources(cfg.CustomResourceEnable)
+	}
+	restartRequired := *cfg
+	restartRequired.CustomResourceEnable = w.current.CustomResourceEnable
+	if !reflect.DeepEqual(&restartRequired, w.current) {
+		klog.Warningf("configuration file %s is changed, the settings except customResourceEnable take effect after a restart", w.opts.ConfigFile)
+	}
+	w.current.CustomResourceEnable = cfg.CustomResourceEnable
+}
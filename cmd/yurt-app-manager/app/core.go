@@ -18,39 +18,48 @@ package app
 
 import (
 	"context"
-	"flag"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
 
 	"github.com/openyurtio/yurt-app-manager/cmd/yurt-app-manager/options"
 	"github.com/openyurtio/yurt-app-manager/pkg/projectinfo"
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	appsv1beta1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1beta1"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
 	extclient "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/client"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/healthcheck"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/fieldindex"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook"
+	webhookutil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/util"
 )
 
+// leaderElectionID is the name of the lease of the leader election
+const leaderElectionID = "yurt-app-manager"
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
-
-	restConfigQPS   = flag.Int("rest-config-qps", 30, "QPS of rest config.")
-	restConfigBurst = flag.Int("rest-config-burst", 50, "Burst of rest config.")
 )
 
 func init() {
@@ -58,6 +67,7 @@ func init() {
 	_ = appsv1alpha1.AddToScheme(clientgoscheme.Scheme)
 
 	_ = appsv1alpha1.AddToScheme(scheme)
+	_ = appsv1beta1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -80,9 +90,15 @@ func NewCmdYurtAppManager(stopCh <-chan struct{}) *cobra.Command {
 			cmd.Flags().VisitAll(func(flag *pflag.Flag) {
 				klog.V(1).Infof("FLAG: --%s=%q", flag.Name, flag.Value)
 			})
+			if err := yurtAppOptions.Complete(cmd.Flags()); err != nil {
+				klog.Fatalf("complete options: %v", err)
+			}
 			if err := options.ValidateOptions(yurtAppOptions); err != nil {
 				klog.Fatalf("validate options: %v", err)
 			}
+			if data, err := yaml.Marshal(yurtAppOptions.Config); err == nil {
+				klog.Infof("effective configuration:\n%s", data)
+			}
 
 			Run(yurtAppOptions)
 		},
@@ -93,9 +109,10 @@ func NewCmdYurtAppManager(stopCh <-chan struct{}) *cobra.Command {
 }
 
 func Run(opts *options.YurtAppOptions) {
-	if opts.EnablePprof {
+	c := opts.Config
+	if c.EnablePprof {
 		go func() {
-			if err := http.ListenAndServe(opts.PprofAddr, nil); err != nil {
+			if err := http.ListenAndServe(c.PprofAddr, nil); err != nil {
 				setupLog.Error(err, "unable to start pprof")
 			}
 		}()
@@ -104,31 +121,53 @@ func Run(opts *options.YurtAppOptions) {
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 	//ctrl.SetLogger(klogr.New())
 
+	gate.SetEnabledResources(c.CustomResourceEnable)
+	webhookutil.SetConfiguration(&c.Webhook)
+
 	cfg := ctrl.GetConfigOrDie()
-	setRestConfig(cfg)
+	setRestConfig(cfg, c.ClientConnection)
 
 	cacheDisableObjs := []client.Object{
 		&appsv1alpha1.YurtIngress{},
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	mgrOpts := ctrl.Options{
 		Scheme:                     scheme,
-		MetricsBindAddress:         opts.MetricsAddr,
-		HealthProbeBindAddress:     opts.HealthProbeAddr,
-		LeaderElection:             opts.EnableLeaderElection,
-		LeaderElectionID:           "yurt-app-manager",
-		LeaderElectionNamespace:    opts.LeaderElectionNamespace,
+		MetricsBindAddress:         c.MetricsAddr,
+		HealthProbeBindAddress:     c.HealthProbeAddr,
+		LeaderElection:             *c.LeaderElection.LeaderElect,
+		LeaderElectionID:           leaderElectionID,
+		LeaderElectionNamespace:    c.LeaderElection.Namespace,
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock, // use lease to election
-		Namespace:                  opts.Namespace,
+		Namespace:                  c.Namespace,
 		ClientDisableCacheFor:      cacheDisableObjs,
-	})
-	if err != nil {
-		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
 	}
 
-	if err := registerHealthChecks(mgr); err != nil {
-		setupLog.Error(err, "Unable to register ready/health checks")
+	var nsWatcher *namespaceWatcher
+	if c.Namespace != "" || len(c.Namespaces) != 0 || c.NamespaceSelector != "" {
+		namespaces := c.Namespaces
+		if c.NamespaceSelector != "" {
+			reader, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err == nil {
+				nsWatcher, err = newNamespaceWatcher(context.Background(), reader, c.NamespaceSelector)
+			}
+			if err != nil {
+				setupLog.Error(err, "unable to resolve the namespace selector")
+				os.Exit(1)
+			}
+			namespaces = nsWatcher.namespaces
+		}
+		if c.Namespace == "" {
+			setupLog.Info("restrict the cache of the namespaced resources", "namespaces", namespaces)
+			mgrOpts.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		}
+		// the webhook secret and the workload registry are out of the restricted cache
+		mgrOpts.ClientDisableCacheFor = append(mgrOpts.ClientDisableCacheFor, &corev1.Secret{}, &corev1.ConfigMap{})
+	}
+
+	mgr, err := ctrl.NewManager(cfg, mgrOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
@@ -147,18 +186,52 @@ func Run(opts *options.YurtAppOptions) {
 
 	setupLog.Info("setup controllers")
 
-	ctx := genOptCtx(opts.CreateDefaultPool)
+	ctx := genOptCtx(&c.Controllers)
 	if err = controller.SetupWithManager(mgr, ctx); err != nil {
 		setupLog.Error(err, "unable to setup controllers")
 		os.Exit(1)
 	}
 
+	if opts.ConfigFile != "" {
+		watcher, err := newConfigWatcher(opts)
+		if err == nil {
+			err = mgr.Add(watcher)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to watch the configuration file")
+			os.Exit(1)
+		}
+	}
+
+	if nsWatcher != nil {
+		if err := mgr.Add(nsWatcher); err != nil {
+			setupLog.Error(err, "unable to watch the selected namespaces")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("setup webhook")
-	if err = webhook.SetupWithManager(mgr); err != nil {
+	if err = webhook.SetupWithManager(mgr, c.Webhook.ManageCerts); err != nil {
 		setupLog.Error(err, "unable to setup webhook")
 		os.Exit(1)
 	}
 
+	setupLog.Info("register ready/health checks")
+	checkOpts := healthcheck.Options{
+		WebhookAddr:         net.JoinHostPort("127.0.0.1", strconv.Itoa(webhookutil.GetPort())),
+		LeaderElection:      *c.LeaderElection.LeaderElect,
+		LeaderElectionLease: types.NamespacedName{Namespace: c.LeaderElection.Namespace, Name: leaderElectionID},
+		StuckThreshold:      c.StuckReconcileThreshold.Duration,
+	}
+	if c.Webhook.ManageCerts {
+		// the names of the certificates issued by the others may not match the service
+		checkOpts.WebhookDNSName = fmt.Sprintf("%s.%s.svc", webhookutil.GetServiceName(), webhookutil.GetNamespace())
+	}
+	if err := healthcheck.AddChecks(mgr, checkOpts); err != nil {
+		setupLog.Error(err, "unable to register ready/health checks")
+		os.Exit(1)
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	stopCh := ctrl.SetupSignalHandler()
@@ -170,28 +243,16 @@ func Run(opts *options.YurtAppOptions) {
 
 }
 
-func genOptCtx(createDefaultPool bool) context.Context {
+func genOptCtx(controllers *configv1alpha1.ControllersConfiguration) context.Context {
 	return context.WithValue(context.Background(),
-		constant.ContextKeyCreateDefaultPool, createDefaultPool)
-}
-
-func setRestConfig(c *rest.Config) {
-	if *restConfigQPS > 0 {
-		c.QPS = float32(*restConfigQPS)
-	}
-	if *restConfigBurst > 0 {
-		c.Burst = *restConfigBurst
-	}
+		constant.ContextKeyControllersConfiguration, controllers)
 }
 
-func registerHealthChecks(mgr ctrl.Manager) error {
-	klog.Info("Create readiness/health check")
-	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
-		return err
+func setRestConfig(c *rest.Config, cc configv1alpha1.ClientConnectionConfiguration) {
+	if cc.QPS > 0 {
+		c.QPS = cc.QPS
 	}
-	// TODO: change the health check to be different from readiness check
-	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
-		return err
+	if cc.Burst > 0 {
+		c.Burst = int(cc.Burst)
 	}
-	return nil
 }
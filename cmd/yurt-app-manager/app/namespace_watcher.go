@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// namespaceResyncPeriod is the period to check the namespaces selected by the namespace selector for changes
+const namespaceResyncPeriod = 30 * time.Second
+
+// namespaceWatcher resolves the namespaces selected by the namespace selector, which the cache of the namespaced
+// resources is restricted to. The cache can not be extended once started, so the watcher stops the manager
+// to restart it once the selected namespaces change.
+type namespaceWatcher struct {
+	client   client.Reader
+	selector labels.Selector
+	// namespaces is the sorted names of the selected namespaces the cache is restricted to
+	namespaces []string
+}
+
+func newNamespaceWatcher(ctx context.Context, c client.Reader, selector string) (*namespaceWatcher, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector %q: %v", selector, err)
+	}
+	w := &namespaceWatcher{client: c, selector: s}
+	if w.namespaces, err = w.list(ctx); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Start checks the selected namespaces periodically until ctx is done, and returns an error to stop
+// the manager once they change.
+func (w *namespaceWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(namespaceResyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := w.check(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NeedLeaderElection is false, every replica restricts its own cache.
+func (w *namespaceWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *namespaceWatcher) check(ctx context.Context) error {
+	namespaces, err := w.list(ctx)
+	if err != nil {
+		klog.Errorf("fail to check the namespaces selected by %q: %v", w.selector, err)
+		return nil
+	}
+	if !equalStrings(namespaces, w.namespaces) {
+		return fmt.Errorf("namespaces selected by %q are changed from %v to %v, restart to watch them", w.selector, w.namespaces, namespaces)
+	}
+	return nil
+}
+
+func (w *namespaceWatcher) list(ctx context.Context) ([]string, error) {
+	nsList := &corev1.NamespaceList{}
+	if err := w.client.List(ctx, nsList, client.MatchingLabelsSelector{Selector: w.selector}); err != nil {
+		return nil, fmt.Errorf("fail to list the namespaces selected by %q: %v", w.selector, err)
+	}
+	namespaces := make([]string, 0, len(nsList.Items))
+	for _, ns := range nsList.Items {
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestNamespaceWatcher(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newNamespace("team-b", map[string]string{"tenant": "a"}),
+		newNamespace("team-a", map[string]string{"tenant": "a"}),
+		newNamespace("other", map[string]string{"tenant": "b"}),
+	).Build()
+
+	w, err := newNamespaceWatcher(context.TODO(), c, "tenant=a")
+	if err != nil {
+		t.Fatalf("failed to create the namespace watcher: %v", err)
+	}
+	if !reflect.DeepEqual(w.namespaces, []string{"team-a", "team-b"}) {
+		t.Fatalf("unexpected selected namespaces %v", w.namespaces)
+	}
+	if err := w.check(context.TODO()); err != nil {
+		t.Fatalf("expect no changes of the selected namespaces, got %v", err)
+	}
+
+	if err := c.Create(context.TODO(), newNamespace("team-c", map[string]string{"tenant": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.check(context.TODO()); err == nil {
+		t.Errorf("expect an error once a namespace is selected")
+	}
+
+	if _, err := newNamespaceWatcher(context.TODO(), c, "tenant in (a"); err == nil {
+		t.Errorf("expect an error for the invalid selector")
+	}
+}
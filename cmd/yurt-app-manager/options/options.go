@@ -17,53 +17,233 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
 )
 
 // YurtAppOptions is the main settings for the yurtapp-manger
 type YurtAppOptions struct {
+	ConfigFile              string
 	MetricsAddr             string
 	PprofAddr               string
 	HealthProbeAddr         string
+	StuckReconcileThreshold time.Duration
 	EnableLeaderElection    bool
 	EnablePprof             bool
 	LeaderElectionNamespace string
 	Namespace               string
+	Namespaces              []string
+	NamespaceSelector       string
+	RestConfigQPS           int
+	RestConfigBurst         int
 	CreateDefaultPool       bool
+	IngressTemplateDir      string
+	ManageWebhookCerts      bool
+	YurtAppSetWorkers       int
+	YurtAppSetRegistry      string
+	YurtAppDaemonWorkers    int
 	Version                 bool
+
+	// Config is the effective configuration, the environment variables overridden by
+	// the configuration file and the flags, set by Complete
+	Config *configv1alpha1.YurtAppManagerConfiguration
+	// flags is the flag set Complete is called with, which overrides the reloaded configuration file
+	flags *pflag.FlagSet
 }
 
 // NewYurtAppOptions creates a new YurtAppOptions with a default config.
 func NewYurtAppOptions() *YurtAppOptions {
 	o := &YurtAppOptions{
-		MetricsAddr:             ":8080",
-		PprofAddr:               ":8090",
-		HealthProbeAddr:         ":8000",
+		MetricsAddr:             configv1alpha1.DefaultMetricsAddr,
+		PprofAddr:               configv1alpha1.DefaultPprofAddr,
+		HealthProbeAddr:         configv1alpha1.DefaultHealthProbeAddr,
+		StuckReconcileThreshold: configv1alpha1.DefaultStuckReconcileThreshold,
 		EnableLeaderElection:    true,
 		EnablePprof:             false,
-		LeaderElectionNamespace: "kube-system",
+		LeaderElectionNamespace: configv1alpha1.DefaultLeaderElectionNamespace,
 		Namespace:               "",
+		RestConfigQPS:           configv1alpha1.DefaultQPS,
+		RestConfigBurst:         configv1alpha1.DefaultBurst,
 		CreateDefaultPool:       false,
+		YurtAppSetWorkers:       configv1alpha1.DefaultWorkers,
+		YurtAppSetRegistry:      configv1alpha1.DefaultWorkloadRegistry,
+		YurtAppDaemonWorkers:    configv1alpha1.DefaultWorkers,
 	}
 
 	return o
 }
 
-// ValidateOptions validates YurtAppOptions
+// ValidateOptions validates the effective configuration of YurtAppOptions, Complete must be called before.
 func ValidateOptions(options *YurtAppOptions) error {
-	// TODO
-	return nil
+	if options.Config == nil {
+		return fmt.Errorf("options are not completed")
+	}
+	return ValidateConfiguration(options.Config).ToAggregate()
 }
 
 // AddFlags returns flags for a specific yurthub by section name
 func (o *YurtAppOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile, "The path of the YurtAppManagerConfiguration file, its settings override the environment variables and the flags override its settings.")
 	fs.StringVar(&o.MetricsAddr, "metrics-addr", o.MetricsAddr, "The address the metric endpoint binds to.")
 	fs.StringVar(&o.PprofAddr, "pprof-addr", o.PprofAddr, "The address the pprof binds to.")
 	fs.StringVar(&o.HealthProbeAddr, "health-probe-addr", o.HealthProbeAddr, "The address the healthz/readyz endpoint binds to.")
+	fs.DurationVar(&o.StuckReconcileThreshold, "stuck-reconcile-threshold", o.StuckReconcileThreshold, "The time a work queue of a controller with items processes none of them, or a reconcile runs, before the liveness check fails.")
 	fs.BoolVar(&o.EnableLeaderElection, "enable-leader-election", o.EnableLeaderElection, "Whether you need to enable leader election.")
 	fs.BoolVar(&o.EnablePprof, "enable-pprof", o.EnablePprof, "Enable pprof for controller manager.")
 	fs.StringVar(&o.LeaderElectionNamespace, "leader-election-namespace", o.LeaderElectionNamespace, "This determines the namespace in which the leader election configmap will be created, it will use in-cluster namespace if empty.")
 	fs.StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace if specified restricts the manager's cache to watch objects in the desired namespace. Defaults to all namespaces.")
+	fs.StringSliceVar(&o.Namespaces, "namespaces", o.Namespaces, "Namespaces if specified restricts the manager's cache to watch the namespaced objects, such as YurtAppSets, YurtAppDaemons and their workloads, in the namespaces. The cluster-scoped objects such as NodePools are watched in the whole cluster.")
+	fs.StringVar(&o.NamespaceSelector, "namespace-selector", o.NamespaceSelector, "The label selector of the namespaces the manager's cache watches the namespaced objects in, the manager restarts once the selected namespaces change. At most one of --namespace, --namespaces and --namespace-selector can be set.")
+	fs.IntVar(&o.RestConfigQPS, "rest-config-qps", o.RestConfigQPS, "QPS of rest config.")
+	fs.IntVar(&o.RestConfigBurst, "rest-config-burst", o.RestConfigBurst, "Burst of rest config.")
 	fs.BoolVar(&o.CreateDefaultPool, "create-default-pool", o.CreateDefaultPool, "Create default cloud/edge pools if indicated.")
+	fs.StringVar(&o.IngressTemplateDir, "ingress-template-dir", o.IngressTemplateDir, "The directory of the template sets overriding the built-in templates of the ingress controllers of YurtIngress, the templates of nginx and traefik are in its nginx and traefik subdirectories.")
+	fs.BoolVar(&o.ManageWebhookCerts, "manage-webhook-certs", o.ManageWebhookCerts, "Generate and rotate the serving certificate of the webhooks, and inject its CA into the webhook configurations and the conversion webhooks of the CRDs, instead of cert-manager or the certgen jobs.")
+	fs.IntVar(&o.YurtAppSetWorkers, "yurtappset-workers", o.YurtAppSetWorkers, "Max concurrent workers for YurtAppSet controller.")
+	fs.StringVar(&o.YurtAppSetRegistry, "yurtappset-workload-registry", o.YurtAppSetRegistry, "The namespace/name of the ConfigMap which declares the custom workloads of YurtAppSet.")
+	fs.IntVar(&o.YurtAppDaemonWorkers, "yurtappdaemon-workers", o.YurtAppDaemonWorkers, "Max concurrent workers for YurtAppDaemon controller.")
 	fs.BoolVar(&o.Version, "version", o.Version, "print the version information.")
 }
+
+// Complete builds the effective configuration from the environment variables, the configuration file
+// and the flags explicitly set in fs, in the increasing order of precedence, then defaults it.
+func (o *YurtAppOptions) Complete(fs *pflag.FlagSet) error {
+	cfg, err := o.complete(fs)
+	if err != nil {
+		return err
+	}
+	o.Config, o.flags = cfg, fs
+	return nil
+}
+
+// Reload builds and validates the effective configuration again from the configuration file which may have changed,
+// the environment variables and the flags, without changing Config. Complete must be called before.
+func (o *YurtAppOptions) Reload() (*configv1alpha1.YurtAppManagerConfiguration, error) {
+	if o.flags == nil {
+		return nil, fmt.Errorf("options are not completed")
+	}
+	cfg, err := o.complete(o.flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateConfiguration(cfg).ToAggregate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (o *YurtAppOptions) complete(fs *pflag.FlagSet) (*configv1alpha1.YurtAppManagerConfiguration, error) {
+	cfg := &configv1alpha1.YurtAppManagerConfiguration{}
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if o.ConfigFile != "" {
+		if err := loadConfigFile(o.ConfigFile, cfg); err != nil {
+			return nil, err
+		}
+	}
+	o.applyFlags(cfg, fs)
+	configv1alpha1.SetDefaultsYurtAppManagerConfiguration(cfg)
+	return cfg, nil
+}
+
+// LoadConfigFile loads the YurtAppManagerConfiguration from the yaml file, unknown fields are rejected.
+func LoadConfigFile(path string) (*configv1alpha1.YurtAppManagerConfiguration, error) {
+	cfg := &configv1alpha1.YurtAppManagerConfiguration{}
+	if err := loadConfigFile(path, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadConfigFile decodes the yaml file into cfg, only the fields set in the file are overridden.
+func loadConfigFile(path string, cfg *configv1alpha1.YurtAppManagerConfiguration) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("fail to read the configuration file %s: %v", path, err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return fmt.Errorf("fail to decode the configuration file %s: %v", path, err)
+	}
+	if cfg.APIVersion != configv1alpha1.GroupVersion.String() || cfg.Kind != configv1alpha1.Kind {
+		return fmt.Errorf("the configuration file %s is %s %s, not %s %s", path,
+			cfg.APIVersion, cfg.Kind, configv1alpha1.GroupVersion.String(), configv1alpha1.Kind)
+	}
+	return nil
+}
+
+// applyEnv sets cfg from the environment variables used before the configuration file,
+// which are the defaults of the settings the configuration file leaves unset
+func applyEnv(cfg *configv1alpha1.YurtAppManagerConfiguration) error {
+	envs := map[string]*string{
+		"WEBHOOK_HOST":                          &cfg.Webhook.Host,
+		"WEBHOOK_CERT_DIR":                      &cfg.Webhook.CertDir,
+		"POD_NAMESPACE":                         &cfg.Webhook.Namespace,
+		"SECRET_NAME":                           &cfg.Webhook.SecretName,
+		"SERVICE_NAME":                          &cfg.Webhook.ServiceName,
+		"MUTATING_WEBHOOK_CONFIGURATION_NAME":   &cfg.Webhook.MutatingWebhookConfigurationName,
+		"VALIDATING_WEBHOOK_CONFIGURATION_NAME": &cfg.Webhook.ValidatingWebhookConfigurationName,
+	}
+	for name, field := range envs {
+		if v := os.Getenv(name); len(v) > 0 {
+			*field = v
+		}
+	}
+
+	if p := os.Getenv("WEBHOOK_PORT"); len(p) > 0 {
+		port, err := strconv.ParseInt(p, 10, 32)
+		if err != nil {
+			return fmt.Errorf("fail to convert WEBHOOK_PORT=%v in env: %v", p, err)
+		}
+		cfg.Webhook.Port = int32(port)
+	}
+
+	if limits := strings.TrimSpace(os.Getenv("CUSTOM_RESOURCE_ENABLE")); len(limits) > 0 {
+		cfg.CustomResourceEnable = strings.Split(limits, ",")
+	}
+	return nil
+}
+
+// applyFlags overrides cfg with the flags explicitly set in fs
+func (o *YurtAppOptions) applyFlags(cfg *configv1alpha1.YurtAppManagerConfiguration, fs *pflag.FlagSet) {
+	flags := map[string]func(){
+		"metrics-addr":      func() { cfg.MetricsAddr = o.MetricsAddr },
+		"pprof-addr":        func() { cfg.PprofAddr = o.PprofAddr },
+		"health-probe-addr": func() { cfg.HealthProbeAddr = o.HealthProbeAddr },
+		"stuck-reconcile-threshold": func() {
+			cfg.StuckReconcileThreshold = metav1.Duration{Duration: o.StuckReconcileThreshold}
+		},
+		"enable-leader-election": func() {
+			leaderElect := o.EnableLeaderElection
+			cfg.LeaderElection.LeaderElect = &leaderElect
+		},
+		"enable-pprof":                 func() { cfg.EnablePprof = o.EnablePprof },
+		"leader-election-namespace":    func() { cfg.LeaderElection.Namespace = o.LeaderElectionNamespace },
+		"namespace":                    func() { cfg.Namespace = o.Namespace },
+		"namespaces":                   func() { cfg.Namespaces = o.Namespaces },
+		"namespace-selector":           func() { cfg.NamespaceSelector = o.NamespaceSelector },
+		"rest-config-qps":              func() { cfg.ClientConnection.QPS = float32(o.RestConfigQPS) },
+		"rest-config-burst":            func() { cfg.ClientConnection.Burst = int32(o.RestConfigBurst) },
+		"create-default-pool":          func() { cfg.Controllers.NodePool.CreateDefaultPool = o.CreateDefaultPool },
+		"ingress-template-dir":         func() { cfg.Controllers.YurtIngress.TemplateDir = o.IngressTemplateDir },
+		"manage-webhook-certs":         func() { cfg.Webhook.ManageCerts = o.ManageWebhookCerts },
+		"yurtappset-workers":           func() { cfg.Controllers.YurtAppSet.Workers = int32(o.YurtAppSetWorkers) },
+		"yurtappset-workload-registry": func() { cfg.Controllers.YurtAppSet.WorkloadRegistry = o.YurtAppSetRegistry },
+		"yurtappdaemon-workers":        func() { cfg.Controllers.YurtAppDaemon.Workers = int32(o.YurtAppDaemonWorkers) },
+	}
+	for name, apply := range flags {
+		if fs.Changed(name) {
+			apply()
+		}
+	}
+}
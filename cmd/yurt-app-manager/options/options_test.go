@@ -0,0 +1,307 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+)
+
+const testConfig = `apiVersion: config.openyurt.io/v1alpha1
+kind: YurtAppManagerConfiguration
+metricsAddr: ":9080"
+leaderElection:
+  leaderElect: false
+clientConnection:
+  qps: 100
+webhook:
+  port: 9443
+customResourceEnable:
+- NodePool
+controllers:
+  yurtAppSet:
+    workers: 5
+    rateLimiter:
+      baseDelay: 10ms
+      maxDelay: 5m
+  nodePool:
+    createDefaultPool: true
+`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func completeOptions(t *testing.T, args ...string) (*YurtAppOptions, error) {
+	o := NewYurtAppOptions()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	o.AddFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return o, o.Complete(fs)
+}
+
+func TestComplete(t *testing.T) {
+	os.Setenv("WEBHOOK_PORT", "10250")
+	defer os.Unsetenv("WEBHOOK_PORT")
+	os.Setenv("POD_NAMESPACE", "yurt-system")
+	defer os.Unsetenv("POD_NAMESPACE")
+
+	path := writeConfig(t, testConfig)
+	o, err := completeOptions(t, "--config", path, "--yurtappset-workers", "7", "--metrics-addr", ":9090")
+	if err != nil {
+		t.Fatalf("failed to complete options: %v", err)
+	}
+	if err := ValidateOptions(o); err != nil {
+		t.Fatalf("failed to validate options: %v", err)
+	}
+
+	c := o.Config
+	if c.MetricsAddr != ":9090" {
+		t.Errorf("expect the flag to override metricsAddr, got %s", c.MetricsAddr)
+	}
+	if c.Webhook.Port != 9443 {
+		t.Errorf("expect the file to override the env of webhook.port, got %d", c.Webhook.Port)
+	}
+	if c.Webhook.Namespace != "yurt-system" {
+		t.Errorf("expect the env to set webhook.namespace unset in the file, got %s", c.Webhook.Namespace)
+	}
+	if *c.LeaderElection.LeaderElect || c.ClientConnection.QPS != 100 || !c.Controllers.NodePool.CreateDefaultPool {
+		t.Errorf("expect the settings of the file, got %+v", c)
+	}
+	if c.ClientConnection.Burst != configv1alpha1.DefaultBurst || c.HealthProbeAddr != configv1alpha1.DefaultHealthProbeAddr {
+		t.Errorf("expect the unset settings to be defaulted, got %+v", c)
+	}
+	yas := c.Controllers.YurtAppSet
+	if yas.Workers != 7 || yas.RateLimiter.BaseDelay.Duration != 10*time.Millisecond ||
+		yas.RateLimiter.MaxDelay.Duration != 5*time.Minute || yas.RateLimiter.QPS != configv1alpha1.DefaultRateLimiterQPS {
+		t.Errorf("unexpected yurtAppSet controller configuration %+v", yas)
+	}
+	if c.Controllers.YurtIngress.Workers != configv1alpha1.DefaultWorkers {
+		t.Errorf("expect the default workers of yurtIngress, got %d", c.Controllers.YurtIngress.Workers)
+	}
+}
+
+func TestCompleteWithoutConfigFile(t *testing.T) {
+	o, err := completeOptions(t, "--enable-leader-election=false", "--create-default-pool")
+	if err != nil {
+		t.Fatalf("failed to complete options: %v", err)
+	}
+	if err := ValidateOptions(o); err != nil {
+		t.Fatalf("failed to validate options: %v", err)
+	}
+	if *o.Config.LeaderElection.LeaderElect || !o.Config.Controllers.NodePool.CreateDefaultPool {
+		t.Errorf("expect the flags to be applied, got %+v", o.Config)
+	}
+	if len(o.Config.Namespaces) != 0 || o.Config.NamespaceSelector != "" {
+		t.Errorf("expect the cache not to be restricted, got %+v", o.Config)
+	}
+	if o.Config.Controllers.YurtAppSet.WorkloadRegistry != configv1alpha1.DefaultWorkloadRegistry {
+		t.Errorf("expect the default workload registry, got %s", o.Config.Controllers.YurtAppSet.WorkloadRegistry)
+	}
+}
+
+func TestCompleteNamespaces(t *testing.T) {
+	os.Setenv("CUSTOM_RESOURCE_ENABLE", "YurtAppSet,YurtAppDaemon")
+	defer os.Unsetenv("CUSTOM_RESOURCE_ENABLE")
+	o, err := completeOptions(t, "--namespaces", "team-a,team-b")
+	if err != nil {
+		t.Fatalf("failed to complete options: %v", err)
+	}
+	if err := ValidateOptions(o); err != nil {
+		t.Fatalf("failed to validate options: %v", err)
+	}
+	if len(o.Config.Namespaces) != 2 || o.Config.Namespaces[1] != "team-b" {
+		t.Errorf("expect the namespaces of the flag, got %v", o.Config.Namespaces)
+	}
+
+	o, err = completeOptions(t, "--namespace", "team-a", "--namespace-selector", "tenant=a")
+	if err != nil {
+		t.Fatalf("failed to complete options: %v", err)
+	}
+	if err := ValidateOptions(o); err == nil {
+		t.Errorf("expect an error setting both namespace and namespace selector")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unknown field",
+			content: "apiVersion: config.openyurt.io/v1alpha1\nkind: YurtAppManagerConfiguration\nunknown: true\n",
+		},
+		{
+			name:    "wrong kind",
+			content: "apiVersion: config.openyurt.io/v1alpha1\nkind: KubeletConfiguration\n",
+		},
+		{
+			name:    "missing apiVersion",
+			content: "kind: YurtAppManagerConfiguration\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadConfigFile(writeConfig(t, tt.content)); err == nil {
+				t.Errorf("expect an error loading the configuration")
+			}
+		})
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *configv1alpha1.YurtAppManagerConfiguration)
+		errs   int
+	}{
+		{
+			name:   "valid",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {},
+		},
+		{
+			name: "invalid addresses",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.MetricsAddr = "8080"
+				c.HealthProbeAddr = ":70000"
+			},
+			errs: 2,
+		},
+		{
+			name: "invalid stuck reconcile threshold",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.StuckReconcileThreshold.Duration = -time.Minute
+			},
+			errs: 1,
+		},
+		{
+			name: "disabled metrics",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.MetricsAddr = "0"
+			},
+		},
+		{
+			name: "invalid webhook",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.Webhook.Port = 70000
+				c.Webhook.ServiceName = "Webhook_Service"
+			},
+			errs: 2,
+		},
+		{
+			name: "unknown custom resource",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.CustomResourceEnable = []string{"NodePool", "Deployment"}
+			},
+			errs: 1,
+		},
+		{
+			name: "namespaces",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.Namespaces = []string{"team-a", "team-b"}
+				c.CustomResourceEnable = []string{"YurtAppSet", "YurtAppDaemon"}
+			},
+		},
+		{
+			name: "invalid namespaces",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.Namespaces = []string{"team-a", "team-a", "Team_B"}
+				c.CustomResourceEnable = []string{"YurtAppSet"}
+			},
+			errs: 2,
+		},
+		{
+			name: "namespace selector with yurtingress",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.NamespaceSelector = "tenant=a"
+			},
+			errs: 1,
+		},
+		{
+			name: "namespaces and namespace selector",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.Namespaces = []string{"team-a"}
+				c.NamespaceSelector = "tenant in (a"
+				c.CustomResourceEnable = []string{"YurtAppSet"}
+			},
+			errs: 2,
+		},
+		{
+			name: "invalid controllers",
+			mutate: func(c *configv1alpha1.YurtAppManagerConfiguration) {
+				c.Controllers.YurtAppDaemon.Workers = -1
+				c.Controllers.NodePool.RateLimiter.MaxDelay.Duration = time.Millisecond
+				c.Controllers.YurtIngress.RateLimiter.QPS = -1
+				c.Controllers.YurtAppSet.WorkloadRegistry = "registry"
+			},
+			errs: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &configv1alpha1.YurtAppManagerConfiguration{}
+			configv1alpha1.SetDefaultsYurtAppManagerConfiguration(c)
+			tt.mutate(c)
+			if errs := ValidateConfiguration(c); len(errs) != tt.errs {
+				t.Errorf("expect %d errors, got %v", tt.errs, errs)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	o, err := completeOptions(t, "--config", path, "--metrics-addr", ":9090")
+	if err != nil {
+		t.Fatalf("failed to complete options: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(testConfig+"  yurtIngress:\n    workers: 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := o.Reload()
+	if err != nil {
+		t.Fatalf("failed to reload the configuration: %v", err)
+	}
+	if cfg.MetricsAddr != ":9090" || !cfg.Controllers.NodePool.CreateDefaultPool {
+		t.Errorf("expect the reloaded configuration to be overridden by the flags, got %+v", cfg)
+	}
+	if o.Config == cfg {
+		t.Errorf("expect Config not to be changed by Reload")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(testConfig+"  yurtIngress:\n    workers: -1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := o.Reload(); err == nil {
+		t.Errorf("expect an error reloading the invalid configuration")
+	}
+}
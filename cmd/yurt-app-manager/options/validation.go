@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+)
+
+// customResourceKinds is the kinds of the custom resources which can be enabled
+var customResourceKinds = sets.NewString("YurtAppSet", "YurtAppDaemon", "NodePool", "YurtIngress")
+
+// ValidateConfiguration validates the defaulted YurtAppManagerConfiguration
+func ValidateConfiguration(cfg *configv1alpha1.YurtAppManagerConfiguration) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if cfg.MetricsAddr != "0" {
+		allErrs = append(allErrs, validateAddr(cfg.MetricsAddr, field.NewPath("metricsAddr"))...)
+	}
+	allErrs = append(allErrs, validateAddr(cfg.HealthProbeAddr, field.NewPath("healthProbeAddr"))...)
+	if cfg.StuckReconcileThreshold.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("stuckReconcileThreshold"), cfg.StuckReconcileThreshold.Duration.String(), "must be positive"))
+	}
+	if cfg.EnablePprof {
+		allErrs = append(allErrs, validateAddr(cfg.PprofAddr, field.NewPath("pprofAddr"))...)
+	}
+	allErrs = append(allErrs, validateNamespaces(cfg)...)
+
+	lePath := field.NewPath("leaderElection")
+	if cfg.LeaderElection.Namespace != "" {
+		allErrs = append(allErrs, validateDNS1123Label(cfg.LeaderElection.Namespace, lePath.Child("namespace"))...)
+	}
+
+	ccPath := field.NewPath("clientConnection")
+	if cfg.ClientConnection.QPS < 0 {
+		allErrs = append(allErrs, field.Invalid(ccPath.Child("qps"), cfg.ClientConnection.QPS, "must be positive"))
+	}
+	if cfg.ClientConnection.Burst < 0 {
+		allErrs = append(allErrs, field.Invalid(ccPath.Child("burst"), cfg.ClientConnection.Burst, "must be positive"))
+	}
+
+	crPath := field.NewPath("customResourceEnable")
+	for i, kind := range cfg.CustomResourceEnable {
+		if !customResourceKinds.Has(kind) {
+			allErrs = append(allErrs, field.NotSupported(crPath.Index(i), kind, customResourceKinds.List()))
+		}
+	}
+
+	allErrs = append(allErrs, validateWebhookConfiguration(&cfg.Webhook, field.NewPath("webhook"))...)
+	allErrs = append(allErrs, validateControllersConfiguration(&cfg.Controllers, field.NewPath("controllers"))...)
+	return allErrs
+}
+
+// validateNamespaces validates the namespaces the cache of the manager is restricted to
+func validateNamespaces(cfg *configv1alpha1.YurtAppManagerConfiguration) field.ErrorList {
+	var allErrs field.ErrorList
+	var set []string
+	if cfg.Namespace != "" {
+		set = append(set, "namespace")
+		allErrs = append(allErrs, validateDNS1123Label(cfg.Namespace, field.NewPath("namespace"))...)
+	}
+	if len(cfg.Namespaces) != 0 {
+		set = append(set, "namespaces")
+		nsPath := field.NewPath("namespaces")
+		namespaces := sets.NewString()
+		for i, ns := range cfg.Namespaces {
+			if namespaces.Has(ns) {
+				allErrs = append(allErrs, field.Duplicate(nsPath.Index(i), ns))
+			}
+			namespaces.Insert(ns)
+			allErrs = append(allErrs, validateDNS1123Label(ns, nsPath.Index(i))...)
+		}
+	}
+	if cfg.NamespaceSelector != "" {
+		set = append(set, "namespaceSelector")
+		if _, err := labels.Parse(cfg.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("namespaceSelector"), cfg.NamespaceSelector, err.Error()))
+		}
+	}
+	if len(set) > 1 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath(set[1]), fmt.Sprintf("can not be set with %s", set[0])))
+	}
+
+	// the ingress controllers of YurtIngress are deployed in their own namespaces out of the restricted cache
+	restricted := len(cfg.Namespaces) != 0 || cfg.NamespaceSelector != ""
+	if restricted && (len(cfg.CustomResourceEnable) == 0 || sets.NewString(cfg.CustomResourceEnable...).Has("YurtIngress")) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("customResourceEnable"), cfg.CustomResourceEnable,
+			"YurtIngress can not be enabled with namespaces or namespaceSelector"))
+	}
+	return allErrs
+}
+
+func validateWebhookConfiguration(c *configv1alpha1.WebhookConfiguration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if c.Host != "" && net.ParseIP(c.Host) == nil {
+		for _, msg := range validation.IsDNS1123Subdomain(c.Host) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("host"), c.Host, msg))
+		}
+	}
+	for _, msg := range validation.IsValidPortNum(int(c.Port)) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), c.Port, msg))
+	}
+	if c.CertDir == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("certDir"), ""))
+	}
+	allErrs = append(allErrs, validateDNS1123Label(c.Namespace, fldPath.Child("namespace"))...)
+	allErrs = append(allErrs, validateDNS1123Subdomain(c.SecretName, fldPath.Child("secretName"))...)
+	allErrs = append(allErrs, validateDNS1123Label(c.ServiceName, fldPath.Child("serviceName"))...)
+	allErrs = append(allErrs, validateDNS1123Subdomain(c.MutatingWebhookConfigurationName, fldPath.Child("mutatingWebhookConfigurationName"))...)
+	allErrs = append(allErrs, validateDNS1123Subdomain(c.ValidatingWebhookConfigurationName, fldPath.Child("validatingWebhookConfigurationName"))...)
+	return allErrs
+}
+
+func validateControllersConfiguration(c *configv1alpha1.ControllersConfiguration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	yasPath := fldPath.Child("yurtAppSet")
+	allErrs = append(allErrs, validateControllerConfiguration(&c.YurtAppSet.ControllerConfiguration, yasPath)...)
+	registryPath := yasPath.Child("workloadRegistry")
+	if parts := strings.Split(c.YurtAppSet.WorkloadRegistry, "/"); len(parts) != 2 {
+		allErrs = append(allErrs, field.Invalid(registryPath, c.YurtAppSet.WorkloadRegistry, "must be namespace/name"))
+	} else {
+		allErrs = append(allErrs, validateDNS1123Label(parts[0], registryPath)...)
+		allErrs = append(allErrs, validateDNS1123Subdomain(parts[1], registryPath)...)
+	}
+
+	allErrs = append(allErrs, validateControllerConfiguration(&c.YurtAppDaemon.ControllerConfiguration, fldPath.Child("yurtAppDaemon"))...)
+	allErrs = append(allErrs, validateControllerConfiguration(&c.NodePool.ControllerConfiguration, fldPath.Child("nodePool"))...)
+	allErrs = append(allErrs, validateControllerConfiguration(&c.YurtIngress.ControllerConfiguration, fldPath.Child("yurtIngress"))...)
+	return allErrs
+}
+
+func validateControllerConfiguration(c *configv1alpha1.ControllerConfiguration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if c.Workers < 1 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("workers"), c.Workers, "must be at least 1"))
+	}
+
+	rlPath := fldPath.Child("rateLimiter")
+	rl := c.RateLimiter
+	if rl.BaseDelay.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(rlPath.Child("baseDelay"), rl.BaseDelay.Duration.String(), "must be positive"))
+	}
+	if rl.MaxDelay.Duration < rl.BaseDelay.Duration {
+		allErrs = append(allErrs, field.Invalid(rlPath.Child("maxDelay"), rl.MaxDelay.Duration.String(), "must not be less than baseDelay"))
+	}
+	if rl.QPS <= 0 {
+		allErrs = append(allErrs, field.Invalid(rlPath.Child("qps"), rl.QPS, "must be positive"))
+	}
+	if rl.Burst < 1 {
+		allErrs = append(allErrs, field.Invalid(rlPath.Child("burst"), rl.Burst, "must be at least 1"))
+	}
+	return allErrs
+}
+
+// validateAddr validates the host:port address a server binds to
+func validateAddr(addr string, fldPath *field.Path) field.ErrorList {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return field.ErrorList{field.Invalid(fldPath, addr, err.Error())}
+	}
+	if host != "" && net.ParseIP(host) == nil && len(validation.IsDNS1123Subdomain(host)) != 0 {
+		return field.ErrorList{field.Invalid(fldPath, addr, "must be an IP address or a host name")}
+	}
+	if p, err := strconv.Atoi(port); err != nil || len(validation.IsValidPortNum(p)) != 0 {
+		return field.ErrorList{field.Invalid(fldPath, addr, "must have a port between 1 and 65535")}
+	}
+	return nil
+}
+
+func validateDNS1123Label(value string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(value) {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, msg))
+	}
+	return allErrs
+}
+
+func validateDNS1123Subdomain(value string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(value) {
+		allErrs = append(allErrs, field.Invalid(fldPath, value, msg))
+	}
+	return allErrs
+}
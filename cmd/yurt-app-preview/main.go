@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappdaemon"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/preview"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+}
+
+// ObjectPreview is the preview of the workloads of one YurtAppSet or YurtAppDaemon.
+type ObjectPreview struct {
+	Kind      string                    `json:"kind"`
+	Namespace string                    `json:"namespace"`
+	Name      string                    `json:"name"`
+	Workloads []preview.WorkloadPreview `json:"workloads"`
+}
+
+func main() {
+	var kubeconfig, filename, output string
+	cmd := &cobra.Command{
+		Use:   "yurt-app-preview",
+		Short: "Preview the per-pool workloads of YurtAppSets and YurtAppDaemons without applying them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if filename == "" {
+				return errors.New("--filename is required")
+			}
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unsupported output format %q", output)
+			}
+			cfg, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+			if err != nil {
+				return err
+			}
+			c, err := client.New(cfg, client.Options{Scheme: scheme})
+			if err != nil {
+				return err
+			}
+			return run(c, filename, output, cmd.OutOrStdout())
+		},
+	}
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "Path to the kubeconfig file.")
+	cmd.Flags().StringVarP(&filename, "filename", "f", "", "The YAML file of the YurtAppSets and YurtAppDaemons, - for stdin.")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "Output format, text or json.")
+
+	if err := cmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func run(c client.Client, filename, output string, out io.Writer) error {
+	var in io.Reader = os.Stdin
+	if filename != "-" {
+		f, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var previews []ObjectPreview
+	decoder := utilyaml.NewYAMLOrJSONDecoder(in, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		if u.GetNamespace() == "" {
+			u.SetNamespace("default")
+		}
+
+		p := ObjectPreview{Kind: u.GetKind(), Namespace: u.GetNamespace(), Name: u.GetName()}
+		var err error
+		switch u.GetKind() {
+		case "YurtAppSet":
+			yas := &appsv1alpha1.YurtAppSet{}
+			if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, yas); err == nil {
+				p.Workloads, err = yurtappset.Preview(c, scheme, yas)
+			}
+		case "YurtAppDaemon":
+			yad := &appsv1alpha1.YurtAppDaemon{}
+			if err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, yad); err == nil {
+				p.Workloads, err = yurtappdaemon.Preview(c, scheme, yad)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("fail to preview %s %s/%s: %v", p.Kind, p.Namespace, p.Name, err)
+		}
+		previews = append(previews, p)
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(previews)
+	}
+	for _, p := range previews {
+		fmt.Fprintf(out, "%s %s/%s\n", p.Kind, p.Namespace, p.Name)
+		for _, w := range p.Workloads {
+			fmt.Fprint(out, w.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1beta1"
+)
+
+// The v1alpha1 types are converted to and from the v1beta1 hub field by field. Every v1alpha1 field
+// has a v1beta1 counterpart, so that the conversion is lossless in both directions.
+
+// ConvertTo converts this NodePool to the hub version.
+func (src *NodePool) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.NodePool)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.NodePoolSpec{
+		Type:        v1beta1.NodePoolType(src.Spec.Type),
+		Selector:    src.Spec.Selector,
+		Labels:      src.Spec.Labels,
+		Annotations: src.Spec.Annotations,
+		Taints:      src.Spec.Taints,
+	}
+	dst.Status = v1beta1.NodePoolStatus{
+		ReadyNodeNum:   src.Status.ReadyNodeNum,
+		UnreadyNodeNum: src.Status.UnreadyNodeNum,
+		Nodes:          src.Status.Nodes,
+		Conditions:     src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this NodePool.
+func (dst *NodePool) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.NodePool)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = NodePoolSpec{
+		Type:        NodePoolType(src.Spec.Type),
+		Selector:    src.Spec.Selector,
+		Labels:      src.Spec.Labels,
+		Annotations: src.Spec.Annotations,
+		Taints:      src.Spec.Taints,
+	}
+	dst.Status = NodePoolStatus{
+		ReadyNodeNum:   src.Status.ReadyNodeNum,
+		UnreadyNodeNum: src.Status.UnreadyNodeNum,
+		Nodes:          src.Status.Nodes,
+		Conditions:     src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertTo converts this YurtAppSet to the hub version.
+func (src *YurtAppSet) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.YurtAppSet)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.YurtAppSetSpec{
+		Selector:             src.Spec.Selector,
+		WorkloadTemplate:     convertWorkloadTemplateToHub(src.Spec.WorkloadTemplate),
+		Topology:             v1beta1.Topology{Pools: convertPoolsToHub(src.Spec.Topology.Pools)},
+		ServiceTemplate:      (*v1beta1.ServiceTemplateSpec)(src.Spec.ServiceTemplate),
+		ElasticPlacement:     (*v1beta1.ElasticPlacementStrategy)(src.Spec.ElasticPlacement),
+		AutonomyCompensation: (*v1beta1.AutonomyCompensationPolicy)(src.Spec.AutonomyCompensation),
+		RevisionHistoryLimit: src.Spec.RevisionHistoryLimit,
+	}
+	dst.Status = v1beta1.YurtAppSetStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CollisionCount:     src.Status.CollisionCount,
+		CurrentRevision:    src.Status.CurrentRevision,
+		PoolReplicas:       src.Status.PoolReplicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		Replicas:           src.Status.Replicas,
+		TemplateType:       v1beta1.TemplateType(src.Status.TemplateType),
+	}
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]metav1.Condition, len(src.Status.Conditions))
+		for i, c := range src.Status.Conditions {
+			dst.Status.Conditions[i] = metav1.Condition{
+				Type:               string(c.Type),
+				Status:             metav1.ConditionStatus(c.Status),
+				ObservedGeneration: c.ObservedGeneration,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	if src.Status.OverflowReplicas != nil {
+		dst.Status.OverflowReplicas = make([]v1beta1.PoolOverflow, len(src.Status.OverflowReplicas))
+		for i := range src.Status.OverflowReplicas {
+			dst.Status.OverflowReplicas[i] = v1beta1.PoolOverflow(src.Status.OverflowReplicas[i])
+		}
+	}
+	if src.Status.Compensations != nil {
+		dst.Status.Compensations = make([]v1beta1.PoolCompensation, len(src.Status.Compensations))
+		for i := range src.Status.Compensations {
+			dst.Status.Compensations[i] = v1beta1.PoolCompensation(src.Status.Compensations[i])
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this YurtAppSet.
+func (dst *YurtAppSet) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.YurtAppSet)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = YurtAppSetSpec{
+		Selector:             src.Spec.Selector,
+		WorkloadTemplate:     convertWorkloadTemplateFromHub(src.Spec.WorkloadTemplate),
+		Topology:             Topology{Pools: convertPoolsFromHub(src.Spec.Topology.Pools)},
+		ServiceTemplate:      (*ServiceTemplateSpec)(src.Spec.ServiceTemplate),
+		ElasticPlacement:     (*ElasticPlacementStrategy)(src.Spec.ElasticPlacement),
+		AutonomyCompensation: (*AutonomyCompensationPolicy)(src.Spec.AutonomyCompensation),
+		RevisionHistoryLimit: src.Spec.RevisionHistoryLimit,
+	}
+	dst.Status = YurtAppSetStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CollisionCount:     src.Status.CollisionCount,
+		CurrentRevision:    src.Status.CurrentRevision,
+		PoolReplicas:       src.Status.PoolReplicas,
+		ReadyReplicas:      src.Status.ReadyReplicas,
+		Replicas:           src.Status.Replicas,
+		TemplateType:       TemplateType(src.Status.TemplateType),
+	}
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]YurtAppSetCondition, len(src.Status.Conditions))
+		for i, c := range src.Status.Conditions {
+			dst.Status.Conditions[i] = YurtAppSetCondition{
+				Type:               YurtAppSetConditionType(c.Type),
+				Status:             corev1.ConditionStatus(c.Status),
+				ObservedGeneration: c.ObservedGeneration,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	if src.Status.OverflowReplicas != nil {
+		dst.Status.OverflowReplicas = make([]PoolOverflow, len(src.Status.OverflowReplicas))
+		for i := range src.Status.OverflowReplicas {
+			dst.Status.OverflowReplicas[i] = PoolOverflow(src.Status.OverflowReplicas[i])
+		}
+	}
+	if src.Status.Compensations != nil {
+		dst.Status.Compensations = make([]PoolCompensation, len(src.Status.Compensations))
+		for i := range src.Status.Compensations {
+			dst.Status.Compensations[i] = PoolCompensation(src.Status.Compensations[i])
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this YurtAppDaemon to the hub version.
+func (src *YurtAppDaemon) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.YurtAppDaemon)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.YurtAppDaemonSpec{
+		Selector:             src.Spec.Selector,
+		WorkloadTemplate:     convertWorkloadTemplateToHub(src.Spec.WorkloadTemplate),
+		NodePoolSelector:     src.Spec.NodePoolSelector,
+		RevisionHistoryLimit: src.Spec.RevisionHistoryLimit,
+	}
+	dst.Status = v1beta1.YurtAppDaemonStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CollisionCount:     src.Status.CollisionCount,
+		CurrentRevision:    src.Status.CurrentRevision,
+		TemplateType:       v1beta1.TemplateType(src.Status.TemplateType),
+		NodePools:          src.Status.NodePools,
+	}
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]metav1.Condition, len(src.Status.Conditions))
+		for i, c := range src.Status.Conditions {
+			dst.Status.Conditions[i] = metav1.Condition{
+				Type:               string(c.Type),
+				Status:             metav1.ConditionStatus(c.Status),
+				ObservedGeneration: c.ObservedGeneration,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this YurtAppDaemon.
+func (dst *YurtAppDaemon) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.YurtAppDaemon)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = YurtAppDaemonSpec{
+		Selector:             src.Spec.Selector,
+		WorkloadTemplate:     convertWorkloadTemplateFromHub(src.Spec.WorkloadTemplate),
+		NodePoolSelector:     src.Spec.NodePoolSelector,
+		RevisionHistoryLimit: src.Spec.RevisionHistoryLimit,
+	}
+	dst.Status = YurtAppDaemonStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		CollisionCount:     src.Status.CollisionCount,
+		CurrentRevision:    src.Status.CurrentRevision,
+		TemplateType:       TemplateType(src.Status.TemplateType),
+		NodePools:          src.Status.NodePools,
+	}
+	if src.Status.Conditions != nil {
+		dst.Status.Conditions = make([]YurtAppDaemonCondition, len(src.Status.Conditions))
+		for i, c := range src.Status.Conditions {
+			dst.Status.Conditions[i] = YurtAppDaemonCondition{
+				Type:               YurtAppDaemonConditionType(c.Type),
+				Status:             corev1.ConditionStatus(c.Status),
+				ObservedGeneration: c.ObservedGeneration,
+				LastTransitionTime: c.LastTransitionTime,
+				Reason:             c.Reason,
+				Message:            c.Message,
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertTo converts this YurtIngress to the hub version.
+func (src *YurtIngress) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.YurtIngress)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = v1beta1.YurtIngressSpec{
+		ControllerType:      v1beta1.IngressControllerType(src.Spec.ControllerType),
+		ControllerTemplate:  (*v1beta1.IngressControllerTemplate)(src.Spec.ControllerTemplate),
+		TemplateSet:         (*v1beta1.IngressTemplateSetReference)(src.Spec.TemplateSet),
+		Namespace:           src.Spec.Namespace,
+		ReplicasPerPool:     src.Spec.Replicas,
+		ControllerImage:     src.Spec.IngressControllerImage,
+		WebhookCertGenImage: src.Spec.IngressWebhookCertGenImage,
+		Config:              src.Spec.Config,
+		Pools:               convertIngressPoolsToHub(src.Spec.Pools),
+		PoolSelector:        src.Spec.PoolSelector,
+		MissingPoolPolicy:   v1beta1.MissingPoolPolicy(src.Spec.MissingPoolPolicy),
+		UpdateStrategy:      (*v1beta1.IngressUpdateStrategy)(src.Spec.UpdateStrategy),
+	}
+	dst.Status = v1beta1.YurtIngressStatus{
+		ReplicasPerPool:     src.Status.Replicas,
+		ReadyPools:          convertIngressPoolsToHub(src.Status.Conditions.IngressReadyPools),
+		ControllerImage:     src.Status.IngressControllerImage,
+		WebhookCertGenImage: src.Status.IngressWebhookCertGenImage,
+		Config:              src.Status.Config,
+		ReadyNum:            src.Status.ReadyNum,
+		UnreadyNum:          src.Status.UnreadyNum,
+		UpdatedNum:          src.Status.UpdatedNum,
+		ObservedGeneration:  src.Status.ObservedGeneration,
+		Conditions:          src.Status.IngressConditions,
+	}
+	if pools := src.Status.Conditions.IngressNotReadyPools; pools != nil {
+		dst.Status.NotReadyPools = make([]v1beta1.IngressNotReadyPool, len(pools))
+		for i := range pools {
+			dst.Status.NotReadyPools[i].Pool = convertIngressPoolToHub(&pools[i].Pool)
+			if info := pools[i].Info; info != nil {
+				dst.Status.NotReadyPools[i].Info = &v1beta1.IngressNotReadyConditionInfo{
+					Type:               v1beta1.IngressNotReadyType(info.Type),
+					LastTransitionTime: info.LastTransitionTime,
+					Reason:             info.Reason,
+					Message:            info.Message,
+				}
+			}
+		}
+	}
+	if src.Status.Pools != nil {
+		dst.Status.Pools = make([]v1beta1.IngressPoolStatus, len(src.Status.Pools))
+		for i, p := range src.Status.Pools {
+			dst.Status.Pools[i] = v1beta1.IngressPoolStatus{
+				Name:               p.Name,
+				Image:              p.Image,
+				ControllerRevision: p.ControllerRevision,
+				TemplateVersion:    p.TemplateVersion,
+			}
+			if p.Endpoints != nil {
+				dst.Status.Pools[i].Endpoints = make([]v1beta1.IngressPoolEndpoint, len(p.Endpoints))
+				for j := range p.Endpoints {
+					dst.Status.Pools[i].Endpoints[j] = v1beta1.IngressPoolEndpoint(p.Endpoints[j])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version to this YurtIngress.
+func (dst *YurtIngress) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.YurtIngress)
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Spec = YurtIngressSpec{
+		ControllerType:             IngressControllerType(src.Spec.ControllerType),
+		ControllerTemplate:         (*IngressControllerTemplate)(src.Spec.ControllerTemplate),
+		TemplateSet:                (*IngressTemplateSetReference)(src.Spec.TemplateSet),
+		Namespace:                  src.Spec.Namespace,
+		Replicas:                   src.Spec.ReplicasPerPool,
+		IngressControllerImage:     src.Spec.ControllerImage,
+		IngressWebhookCertGenImage: src.Spec.WebhookCertGenImage,
+		Config:                     src.Spec.Config,
+		Pools:                      convertIngressPoolsFromHub(src.Spec.Pools),
+		PoolSelector:               src.Spec.PoolSelector,
+		MissingPoolPolicy:          MissingPoolPolicy(src.Spec.MissingPoolPolicy),
+		UpdateStrategy:             (*IngressUpdateStrategy)(src.Spec.UpdateStrategy),
+	}
+	dst.Status = YurtIngressStatus{
+		Replicas:                   src.Status.ReplicasPerPool,
+		Conditions:                 YurtIngressCondition{IngressReadyPools: convertIngressPoolsFromHub(src.Status.ReadyPools)},
+		IngressControllerImage:     src.Status.ControllerImage,
+		IngressWebhookCertGenImage: src.Status.WebhookCertGenImage,
+		Config:                     src.Status.Config,
+		ReadyNum:                   src.Status.ReadyNum,
+		UnreadyNum:                 src.Status.UnreadyNum,
+		UpdatedNum:                 src.Status.UpdatedNum,
+		ObservedGeneration:         src.Status.ObservedGeneration,
+		IngressConditions:          src.Status.Conditions,
+	}
+	if pools := src.Status.NotReadyPools; pools != nil {
+		dst.Status.Conditions.IngressNotReadyPools = make([]IngressNotReadyPool, len(pools))
+		for i := range pools {
+			dst.Status.Conditions.IngressNotReadyPools[i].Pool = convertIngressPoolFromHub(&pools[i].Pool)
+			if info := pools[i].Info; info != nil {
+				dst.Status.Conditions.IngressNotReadyPools[i].Info = &IngressNotReadyConditionInfo{
+					Type:               IngressNotReadyType(info.Type),
+					LastTransitionTime: info.LastTransitionTime,
+					Reason:             info.Reason,
+					Message:            info.Message,
+				}
+			}
+		}
+	}
+	if src.Status.Pools != nil {
+		dst.Status.Pools = make([]IngressPoolStatus, len(src.Status.Pools))
+		for i, p := range src.Status.Pools {
+			dst.Status.Pools[i] = IngressPoolStatus{
+				Name:               p.Name,
+				Image:              p.Image,
+				ControllerRevision: p.ControllerRevision,
+				TemplateVersion:    p.TemplateVersion,
+			}
+			if p.Endpoints != nil {
+				dst.Status.Pools[i].Endpoints = make([]IngressPoolEndpoint, len(p.Endpoints))
+				for j := range p.Endpoints {
+					dst.Status.Pools[i].Endpoints[j] = IngressPoolEndpoint(p.Endpoints[j])
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func convertWorkloadTemplateToHub(in WorkloadTemplate) v1beta1.WorkloadTemplate {
+	return v1beta1.WorkloadTemplate{
+		StatefulSetTemplate: (*v1beta1.StatefulSetTemplateSpec)(in.StatefulSetTemplate),
+		DeploymentTemplate:  (*v1beta1.DeploymentTemplateSpec)(in.DeploymentTemplate),
+		CustomTemplate:      (*v1beta1.CustomTemplateSpec)(in.CustomTemplate),
+	}
+}
+
+func convertWorkloadTemplateFromHub(in v1beta1.WorkloadTemplate) WorkloadTemplate {
+	return WorkloadTemplate{
+		StatefulSetTemplate: (*StatefulSetTemplateSpec)(in.StatefulSetTemplate),
+		DeploymentTemplate:  (*DeploymentTemplateSpec)(in.DeploymentTemplate),
+		CustomTemplate:      (*CustomTemplateSpec)(in.CustomTemplate),
+	}
+}
+
+func convertPoolsToHub(in []Pool) []v1beta1.Pool {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.Pool, len(in))
+	for i := range in {
+		out[i] = v1beta1.Pool(in[i])
+	}
+	return out
+}
+
+func convertPoolsFromHub(in []v1beta1.Pool) []Pool {
+	if in == nil {
+		return nil
+	}
+	out := make([]Pool, len(in))
+	for i := range in {
+		out[i] = Pool(in[i])
+	}
+	return out
+}
+
+func convertIngressPoolToHub(in *IngressPool) v1beta1.IngressPool {
+	return v1beta1.IngressPool{
+		Name:               in.Name,
+		IngressIPs:         in.IngressIPs,
+		Replicas:           in.Replicas,
+		Image:              in.Image,
+		Resources:          in.Resources,
+		ExtraArgs:          in.ExtraArgs,
+		NodeSelector:       in.NodeSelector,
+		Tolerations:        in.Tolerations,
+		ServiceAnnotations: in.ServiceAnnotations,
+		Service:            (*v1beta1.IngressPoolService)(in.Service),
+		Config:             in.Config,
+	}
+}
+
+func convertIngressPoolFromHub(in *v1beta1.IngressPool) IngressPool {
+	return IngressPool{
+		Name:               in.Name,
+		IngressIPs:         in.IngressIPs,
+		Replicas:           in.Replicas,
+		Image:              in.Image,
+		Resources:          in.Resources,
+		ExtraArgs:          in.ExtraArgs,
+		NodeSelector:       in.NodeSelector,
+		Tolerations:        in.Tolerations,
+		ServiceAnnotations: in.ServiceAnnotations,
+		Service:            (*IngressPoolService)(in.Service),
+		Config:             in.Config,
+	}
+}
+
+func convertIngressPoolsToHub(in []IngressPool) []v1beta1.IngressPool {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1beta1.IngressPool, len(in))
+	for i := range in {
+		out[i] = convertIngressPoolToHub(&in[i])
+	}
+	return out
+}
+
+func convertIngressPoolsFromHub(in []v1beta1.IngressPool) []IngressPool {
+	if in == nil {
+		return nil
+	}
+	out := make([]IngressPool, len(in))
+	for i := range in {
+		out[i] = convertIngressPoolFromHub(&in[i])
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/diff"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1beta1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		spoke func() conversion.Convertible
+		hub   func() conversion.Hub
+	}{
+		{"NodePool", func() conversion.Convertible { return &NodePool{} }, func() conversion.Hub { return &v1beta1.NodePool{} }},
+		{"YurtAppSet", func() conversion.Convertible { return &YurtAppSet{} }, func() conversion.Hub { return &v1beta1.YurtAppSet{} }},
+		{"YurtAppDaemon", func() conversion.Convertible { return &YurtAppDaemon{} }, func() conversion.Hub { return &v1beta1.YurtAppDaemon{} }},
+		{"YurtIngress", func() conversion.Convertible { return &YurtIngress{} }, func() conversion.Hub { return &v1beta1.YurtIngress{} }},
+	}
+
+	f := fuzz.New().NilChance(0.2).NumElements(0, 3).Funcs(
+		// gofuzz can not fill the Object interface, the raw bytes are enough for the conversion
+		func(e *runtime.RawExtension, c fuzz.Continue) {
+			c.Fuzz(&e.Raw)
+		},
+		// the conversion webhook sets the TypeMeta of the converted objects
+		func(*metav1.TypeMeta, fuzz.Continue) {},
+	)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				// v1alpha1 -> v1beta1 -> v1alpha1
+				spoke := tt.spoke()
+				f.Fuzz(spoke)
+				hub := tt.hub()
+				if err := spoke.ConvertTo(hub); err != nil {
+					t.Fatalf("fail to convert to the hub: %v", err)
+				}
+				got := tt.spoke()
+				if err := got.ConvertFrom(hub); err != nil {
+					t.Fatalf("fail to convert from the hub: %v", err)
+				}
+				if !reflect.DeepEqual(spoke, got) {
+					t.Fatalf("v1alpha1 is changed by the round trip: %s", diff.ObjectReflectDiff(spoke, got))
+				}
+
+				// v1beta1 -> v1alpha1 -> v1beta1
+				hub = tt.hub()
+				f.Fuzz(hub)
+				spoke = tt.spoke()
+				if err := spoke.ConvertFrom(hub); err != nil {
+					t.Fatalf("fail to convert from the hub: %v", err)
+				}
+				gotHub := tt.hub()
+				if err := spoke.ConvertTo(gotHub); err != nil {
+					t.Fatalf("fail to convert to the hub: %v", err)
+				}
+				if !reflect.DeepEqual(hub, gotHub) {
+					t.Fatalf("v1beta1 is changed by the round trip: %s", diff.ObjectReflectDiff(hub, gotHub))
+				}
+			}
+		})
+	}
+}
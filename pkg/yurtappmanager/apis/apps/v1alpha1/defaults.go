@@ -23,18 +23,25 @@ import (
 )
 
 const (
-	defaultIngressControllerImage     string = "k8s.gcr.io/ingress-nginx/controller:v0.48.1"
-	defaultIngressWebhookCertGenImage string = "docker.io/jettech/kube-webhook-certgen:v1.5.1"
+	defaultIngressControllerImage string = "k8s.gcr.io/ingress-nginx/controller:v0.48.1"
+	defaultTraefikImage           string = "docker.io/library/traefik:v2.5.4"
 )
 
 // SetDefaultsYurtIngress set default values for YurtIngress.
 func SetDefaultsYurtIngress(obj *YurtIngress) {
 
-	if obj.Spec.IngressControllerImage == "" {
-		obj.Spec.IngressControllerImage = defaultIngressControllerImage
+	if obj.Spec.ControllerType == "" {
+		obj.Spec.ControllerType = NginxIngressController
 	}
-	if obj.Spec.IngressWebhookCertGenImage == "" {
-		obj.Spec.IngressWebhookCertGenImage = defaultIngressWebhookCertGenImage
+	switch obj.Spec.ControllerType {
+	case NginxIngressController:
+		if obj.Spec.IngressControllerImage == "" {
+			obj.Spec.IngressControllerImage = defaultIngressControllerImage
+		}
+	case TraefikIngressController:
+		if obj.Spec.IngressControllerImage == "" {
+			obj.Spec.IngressControllerImage = defaultTraefikImage
+		}
 	}
 	if obj.Spec.Replicas == 0 {
 		obj.Spec.Replicas = 1
@@ -85,6 +92,22 @@ func SetDefaultsYurtAppSet(obj *YurtAppSet) {
 	if obj.Spec.WorkloadTemplate.DeploymentTemplate != nil {
 		SetDefaultPodSpec(&obj.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Spec)
 	}
+	if obj.Spec.ServiceTemplate != nil {
+		svc := &corev1.Service{Spec: obj.Spec.ServiceTemplate.Spec}
+		v1.SetObjectDefaults_Service(svc)
+		obj.Spec.ServiceTemplate.Spec = svc.Spec
+	}
+	if obj.Spec.ElasticPlacement != nil && obj.Spec.ElasticPlacement.UnschedulableThresholdSeconds == nil {
+		obj.Spec.ElasticPlacement.UnschedulableThresholdSeconds = utilpointer.Int32Ptr(300)
+	}
+	if obj.Spec.AutonomyCompensation != nil {
+		if obj.Spec.AutonomyCompensation.MinReadyNodePercentage == nil {
+			obj.Spec.AutonomyCompensation.MinReadyNodePercentage = utilpointer.Int32Ptr(50)
+		}
+		if obj.Spec.AutonomyCompensation.OfflineThresholdSeconds == nil {
+			obj.Spec.AutonomyCompensation.OfflineThresholdSeconds = utilpointer.Int32Ptr(300)
+		}
+	}
 
 }
 
@@ -66,6 +66,12 @@ type NodePoolStatus struct {
 	// The list of nodes' names in the pool
 	// +optional
 	Nodes []string `json:"nodes,omitempty"`
+
+	// Represents the latest available observations of a NodePool's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -97,6 +97,10 @@ type YurtAppDaemonCondition struct {
 
 	// A human readable message indicating details about the transition.
 	Message string `json:"message,omitempty"`
+
+	// The generation of the YurtAppDaemon which the condition was set based upon.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +genclient
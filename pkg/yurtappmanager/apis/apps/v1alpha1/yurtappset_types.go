@@ -33,6 +33,7 @@ type TemplateType string
 const (
 	StatefulSetTemplateType TemplateType = "StatefulSet"
 	DeploymentTemplateType  TemplateType = "Deployment"
+	CustomTemplateType      TemplateType = "Custom"
 )
 
 // YurtAppSetConditionType indicates valid conditions type of a YurtAppSet.
@@ -61,6 +62,23 @@ type YurtAppSetSpec struct {
 	// +optional
 	Topology Topology `json:"topology,omitempty"`
 
+	// ServiceTemplate describes the Service that will be created for every pool.
+	// The Service only selects the pods of its own pool, and the ServiceName of
+	// each pool's StatefulSet is rewritten to the name of the pool's Service.
+	// +optional
+	ServiceTemplate *ServiceTemplateSpec `json:"serviceTemplate,omitempty"`
+
+	// ElasticPlacement enables the elastic placement mode. The replicas which stay unschedulable in a pool
+	// are moved to the fallback pools, and are moved back when the pool is able to schedule them again.
+	// +optional
+	ElasticPlacement *ElasticPlacementStrategy `json:"elasticPlacement,omitempty"`
+
+	// AutonomyCompensation enables the compensating replicas for the pools whose NodePool goes offline.
+	// The pods of an offline pool keep running under edge autonomy and are never deleted, while the same
+	// number of replicas is temporarily added to a healthy pool until the offline pool recovers.
+	// +optional
+	AutonomyCompensation *AutonomyCompensationPolicy `json:"autonomyCompensation,omitempty"`
+
 	// Indicates the number of histories to be conserved.
 	// If unspecified, defaults to 10.
 	// +optional
@@ -69,7 +87,7 @@ type YurtAppSetSpec struct {
 
 // WorkloadTemplate defines the pool template under the YurtAppSet.
 // YurtAppSet will provision every pool based on one workload templates in WorkloadTemplate.
-// WorkloadTemplate now support statefulset and deployment
+// WorkloadTemplate now support statefulset, deployment and the custom workloads declared in the workload registry
 // Only one of its members may be specified.
 type WorkloadTemplate struct {
 	// StatefulSet template
@@ -79,6 +97,11 @@ type WorkloadTemplate struct {
 	// Deployment template
 	// +optional
 	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// Custom template of a workload kind declared in the workload registry, e.g. the CloneSet of OpenKruise.
+	// It is only supported by YurtAppSet.
+	// +optional
+	CustomTemplate *CustomTemplateSpec `json:"custom,omitempty"`
 }
 
 // StatefulSetTemplateSpec defines the pool template of StatefulSet.
@@ -101,6 +124,64 @@ type DeploymentTemplateSpec struct {
 	Spec appsv1.DeploymentSpec `json:"spec"`
 }
 
+// CustomTemplateSpec defines the pool template of a custom workload.
+// The field paths of the replicas, pod template and status of the workload kind are declared in the workload registry.
+type CustomTemplateSpec struct {
+	// APIVersion of the workload, e.g. apps.kruise.io/v1alpha1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the workload, e.g. CloneSet
+	Kind string `json:"kind"`
+
+	// Template is the workload object of the pool. Its metadata and spec are used, the other fields are ignored.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Template runtime.RawExtension `json:"template"`
+}
+
+// ServiceTemplateSpec defines the per-pool Service template.
+// The Service of each pool is named in the format '<yurtappset-name>-<pool-name>'.
+type ServiceTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec corev1.ServiceSpec `json:"spec"`
+}
+
+// ElasticPlacementStrategy defines how the unschedulable replicas of a pool overflow to the fallback pools.
+type ElasticPlacementStrategy struct {
+	// FallbackPools are the names of the pools which take over the unschedulable replicas of the other pools,
+	// e.g. the cloud pool. The replicas are moved to the first fallback pool which has no unschedulable pods.
+	// The replicas of the fallback pools never overflow.
+	FallbackPools []string `json:"fallbackPools"`
+
+	// UnschedulableThresholdSeconds is the number of seconds a pod stays unschedulable before its replica is
+	// moved to the fallback pools. A pool must also stay free of unschedulable pods for the same period
+	// before the moved replicas are returned to it one by one.
+	// If unspecified, defaults to 300.
+	// +optional
+	UnschedulableThresholdSeconds *int32 `json:"unschedulableThresholdSeconds,omitempty"`
+}
+
+// AutonomyCompensationPolicy defines when and where the compensating replicas are added for an offline pool.
+type AutonomyCompensationPolicy struct {
+	// CompensationPools are the names of the pools which receive the compensating replicas.
+	// The replicas are added to the first pool whose NodePool is healthy.
+	CompensationPools []string `json:"compensationPools"`
+
+	// MinReadyNodePercentage is the percentage of ready nodes in a NodePool, below which the pool is considered offline.
+	// If unspecified, defaults to 50.
+	// +optional
+	MinReadyNodePercentage *int32 `json:"minReadyNodePercentage,omitempty"`
+
+	// OfflineThresholdSeconds is the number of seconds a pool stays offline before the compensating replicas are added.
+	// If unspecified, defaults to 300.
+	// +optional
+	OfflineThresholdSeconds *int32 `json:"offlineThresholdSeconds,omitempty"`
+}
+
 // Topology defines the spread detail of each pool under YurtAppSet.
 // A YurtAppSet manages multiple homogeneous workloads which are called pool.
 // Each of pools under the YurtAppSet is described in Topology.
@@ -175,6 +256,58 @@ type YurtAppSetStatus struct {
 
 	// TemplateType indicates the type of PoolTemplate
 	TemplateType TemplateType `json:"templateType"`
+
+	// OverflowReplicas records the replicas which are moved from their pools to the fallback pools.
+	// +optional
+	OverflowReplicas []PoolOverflow `json:"overflowReplicas,omitempty"`
+
+	// Compensations records the offline pools and the replicas added to other pools for them.
+	// +optional
+	Compensations []PoolCompensation `json:"compensations,omitempty"`
+}
+
+// PoolOverflow records the replicas moved from one pool to one fallback pool.
+type PoolOverflow struct {
+	// Pool is the name of the pool which can not schedule the replicas.
+	Pool string `json:"pool"`
+
+	// FallbackPool is the name of the pool which takes over the replicas.
+	FallbackPool string `json:"fallbackPool"`
+
+	// Replicas is the number of the replicas moved to the fallback pool.
+	Replicas int32 `json:"replicas"`
+
+	// Last time the number of the moved replicas changed.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Last time a moved replica was returned to the pool.
+	// +optional
+	LastReturnTime *metav1.Time `json:"lastReturnTime,omitempty"`
+
+	// ReturnBackoffSeconds is how long to wait after the last change before returning a replica to the pool.
+	// It doubles every time a returned replica overflows again, and is reset once all the replicas are returned
+	// and stay scheduled.
+	// +optional
+	ReturnBackoffSeconds int32 `json:"returnBackoffSeconds,omitempty"`
+}
+
+// PoolCompensation records the replicas added to a healthy pool for an offline pool.
+type PoolCompensation struct {
+	// Pool is the name of the offline pool.
+	Pool string `json:"pool"`
+
+	// CompensationPool is the name of the pool which the compensating replicas are added to.
+	// It is empty before the pool has been offline longer than the threshold.
+	// +optional
+	CompensationPool string `json:"compensationPool,omitempty"`
+
+	// Replicas is the number of the compensating replicas.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// OfflineSince is the time the pool was first observed offline.
+	OfflineSince metav1.Time `json:"offlineSince"`
 }
 
 // YurtAppSetCondition describes current state of a YurtAppSet.
@@ -193,6 +326,10 @@ type YurtAppSetCondition struct {
 
 	// A human readable message indicating details about the transition.
 	Message string `json:"message,omitempty"`
+
+	// The generation of the YurtAppSet which the condition was set based upon.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // +genclient
@@ -24,8 +24,55 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutonomyCompensationPolicy) DeepCopyInto(out *AutonomyCompensationPolicy) {
+	*out = *in
+	if in.CompensationPools != nil {
+		in, out := &in.CompensationPools, &out.CompensationPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinReadyNodePercentage != nil {
+		in, out := &in.MinReadyNodePercentage, &out.MinReadyNodePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OfflineThresholdSeconds != nil {
+		in, out := &in.OfflineThresholdSeconds, &out.OfflineThresholdSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutonomyCompensationPolicy.
+func (in *AutonomyCompensationPolicy) DeepCopy() *AutonomyCompensationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AutonomyCompensationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomTemplateSpec) DeepCopyInto(out *CustomTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomTemplateSpec.
+func (in *CustomTemplateSpec) DeepCopy() *CustomTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentTemplateSpec) DeepCopyInto(out *DeploymentTemplateSpec) {
 	*out = *in
@@ -43,6 +90,46 @@ func (in *DeploymentTemplateSpec) DeepCopy() *DeploymentTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticPlacementStrategy) DeepCopyInto(out *ElasticPlacementStrategy) {
+	*out = *in
+	if in.FallbackPools != nil {
+		in, out := &in.FallbackPools, &out.FallbackPools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnschedulableThresholdSeconds != nil {
+		in, out := &in.UnschedulableThresholdSeconds, &out.UnschedulableThresholdSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticPlacementStrategy.
+func (in *ElasticPlacementStrategy) DeepCopy() *ElasticPlacementStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticPlacementStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressControllerTemplate) DeepCopyInto(out *IngressControllerTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressControllerTemplate.
+func (in *IngressControllerTemplate) DeepCopy() *IngressControllerTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressControllerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressNotReadyConditionInfo) DeepCopyInto(out *IngressNotReadyConditionInfo) {
 	*out = *in
@@ -88,6 +175,54 @@ func (in *IngressPool) DeepCopyInto(out *IngressPool) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(IngressPoolService)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressPool.
@@ -100,6 +235,101 @@ func (in *IngressPool) DeepCopy() *IngressPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressPoolEndpoint) DeepCopyInto(out *IngressPoolEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressPoolEndpoint.
+func (in *IngressPoolEndpoint) DeepCopy() *IngressPoolEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressPoolEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressPoolService) DeepCopyInto(out *IngressPoolService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressPoolService.
+func (in *IngressPoolService) DeepCopy() *IngressPoolService {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressPoolService)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressPoolStatus) DeepCopyInto(out *IngressPoolStatus) {
+	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make([]IngressPoolEndpoint, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressPoolStatus.
+func (in *IngressPoolStatus) DeepCopy() *IngressPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressTemplateSetReference) DeepCopyInto(out *IngressTemplateSetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressTemplateSetReference.
+func (in *IngressTemplateSetReference) DeepCopy() *IngressTemplateSetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressTemplateSetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressUpdateStrategy) DeepCopyInto(out *IngressUpdateStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Order != nil {
+		in, out := &in.Order, &out.Order
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressUpdateStrategy.
+func (in *IngressUpdateStrategy) DeepCopy() *IngressUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodePool) DeepCopyInto(out *NodePool) {
 	*out = *in
@@ -208,6 +438,13 @@ func (in *NodePoolStatus) DeepCopyInto(out *NodePoolStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolStatus.
@@ -253,6 +490,59 @@ func (in *Pool) DeepCopy() *Pool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolCompensation) DeepCopyInto(out *PoolCompensation) {
+	*out = *in
+	in.OfflineSince.DeepCopyInto(&out.OfflineSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolCompensation.
+func (in *PoolCompensation) DeepCopy() *PoolCompensation {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolCompensation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolOverflow) DeepCopyInto(out *PoolOverflow) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.LastReturnTime != nil {
+		in, out := &in.LastReturnTime, &out.LastReturnTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolOverflow.
+func (in *PoolOverflow) DeepCopy() *PoolOverflow {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolOverflow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceTemplateSpec) DeepCopyInto(out *ServiceTemplateSpec) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceTemplateSpec.
+func (in *ServiceTemplateSpec) DeepCopy() *ServiceTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StatefulSetTemplateSpec) DeepCopyInto(out *StatefulSetTemplateSpec) {
 	*out = *in
@@ -305,6 +595,11 @@ func (in *WorkloadTemplate) DeepCopyInto(out *WorkloadTemplate) {
 		*out = new(DeploymentTemplateSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CustomTemplate != nil {
+		in, out := &in.CustomTemplate, &out.CustomTemplate
+		*out = new(CustomTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadTemplate.
@@ -540,6 +835,21 @@ func (in *YurtAppSetSpec) DeepCopyInto(out *YurtAppSetSpec) {
 	}
 	in.WorkloadTemplate.DeepCopyInto(&out.WorkloadTemplate)
 	in.Topology.DeepCopyInto(&out.Topology)
+	if in.ServiceTemplate != nil {
+		in, out := &in.ServiceTemplate, &out.ServiceTemplate
+		*out = new(ServiceTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ElasticPlacement != nil {
+		in, out := &in.ElasticPlacement, &out.ElasticPlacement
+		*out = new(ElasticPlacementStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AutonomyCompensation != nil {
+		in, out := &in.AutonomyCompensation, &out.AutonomyCompensation
+		*out = new(AutonomyCompensationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RevisionHistoryLimit != nil {
 		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
 		*out = new(int32)
@@ -579,6 +889,20 @@ func (in *YurtAppSetStatus) DeepCopyInto(out *YurtAppSetStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.OverflowReplicas != nil {
+		in, out := &in.OverflowReplicas, &out.OverflowReplicas
+		*out = make([]PoolOverflow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Compensations != nil {
+		in, out := &in.Compensations, &out.Compensations
+		*out = make([]PoolCompensation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtAppSetStatus.
@@ -682,6 +1006,23 @@ func (in *YurtIngressList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *YurtIngressSpec) DeepCopyInto(out *YurtIngressSpec) {
 	*out = *in
+	if in.ControllerTemplate != nil {
+		in, out := &in.ControllerTemplate, &out.ControllerTemplate
+		*out = new(IngressControllerTemplate)
+		**out = **in
+	}
+	if in.TemplateSet != nil {
+		in, out := &in.TemplateSet, &out.TemplateSet
+		*out = new(IngressTemplateSetReference)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Pools != nil {
 		in, out := &in.Pools, &out.Pools
 		*out = make([]IngressPool, len(*in))
@@ -689,6 +1030,16 @@ func (in *YurtIngressSpec) DeepCopyInto(out *YurtIngressSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PoolSelector != nil {
+		in, out := &in.PoolSelector, &out.PoolSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(IngressUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtIngressSpec.
@@ -705,6 +1056,27 @@ func (in *YurtIngressSpec) DeepCopy() *YurtIngressSpec {
 func (in *YurtIngressStatus) DeepCopyInto(out *YurtIngressStatus) {
 	*out = *in
 	in.Conditions.DeepCopyInto(&out.Conditions)
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]IngressPoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IngressConditions != nil {
+		in, out := &in.IngressConditions, &out.IngressConditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new YurtIngressStatus.
@@ -0,0 +1,347 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+Copyright 2020 The Kruise Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+@CHANGELOG
+OpenYurt Authors:
+change UnitedDeployment API Definition
+*/
+
+package v1beta1
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type TemplateType string
+
+const (
+	StatefulSetTemplateType TemplateType = "StatefulSet"
+	DeploymentTemplateType  TemplateType = "Deployment"
+	CustomTemplateType      TemplateType = "Custom"
+)
+
+// YurtAppSetConditionType indicates valid conditions type of a YurtAppSet.
+type YurtAppSetConditionType string
+
+const (
+	// PoolProvisioned means all the expected pools are provisioned and unexpected pools are deleted.
+	PoolProvisioned YurtAppSetConditionType = "PoolProvisioned"
+	// PoolUpdated means all the pools are updated.
+	PoolUpdated YurtAppSetConditionType = "PoolUpdated"
+	// PoolFailure is added to a YurtAppSet when one of its pools has failure during its own reconciling.
+	PoolFailure YurtAppSetConditionType = "PoolFailure"
+)
+
+// YurtAppSetSpec defines the desired state of YurtAppSet.
+type YurtAppSetSpec struct {
+	// Selector is a label query over pods that should match the replica count.
+	// It must match the pod template's labels.
+	Selector *metav1.LabelSelector `json:"selector"`
+
+	// WorkloadTemplate describes the pool that will be created.
+	// +optional
+	WorkloadTemplate WorkloadTemplate `json:"workloadTemplate,omitempty"`
+
+	// Topology describes the pods distribution detail between each of pools.
+	// +optional
+	Topology Topology `json:"topology,omitempty"`
+
+	// ServiceTemplate describes the Service that will be created for every pool.
+	// The Service only selects the pods of its own pool, and the ServiceName of
+	// each pool's StatefulSet is rewritten to the name of the pool's Service.
+	// +optional
+	ServiceTemplate *ServiceTemplateSpec `json:"serviceTemplate,omitempty"`
+
+	// ElasticPlacement enables the elastic placement mode. The replicas which stay unschedulable in a pool
+	// are moved to the fallback pools, and are moved back when the pool is able to schedule them again.
+	// +optional
+	ElasticPlacement *ElasticPlacementStrategy `json:"elasticPlacement,omitempty"`
+
+	// AutonomyCompensation enables the compensating replicas for the pools whose NodePool goes offline.
+	// The pods of an offline pool keep running under edge autonomy and are never deleted, while the same
+	// number of replicas is temporarily added to a healthy pool until the offline pool recovers.
+	// +optional
+	AutonomyCompensation *AutonomyCompensationPolicy `json:"autonomyCompensation,omitempty"`
+
+	// Indicates the number of histories to be conserved.
+	// If unspecified, defaults to 10.
+	// +optional
+	// +kubebuilder:default=10
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// WorkloadTemplate defines the pool template under the YurtAppSet.
+// YurtAppSet will provision every pool based on one workload templates in WorkloadTemplate.
+// WorkloadTemplate now support statefulset, deployment and the custom workloads declared in the workload registry
+// Only one of its members may be specified.
+type WorkloadTemplate struct {
+	// StatefulSet template
+	// +optional
+	StatefulSetTemplate *StatefulSetTemplateSpec `json:"statefulSetTemplate,omitempty"`
+
+	// Deployment template
+	// +optional
+	DeploymentTemplate *DeploymentTemplateSpec `json:"deploymentTemplate,omitempty"`
+
+	// Custom template of a workload kind declared in the workload registry, e.g. the CloneSet of OpenKruise.
+	// It is only supported by YurtAppSet.
+	// +optional
+	CustomTemplate *CustomTemplateSpec `json:"customTemplate,omitempty"`
+}
+
+// StatefulSetTemplateSpec defines the pool template of StatefulSet.
+type StatefulSetTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec appsv1.StatefulSetSpec `json:"spec"`
+}
+
+// DeploymentTemplateSpec defines the pool template of Deployment.
+type DeploymentTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec appsv1.DeploymentSpec `json:"spec"`
+}
+
+// CustomTemplateSpec defines the pool template of a custom workload.
+// The field paths of the replicas, pod template and status of the workload kind are declared in the workload registry.
+type CustomTemplateSpec struct {
+	// APIVersion of the workload, e.g. apps.kruise.io/v1alpha1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the workload, e.g. CloneSet
+	Kind string `json:"kind"`
+
+	// Template is the workload object of the pool. Its metadata and spec are used, the other fields are ignored.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Template runtime.RawExtension `json:"template"`
+}
+
+// ServiceTemplateSpec defines the per-pool Service template.
+// The Service of each pool is named in the format '<yurtappset-name>-<pool-name>'.
+type ServiceTemplateSpec struct {
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Spec corev1.ServiceSpec `json:"spec"`
+}
+
+// ElasticPlacementStrategy defines how the unschedulable replicas of a pool overflow to the fallback pools.
+type ElasticPlacementStrategy struct {
+	// FallbackPools are the names of the pools which take over the unschedulable replicas of the other pools,
+	// e.g. the cloud pool. The replicas are moved to the first fallback pool which has no unschedulable pods.
+	// The replicas of the fallback pools never overflow.
+	FallbackPools []string `json:"fallbackPools"`
+
+	// UnschedulableThresholdSeconds is the number of seconds a pod stays unschedulable before its replica is
+	// moved to the fallback pools. A pool must also stay free of unschedulable pods for the same period
+	// before the moved replicas are returned to it one by one.
+	// If unspecified, defaults to 300.
+	// +optional
+	// +kubebuilder:default=300
+	UnschedulableThresholdSeconds *int32 `json:"unschedulableThresholdSeconds,omitempty"`
+}
+
+// AutonomyCompensationPolicy defines when and where the compensating replicas are added for an offline pool.
+type AutonomyCompensationPolicy struct {
+	// CompensationPools are the names of the pools which receive the compensating replicas.
+	// The replicas are added to the first pool whose NodePool is healthy.
+	CompensationPools []string `json:"compensationPools"`
+
+	// MinReadyNodePercentage is the percentage of ready nodes in a NodePool, below which the pool is considered offline.
+	// If unspecified, defaults to 50.
+	// +optional
+	// +kubebuilder:default=50
+	MinReadyNodePercentage *int32 `json:"minReadyNodePercentage,omitempty"`
+
+	// OfflineThresholdSeconds is the number of seconds a pool stays offline before the compensating replicas are added.
+	// If unspecified, defaults to 300.
+	// +optional
+	// +kubebuilder:default=300
+	OfflineThresholdSeconds *int32 `json:"offlineThresholdSeconds,omitempty"`
+}
+
+// Topology defines the spread detail of each pool under YurtAppSet.
+// A YurtAppSet manages multiple homogeneous workloads which are called pool.
+// Each of pools under the YurtAppSet is described in Topology.
+type Topology struct {
+	// Contains the details of each pool. Each element in this array represents one pool
+	// which will be provisioned and managed by YurtAppSet.
+	// +optional
+	Pools []Pool `json:"pools,omitempty"`
+}
+
+// Pool defines the detail of a pool.
+type Pool struct {
+	// Indicates pool name as a DNS_LABEL, which will be used to generate
+	// pool workload name prefix in the format '<deployment-name>-<pool-name>-'.
+	// Name should be unique between all of the pools under one YurtAppSet.
+	// Name is NodePool Name
+	Name string `json:"name"`
+
+	// Indicates the node selector to form the pool. Depending on the node selector,
+	// pods provisioned could be distributed across multiple groups of nodes.
+	// A pool's nodeSelectorTerm is not allowed to be updated.
+	// +optional
+	NodeSelectorTerm corev1.NodeSelectorTerm `json:"nodeSelectorTerm,omitempty"`
+
+	// Indicates the tolerations the pods under this pool have.
+	// A pool's tolerations is not allowed to be updated.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Indicates the number of the pod to be created under this pool.
+	// +required
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Indicates the patch for the templateSpec
+	// Now support strategic merge path :https://kubernetes.io/docs/tasks/manage-kubernetes-objects/update-api-object-kubectl-patch/#notes-on-the-strategic-merge-patch
+	// Patch takes precedence over Replicas fields
+	// If the Patch also modifies the Replicas, use the Replicas value in the Patch
+	// +optional
+	Patch *runtime.RawExtension `json:"patch,omitempty"`
+}
+
+// YurtAppSetStatus defines the observed state of YurtAppSet.
+type YurtAppSetStatus struct {
+	// ObservedGeneration is the most recent generation observed for this YurtAppSet. It corresponds to the
+	// YurtAppSet's generation, which is updated on mutation by the API Server.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Count of hash collisions for the YurtAppSet. The YurtAppSet controller
+	// uses this field as a collision avoidance mechanism when it needs to
+	// create the name for the newest ControllerRevision.
+	// +optional
+	CollisionCount *int32 `json:"collisionCount,omitempty"`
+
+	// CurrentRevision, if not empty, indicates the current version of the YurtAppSet.
+	CurrentRevision string `json:"currentRevision"`
+
+	// Represents the latest available observations of a YurtAppSet's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Records the topology detail information of the replicas of each pool.
+	// +optional
+	PoolReplicas map[string]int32 `json:"poolReplicas,omitempty"`
+
+	// The number of ready replicas.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// Replicas is the most recently observed number of replicas.
+	Replicas int32 `json:"replicas"`
+
+	// TemplateType indicates the type of PoolTemplate
+	TemplateType TemplateType `json:"templateType"`
+
+	// OverflowReplicas records the replicas which are moved from their pools to the fallback pools.
+	// +optional
+	OverflowReplicas []PoolOverflow `json:"overflowReplicas,omitempty"`
+
+	// Compensations records the offline pools and the replicas added to other pools for them.
+	// +optional
+	Compensations []PoolCompensation `json:"compensations,omitempty"`
+}
+
+// PoolOverflow records the replicas moved from one pool to one fallback pool.
+type PoolOverflow struct {
+	// Pool is the name of the pool which can not schedule the replicas.
+	Pool string `json:"pool"`
+
+	// FallbackPool is the name of the pool which takes over the replicas.
+	FallbackPool string `json:"fallbackPool"`
+
+	// Replicas is the number of the replicas moved to the fallback pool.
+	Replicas int32 `json:"replicas"`
+
+	// Last time the number of the moved replicas changed.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Last time a moved replica was returned to the pool.
+	// +optional
+	LastReturnTime *metav1.Time `json:"lastReturnTime,omitempty"`
+
+	// ReturnBackoffSeconds is how long to wait after the last change before returning a replica to the pool.
+	// It doubles every time a returned replica overflows again, and is reset once all the replicas are returned
+	// and stay scheduled.
+	// +optional
+	ReturnBackoffSeconds int32 `json:"returnBackoffSeconds,omitempty"`
+}
+
+// PoolCompensation records the replicas added to a healthy pool for an offline pool.
+type PoolCompensation struct {
+	// Pool is the name of the offline pool.
+	Pool string `json:"pool"`
+
+	// CompensationPool is the name of the pool which the compensating replicas are added to.
+	// It is empty before the pool has been offline longer than the threshold.
+	// +optional
+	CompensationPool string `json:"compensationPool,omitempty"`
+
+	// Replicas is the number of the compensating replicas.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// OfflineSince is the time the pool was first observed offline.
+	OfflineSince metav1.Time `json:"offlineSince"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:shortName=yas
+// +kubebuilder:printcolumn:name="READY",type="integer",JSONPath=".status.readyReplicas",description="The number of pods ready."
+// +kubebuilder:printcolumn:name="WorkloadTemplate",type="string",JSONPath=".status.templateType",description="The WorkloadTemplate Type."
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp",description="CreationTimestamp is a timestamp representing the server time when this object was created. It is not guaranteed to be set in happens-before order across separate operations. Clients may not set this value. It is represented in RFC3339 form and is in UTC."
+
+// YurtAppSet is the Schema for the yurtAppSets API
+type YurtAppSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   YurtAppSetSpec   `json:"spec,omitempty"`
+	Status YurtAppSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// YurtAppSetList contains a list of YurtAppSet
+type YurtAppSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []YurtAppSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&YurtAppSet{}, &YurtAppSetList{})
+}
@@ -0,0 +1,425 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// YurtIngressFinalizer is used to cleanup ingress resources when YurtIngress CR is deleted
+const YurtIngressFinalizer string = "ingress.operator.openyurt.io"
+
+// IngressNodePoolKey is the label of the IngressClass created for a pool, and the annotation of an Ingress
+// which should only be served by the ingress controller of the pool.
+const IngressNodePoolKey string = "yurtingress.io/nodepool"
+
+// IngressYurtIngressKey is the annotation of an Ingress which chooses the YurtIngress whose ingress controller
+// serves it, when more than one YurtIngress enables ingress on the pool of the Ingress.
+const IngressYurtIngressKey string = "yurtingress.io/yurtingress"
+
+type IngressNotReadyType string
+
+const (
+	IngressPending IngressNotReadyType = "Pending"
+	IngressFailure IngressNotReadyType = "Failure"
+)
+
+// The reasons why the ingress controller of a pool is not ready.
+const (
+	// IngressControllerNotFound means the ingress controller Deployment of the pool does not exist.
+	IngressControllerNotFound = "ControllerNotFound"
+	// IngressControllerUpdating means the ingress controller of the pool is being created or updated.
+	IngressControllerUpdating = "ControllerUpdating"
+	// IngressControllerUnavailable means not all the replicas of the ingress controller are updated and available.
+	IngressControllerUnavailable = "ControllerUnavailable"
+	// IngressReplicaFailure means the replicas of the ingress controller can not be created.
+	IngressReplicaFailure = "ReplicaFailure"
+	// IngressWebhookUnavailable means the admission webhook of the ingress controller is not available.
+	IngressWebhookUnavailable = "WebhookUnavailable"
+	// IngressNoReadyEndpoints means the ingress controller Service has no ready endpoints.
+	IngressNoReadyEndpoints = "NoReadyEndpoints"
+	// IngressCertGenPending means the admission webhook certificate of the ingress controller is not generated yet.
+	IngressCertGenPending = "CertGenPending"
+	// IngressCertGenFailed means the admission webhook certificate of the ingress controller fails to be generated.
+	IngressCertGenFailed = "CertGenFailed"
+	// IngressNodePoolNotFound means the NodePool of the pool does not exist.
+	IngressNodePoolNotFound = "NodePoolNotFound"
+	// IngressNodePoolEmpty means the NodePool of the pool has no nodes.
+	IngressNodePoolEmpty = "NodePoolEmpty"
+)
+
+// MissingPoolPolicy defines what is done with the ingress controller of a pool whose NodePool is missing or empty.
+type MissingPoolPolicy string
+
+const (
+	// RetainMissingPool keeps the ingress controller resources of the pool, which is the default.
+	RetainMissingPool MissingPoolPolicy = "Retain"
+	// DeleteMissingPool deletes the ingress controller resources of the pool, they are created again
+	// once the NodePool has nodes.
+	DeleteMissingPool MissingPoolPolicy = "Delete"
+)
+
+// The types of the conditions of a YurtIngress.
+const (
+	// YurtIngressReady is true when the ingress controllers of all the pools are ready.
+	YurtIngressReady = "Ready"
+	// YurtIngressDegraded is true when the ingress controller of any pool fails.
+	YurtIngressDegraded = "Degraded"
+)
+
+// IngressControllerType is the type of the ingress controller deployed in the pools.
+type IngressControllerType string
+
+const (
+	// NginxIngressController deploys ingress-nginx in the pools.
+	NginxIngressController IngressControllerType = "nginx"
+	// TraefikIngressController deploys traefik in the pools.
+	TraefikIngressController IngressControllerType = "traefik"
+	// TemplateIngressController deploys the ingress controller from the user provided templates.
+	TemplateIngressController IngressControllerType = "template"
+)
+
+// IngressControllerTemplate refers to the configmap which holds the templates of the ingress controller.
+// The configmap should have the key "pool.yaml", and optionally the key "common.yaml", each is a
+// multi-document yaml of go templates, which are rendered for every pool and once for all pools.
+type IngressControllerTemplate struct {
+	// Namespace of the configmap.
+	Namespace string `json:"namespace"`
+
+	// Name of the configmap.
+	Name string `json:"name"`
+}
+
+// IngressTemplateSetReference refers to the configmap which holds the templates overriding the built-in ones of
+// the nginx or traefik ingress controller. The keys of the configmap are the names of the templates, such as
+// deployment.yaml, and "version", the version of the templates.
+type IngressTemplateSetReference struct {
+	// Namespace of the configmap.
+	Namespace string `json:"namespace"`
+
+	// Name of the configmap.
+	Name string `json:"name"`
+
+	// Version of the templates, it should be the same as the version in the configmap. Changing it rolls out the
+	// ingress controllers rendered from the templates of the new version to the pools.
+	Version string `json:"version"`
+}
+
+// IngressPoolService defines how the ingress controller of a pool is exposed.
+type IngressPoolService struct {
+	// Type of the ingress controller service, one of NodePort, LoadBalancer and ClusterIP.
+	// The ingress controller runs with hostNetwork if the type is ClusterIP.
+	// The annotations of a LoadBalancer service are set by serviceAnnotations of the pool.
+	// +optional
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer;ClusterIP
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// The fixed node port of http, only used by the NodePort and LoadBalancer types.
+	// +optional
+	HTTPNodePort int32 `json:"httpNodePort,omitempty"`
+
+	// The fixed node port of https, only used by the NodePort and LoadBalancer types.
+	// +optional
+	HTTPSNodePort int32 `json:"httpsNodePort,omitempty"`
+
+	// Indicates how the external traffic is routed to the ingress controllers, only used by the NodePort
+	// and LoadBalancer types.
+	// +optional
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+}
+
+// IngressPool defines the details of a Pool for ingress
+type IngressPool struct {
+	// Indicates the pool name.
+	Name string `json:"name"`
+
+	// IngressIPs is a list of IP addresses for which nodes will also accept traffic for this service.
+	IngressIPs []string `json:"ingressIPs,omitempty"`
+
+	// Indicates the number of the ingress controllers of the pool, overrides replicasPerPool.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Indicates the ingress controller image url of the pool, overrides controllerImage.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Indicates the compute resources of the ingress controller container of the pool.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Indicates the extra arguments appended to the ingress controller container of the pool.
+	// +optional
+	ExtraArgs []string `json:"extraArgs,omitempty"`
+
+	// Indicates the node selector added to the ingress controller pods of the pool,
+	// besides the node selector of the pool itself.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Indicates the tolerations added to the ingress controller pods of the pool.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Indicates the annotations added to the ingress controller service of the pool.
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// Indicates how the ingress controller of the pool is exposed, defaults to a NodePort service.
+	// +optional
+	Service *IngressPoolService `json:"service,omitempty"`
+
+	// Indicates the configuration of the ingress controller of the pool, which overrides the config of spec.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// IngressPoolEndpoint is an address through which the ingress controller of a pool is accessed.
+type IngressPoolEndpoint struct {
+	// Name of the service port, such as http or https.
+	Name string `json:"name"`
+
+	// IP or hostname of the endpoint.
+	Address string `json:"address"`
+
+	// Port of the endpoint.
+	Port int32 `json:"port"`
+}
+
+// IngressPoolStatus defines the observed state of the ingress controller of a pool.
+type IngressPoolStatus struct {
+	// Indicates the pool name.
+	Name string `json:"name"`
+
+	// Indicates the effective access endpoints of the ingress controller of the pool.
+	// +optional
+	Endpoints []IngressPoolEndpoint `json:"endpoints,omitempty"`
+
+	// Indicates the image of the ingress controller deployed in the pool.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Indicates the hash of the ingress controller configuration applied to the pool.
+	// +optional
+	ControllerRevision string `json:"controllerRevision,omitempty"`
+
+	// Indicates the version of the templates of the ingress controller deployed in the pool,
+	// empty for the built-in templates.
+	// +optional
+	TemplateVersion string `json:"templateVersion,omitempty"`
+}
+
+// IngressUpdateStrategy defines how the ingress controllers of the pools are updated, when the image, resources,
+// arguments, scheduling or configuration of them change. The ingress controllers of the pools are updated in batches,
+// and the next batch is not started until the pools updated before are ready.
+type IngressUpdateStrategy struct {
+	// The maximum number of the pools which are not ready during the update, an absolute number or a percentage
+	// of the pools. Defaults to 1.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// The pools which are updated before the others, in this order. The other pools are updated in the order of
+	// spec.pools after them.
+	// +optional
+	Order []string `json:"order,omitempty"`
+
+	// The canary pools are updated first, and the other pools are not updated as long as canary is set.
+	// Clear it to continue updating the other pools.
+	// +optional
+	Canary []string `json:"canary,omitempty"`
+}
+
+// IngressNotReadyConditionInfo defines the details info of an ingress not ready Pool
+type IngressNotReadyConditionInfo struct {
+	// Type of ingress not ready condition.
+	Type IngressNotReadyType `json:"type,omitempty"`
+
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// IngressNotReadyPool defines the condition details of an ingress not ready Pool
+type IngressNotReadyPool struct {
+	// Indicates the base pool info.
+	Pool IngressPool `json:"pool"`
+
+	// Info of ingress not ready condition.
+	Info *IngressNotReadyConditionInfo `json:"info,omitempty"`
+}
+
+// YurtIngressSpec defines the desired state of YurtIngress
+type YurtIngressSpec struct {
+	// Indicates the type of the ingress controller to be deployed, one of nginx, traefik and template.
+	// Defaults to nginx.
+	// +optional
+	// +kubebuilder:validation:Enum=nginx;traefik;template
+	// +kubebuilder:default=nginx
+	ControllerType IngressControllerType `json:"controllerType,omitempty"`
+
+	// Indicates the templates of the ingress controller, only used when the controller type is template.
+	// +optional
+	ControllerTemplate *IngressControllerTemplate `json:"controllerTemplate,omitempty"`
+
+	// Indicates the templates overriding the built-in ones of the nginx or traefik ingress controller.
+	// Defaults to the templates loaded by yurt-app-manager from --ingress-template-dir, or the built-in ones.
+	// +optional
+	TemplateSet *IngressTemplateSetReference `json:"templateSet,omitempty"`
+
+	// Indicates the namespace of the ingress controllers and their common resources, such as the rbac.
+	// Defaults to ingress-nginx for nginx and ingress-traefik for traefik, the templates of the template type get it
+	// as namespace. Out of the default namespace, the cluster scoped resources, such as the IngressClasses, are
+	// prefixed with the namespace, so the YurtIngresses in different namespaces can enable ingress on the same pool.
+	// The YurtIngresses in the same namespace share the common resources. It is immutable, and required by the
+	// template type.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Indicates the number of the ingress controllers to be deployed under every pool. Defaults to 1.
+	// +optional
+	// +kubebuilder:default=1
+	ReplicasPerPool int32 `json:"replicasPerPool,omitempty"`
+
+	// Indicates the ingress controller image url. Defaults to the image of the controller type.
+	// +optional
+	ControllerImage string `json:"controllerImage,omitempty"`
+
+	// Indicates the ingress webhook image url, which generates the admission webhook certificates of the
+	// ingress controllers in jobs. If it is unset, yurt-app-manager generates and rotates the certificates itself.
+	// +optional
+	WebhookCertGenImage string `json:"webhookCertGenImage,omitempty"`
+
+	// Indicates the configuration of the ingress controllers of all the pools. For the nginx type, it is
+	// rendered into the ConfigMap of every pool together with the config of the pool, see
+	// https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/configmap/
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// Indicates all the nodepools on which to enable ingress.
+	// +optional
+	Pools []IngressPool `json:"pools,omitempty"`
+
+	// Indicates the nodepools on which to enable ingress by their labels, in addition to pools.
+	// The selected nodepools which are not listed in pools use the settings of spec without overrides.
+	// +optional
+	PoolSelector *metav1.LabelSelector `json:"poolSelector,omitempty"`
+
+	// Indicates what is done with the ingress controller of a pool whose NodePool is missing or has no nodes,
+	// one of Retain and Delete. Defaults to Retain.
+	// +optional
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	MissingPoolPolicy MissingPoolPolicy `json:"missingPoolPolicy,omitempty"`
+
+	// Indicates how the ingress controllers of the pools are updated.
+	// +optional
+	UpdateStrategy *IngressUpdateStrategy `json:"updateStrategy,omitempty"`
+}
+
+// YurtIngressStatus defines the observed state of YurtIngress
+type YurtIngressStatus struct {
+	// Indicates the number of the ingress controllers deployed under every pool.
+	// +optional
+	ReplicasPerPool int32 `json:"replicasPerPool,omitempty"`
+
+	// Indicates the pools that ingress controller is deployed successfully.
+	// +optional
+	ReadyPools []IngressPool `json:"readyPools,omitempty"`
+
+	// Indicates the pools that ingress controller is being deployed or deployed failed.
+	// +optional
+	NotReadyPools []IngressNotReadyPool `json:"notReadyPools,omitempty"`
+
+	// Indicates the ingress controller image url.
+	// +optional
+	ControllerImage string `json:"controllerImage,omitempty"`
+
+	// Indicates the ingress webhook image url.
+	// +optional
+	WebhookCertGenImage string `json:"webhookCertGenImage,omitempty"`
+
+	// Indicates the configuration of the ingress controllers of all the pools.
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
+
+	// Total number of ready pools on which ingress is enabled.
+	// +optional
+	ReadyNum int32 `json:"readyNum"`
+
+	// Total number of unready pools on which ingress is enabling or enable failed.
+	// +optional
+	UnreadyNum int32 `json:"unreadyNum"`
+
+	// Indicates the observed state of the ingress controller of every pool.
+	// +optional
+	Pools []IngressPoolStatus `json:"pools,omitempty"`
+
+	// Total number of pools whose ingress controllers are updated to the spec.
+	// +optional
+	UpdatedNum int32 `json:"updatedNum,omitempty"`
+
+	// The generation of the YurtIngress observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Indicates the standard conditions of the YurtIngress, Ready and Degraded.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,path=yurtingresses,shortName=ying,categories=all
+// +kubebuilder:printcolumn:name="Type",type="string",JSONPath=".spec.controllerType",description="The type of the ingress controller"
+// +kubebuilder:printcolumn:name="Replicas-Per-Pool",type="integer",JSONPath=".status.replicasPerPool",description="The ingress controller replicas per pool"
+// +kubebuilder:printcolumn:name="ReadyNum",type="integer",JSONPath=".status.readyNum",description="The number of pools on which ingress is enabled"
+// +kubebuilder:printcolumn:name="NotReadyNum",type="integer",JSONPath=".status.unreadyNum",description="The number of pools on which ingress is enabling or enable failed"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the ingress controllers of all the pools are ready"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+
+// YurtIngress is the Schema for the yurtingresses API
+type YurtIngress struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   YurtIngressSpec   `json:"spec,omitempty"`
+	Status YurtIngressStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// YurtIngressList contains a list of YurtIngress
+type YurtIngressList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []YurtIngress `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&YurtIngress{}, &YurtIngressList{})
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	DefaultMetricsAddr             = ":8080"
+	DefaultHealthProbeAddr         = ":8000"
+	DefaultPprofAddr               = ":8090"
+	DefaultStuckReconcileThreshold = 10 * time.Minute
+	DefaultLeaderElectionNamespace = "kube-system"
+	DefaultQPS                     = 30
+	DefaultBurst                   = 50
+
+	DefaultWebhookPort                        = 9876
+	DefaultWebhookCertDir                     = "/tmp/yurt-app-webhook-certs"
+	DefaultWebhookNamespace                   = "kube-system"
+	DefaultWebhookSecretName                  = "yurt-app-webhook-certs"
+	DefaultWebhookServiceName                 = "yurt-app-webhook-service"
+	DefaultMutatingWebhookConfigurationName   = "yurt-app-mutating-webhook-configuration"
+	DefaultValidatingWebhookConfigurationName = "yurt-app-validating-webhook-configuration"
+
+	DefaultWorkers          = 3
+	DefaultWorkloadRegistry = "kube-system/yurt-app-manager-workload-registry"
+
+	// the rate limiter defaults are the ones of workqueue.DefaultControllerRateLimiter
+	DefaultRateLimiterBaseDelay = 5 * time.Millisecond
+	DefaultRateLimiterMaxDelay  = 1000 * time.Second
+	DefaultRateLimiterQPS       = 10
+	DefaultRateLimiterBurst     = 100
+)
+
+// SetDefaultsYurtAppManagerConfiguration set default values for YurtAppManagerConfiguration.
+func SetDefaultsYurtAppManagerConfiguration(obj *YurtAppManagerConfiguration) {
+	obj.APIVersion = GroupVersion.String()
+	obj.Kind = Kind
+
+	if obj.MetricsAddr == "" {
+		obj.MetricsAddr = DefaultMetricsAddr
+	}
+	if obj.HealthProbeAddr == "" {
+		obj.HealthProbeAddr = DefaultHealthProbeAddr
+	}
+	if obj.StuckReconcileThreshold.Duration == 0 {
+		obj.StuckReconcileThreshold = metav1.Duration{Duration: DefaultStuckReconcileThreshold}
+	}
+	if obj.PprofAddr == "" {
+		obj.PprofAddr = DefaultPprofAddr
+	}
+
+	if obj.LeaderElection.LeaderElect == nil {
+		leaderElect := true
+		obj.LeaderElection.LeaderElect = &leaderElect
+	}
+	if obj.LeaderElection.Namespace == "" {
+		obj.LeaderElection.Namespace = DefaultLeaderElectionNamespace
+	}
+
+	if obj.ClientConnection.QPS == 0 {
+		obj.ClientConnection.QPS = DefaultQPS
+	}
+	if obj.ClientConnection.Burst == 0 {
+		obj.ClientConnection.Burst = DefaultBurst
+	}
+
+	SetDefaultsWebhookConfiguration(&obj.Webhook)
+	SetDefaultsControllersConfiguration(&obj.Controllers)
+}
+
+// SetDefaultsWebhookConfiguration set default values for WebhookConfiguration.
+func SetDefaultsWebhookConfiguration(obj *WebhookConfiguration) {
+	if obj.Port == 0 {
+		obj.Port = DefaultWebhookPort
+	}
+	if obj.CertDir == "" {
+		obj.CertDir = DefaultWebhookCertDir
+	}
+	if obj.Namespace == "" {
+		obj.Namespace = DefaultWebhookNamespace
+	}
+	if obj.SecretName == "" {
+		obj.SecretName = DefaultWebhookSecretName
+	}
+	if obj.ServiceName == "" {
+		obj.ServiceName = DefaultWebhookServiceName
+	}
+	if obj.MutatingWebhookConfigurationName == "" {
+		obj.MutatingWebhookConfigurationName = DefaultMutatingWebhookConfigurationName
+	}
+	if obj.ValidatingWebhookConfigurationName == "" {
+		obj.ValidatingWebhookConfigurationName = DefaultValidatingWebhookConfigurationName
+	}
+}
+
+// SetDefaultsControllersConfiguration set default values for ControllersConfiguration.
+func SetDefaultsControllersConfiguration(obj *ControllersConfiguration) {
+	SetDefaultsControllerConfiguration(&obj.YurtAppSet.ControllerConfiguration)
+	if obj.YurtAppSet.WorkloadRegistry == "" {
+		obj.YurtAppSet.WorkloadRegistry = DefaultWorkloadRegistry
+	}
+	SetDefaultsControllerConfiguration(&obj.YurtAppDaemon.ControllerConfiguration)
+	SetDefaultsControllerConfiguration(&obj.NodePool.ControllerConfiguration)
+	SetDefaultsControllerConfiguration(&obj.YurtIngress.ControllerConfiguration)
+}
+
+// SetDefaultsControllerConfiguration set default values for ControllerConfiguration.
+func SetDefaultsControllerConfiguration(obj *ControllerConfiguration) {
+	if obj.Workers == 0 {
+		obj.Workers = DefaultWorkers
+	}
+	if obj.RateLimiter.BaseDelay.Duration == 0 {
+		obj.RateLimiter.BaseDelay = metav1.Duration{Duration: DefaultRateLimiterBaseDelay}
+	}
+	if obj.RateLimiter.MaxDelay.Duration == 0 {
+		obj.RateLimiter.MaxDelay = metav1.Duration{Duration: DefaultRateLimiterMaxDelay}
+	}
+	if obj.RateLimiter.QPS == 0 {
+		obj.RateLimiter.QPS = DefaultRateLimiterQPS
+	}
+	if obj.RateLimiter.Burst == 0 {
+		obj.RateLimiter.Burst = DefaultRateLimiterBurst
+	}
+}
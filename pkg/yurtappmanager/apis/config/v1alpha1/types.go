@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// GroupVersion is the group version of the configuration file format
+	GroupVersion = schema.GroupVersion{Group: "config.openyurt.io", Version: "v1alpha1"}
+)
+
+// Kind is the kind of the configuration of yurt-app-manager
+const Kind = "YurtAppManagerConfiguration"
+
+// YurtAppManagerConfiguration is the configuration of yurt-app-manager, loaded from the file of --config.
+// The settings of the file override the environment variables, and the command line flags override both.
+type YurtAppManagerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// MetricsAddr is the address the metric endpoint binds to, "0" disables it.
+	MetricsAddr string `json:"metricsAddr,omitempty"`
+	// HealthProbeAddr is the address the healthz/readyz endpoint binds to.
+	HealthProbeAddr string `json:"healthProbeAddr,omitempty"`
+	// StuckReconcileThreshold is the time a work queue of a controller with items processes none of them,
+	// or a reconcile runs, before the liveness check fails.
+	StuckReconcileThreshold metav1.Duration `json:"stuckReconcileThreshold,omitempty"`
+	// EnablePprof enables pprof on PprofAddr.
+	EnablePprof bool `json:"enablePprof,omitempty"`
+	// PprofAddr is the address the pprof binds to.
+	PprofAddr string `json:"pprofAddr,omitempty"`
+	// Namespace restricts the cache of the manager to the objects in the namespace, all namespaces if empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Namespaces restricts the cache of the namespaced resources, such as YurtAppSet, YurtAppDaemon and their workloads,
+	// to the objects in the namespaces. The cluster-scoped resources such as NodePool are cached in the whole cluster.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector is the label selector of the namespaces the cache of the namespaced resources is restricted to,
+	// the manager restarts once the selected namespaces change. At most one of Namespace, Namespaces and
+	// NamespaceSelector can be set.
+	NamespaceSelector string `json:"namespaceSelector,omitempty"`
+
+	LeaderElection   LeaderElectionConfiguration   `json:"leaderElection,omitempty"`
+	ClientConnection ClientConnectionConfiguration `json:"clientConnection,omitempty"`
+
+	// CustomResourceEnable is the kinds of the custom resources whose controllers and webhooks are enabled,
+	// all of them if empty.
+	CustomResourceEnable []string `json:"customResourceEnable,omitempty"`
+
+	Webhook     WebhookConfiguration     `json:"webhook,omitempty"`
+	Controllers ControllersConfiguration `json:"controllers,omitempty"`
+}
+
+// LeaderElectionConfiguration is the leader election of yurt-app-manager
+type LeaderElectionConfiguration struct {
+	// LeaderElect enables the leader election, defaults to true.
+	LeaderElect *bool `json:"leaderElect,omitempty"`
+	// Namespace is the namespace of the lease of the leader election, the in-cluster namespace if empty.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ClientConnectionConfiguration is the connection of the clients to kube-apiserver
+type ClientConnectionConfiguration struct {
+	QPS   float32 `json:"qps,omitempty"`
+	Burst int32   `json:"burst,omitempty"`
+}
+
+// WebhookConfiguration is the webhook server and the objects of the webhooks in the cluster
+type WebhookConfiguration struct {
+	// Host is the address the webhook server binds to, all addresses if empty.
+	Host string `json:"host,omitempty"`
+	Port int32  `json:"port,omitempty"`
+	// CertDir is the directory of the serving certificate tls.crt and tls.key.
+	CertDir string `json:"certDir,omitempty"`
+	// Namespace is the namespace of the secret and the service of the webhooks.
+	Namespace   string `json:"namespace,omitempty"`
+	SecretName  string `json:"secretName,omitempty"`
+	ServiceName string `json:"serviceName,omitempty"`
+
+	MutatingWebhookConfigurationName   string `json:"mutatingWebhookConfigurationName,omitempty"`
+	ValidatingWebhookConfigurationName string `json:"validatingWebhookConfigurationName,omitempty"`
+
+	// ManageCerts generates and rotates the serving certificate, and injects its CA into the webhook configurations
+	// and the conversion webhooks of the CRDs.
+	ManageCerts bool `json:"manageCerts,omitempty"`
+}
+
+// ControllersConfiguration is the configuration of every controller
+type ControllersConfiguration struct {
+	YurtAppSet    YurtAppSetControllerConfiguration    `json:"yurtAppSet,omitempty"`
+	YurtAppDaemon YurtAppDaemonControllerConfiguration `json:"yurtAppDaemon,omitempty"`
+	NodePool      NodePoolControllerConfiguration      `json:"nodePool,omitempty"`
+	YurtIngress   YurtIngressControllerConfiguration   `json:"yurtIngress,omitempty"`
+}
+
+// ControllerConfiguration is the settings shared by all the controllers
+type ControllerConfiguration struct {
+	// Workers is the max concurrent reconciles of the controller.
+	Workers     int32                    `json:"workers,omitempty"`
+	RateLimiter RateLimiterConfiguration `json:"rateLimiter,omitempty"`
+}
+
+// RateLimiterConfiguration is the rate limiter of the work queue of a controller, which is the max of
+// a per-item exponential backoff from BaseDelay to MaxDelay and an overall token bucket of QPS and Burst.
+type RateLimiterConfiguration struct {
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+	MaxDelay  metav1.Duration `json:"maxDelay,omitempty"`
+	QPS       float32         `json:"qps,omitempty"`
+	Burst     int32           `json:"burst,omitempty"`
+}
+
+// YurtAppSetControllerConfiguration is the configuration of the YurtAppSet controller
+type YurtAppSetControllerConfiguration struct {
+	ControllerConfiguration `json:",inline"`
+	// WorkloadRegistry is the namespace/name of the ConfigMap which declares the custom workloads of YurtAppSet.
+	WorkloadRegistry string `json:"workloadRegistry,omitempty"`
+}
+
+// YurtAppDaemonControllerConfiguration is the configuration of the YurtAppDaemon controller
+type YurtAppDaemonControllerConfiguration struct {
+	ControllerConfiguration `json:",inline"`
+}
+
+// NodePoolControllerConfiguration is the configuration of the NodePool controller
+type NodePoolControllerConfiguration struct {
+	ControllerConfiguration `json:",inline"`
+	// CreateDefaultPool creates the default cloud/edge pools.
+	CreateDefaultPool bool `json:"createDefaultPool,omitempty"`
+}
+
+// YurtIngressControllerConfiguration is the configuration of the YurtIngress controller
+type YurtIngressControllerConfiguration struct {
+	ControllerConfiguration `json:",inline"`
+	// TemplateDir is the directory of the template sets overriding the built-in templates of the ingress controllers.
+	TemplateDir string `json:"templateDir,omitempty"`
+}
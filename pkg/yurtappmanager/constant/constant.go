@@ -17,6 +17,6 @@ limitations under the License.
 package constant
 
 const (
-	// ContextKeyCreateDefaultPool indicate whether creating the default nodepools
-	ContextKeyCreateDefaultPool = "CreateDefaultPool"
+	// ContextKeyControllersConfiguration indicate the configuration of the controllers
+	ContextKeyControllersConfiguration = "ControllersConfiguration"
 )
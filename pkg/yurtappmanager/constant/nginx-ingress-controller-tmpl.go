@@ -21,7 +21,7 @@ const (
 apiVersion: v1
 kind: Namespace
 metadata:
-  name: ingress-nginx
+  name: {{.namespace}}
   labels:
     app.kubernetes.io/name: ingress-nginx
     app.kubernetes.io/instance: ingress-nginx
@@ -34,7 +34,7 @@ metadata:
   labels:
     app.kubernetes.io/name: ingress-nginx
     app.kubernetes.io/instance: ingress-nginx
-  name: ingress-nginx
+  name: {{.name_prefix}}ingress-nginx
 rules:
   - apiGroups:
       - ''
@@ -97,7 +97,7 @@ rules:
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRole
 metadata:
-  name: ingress-nginx-admission
+  name: {{.name_prefix}}ingress-nginx-admission
   annotations:
     helm.sh/hook: pre-install,pre-upgrade,post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -124,7 +124,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
   name: ingress-nginx
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 automountServiceAccountToken: true
 `
 	NginxIngressControllerConfigMap = `
@@ -137,7 +137,22 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
   name: ingress-nginx-controller
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
+data:
+  allow-snippet-annotations: 'true'
+`
+	NginxIngressControllerPoolConfigMap = `
+# Source: ingress-nginx/templates/controller-configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/instance: ingress-nginx
+    app.kubernetes.io/component: controller
+    yurtingress.io/nodepool: {{.nodepool_name}}
+  name: {{.nodepool_name}}-ingress-nginx-controller
+  namespace: {{.namespace}}
 data:
   allow-snippet-annotations: 'true'
 `
@@ -149,15 +164,15 @@ metadata:
   labels:
     app.kubernetes.io/name: ingress-nginx
     app.kubernetes.io/instance: ingress-nginx
-  name: ingress-nginx
+  name: {{.name_prefix}}ingress-nginx
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: ClusterRole
-  name: ingress-nginx
+  name: {{.name_prefix}}ingress-nginx
 subjects:
   - kind: ServiceAccount
     name: ingress-nginx
-    namespace: ingress-nginx
+    namespace: {{.namespace}}
 `
 	NginxIngressControllerRole = `
 # Source: ingress-nginx/templates/controller-role.yaml
@@ -169,7 +184,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
   name: ingress-nginx
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 rules:
   - apiGroups:
       - ''
@@ -249,7 +264,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
   name: ingress-nginx
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: Role
@@ -257,7 +272,7 @@ roleRef:
 subjects:
   - kind: ServiceAccount
     name: ingress-nginx
-    namespace: ingress-nginx
+    namespace: {{.namespace}}
 `
 	NginxIngressAdmissionWebhookService = `
 # Source: ingress-nginx/templates/controller-service-webhook.yaml
@@ -269,7 +284,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx-webhook
     app.kubernetes.io/component: controller-webhook
   name: {{.nodepool_name}}-ingress-nginx-controller-admission
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 spec:
   type: ClusterIP
   ports:
@@ -293,7 +308,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
   name: {{.nodepool_name}}-ingress-nginx-controller
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 spec:
   type: NodePort
   ipFamilyPolicy: SingleStack
@@ -313,6 +328,20 @@ spec:
     app.kubernetes.io/instance: ingress-nginx
     app.kubernetes.io/component: controller
     yurtingress.io/nodepool: {{.nodepool_name}}
+`
+	NginxIngressControllerIngressClass = `
+# Source: ingress-nginx/templates/controller-ingressclass.yaml
+apiVersion: networking.k8s.io/v1
+kind: IngressClass
+metadata:
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/instance: ingress-nginx
+    app.kubernetes.io/component: controller
+    yurtingress.io/nodepool: {{.nodepool_name}}
+  name: {{.name_prefix}}{{.nodepool_name}}-nginx
+spec:
+  controller: k8s.io/ingress-nginx
 `
 	NginxIngressControllerNodePoolDeployment = `
 # Source: ingress-nginx/templates/controller-deployment.yaml
@@ -325,7 +354,7 @@ metadata:
     app.kubernetes.io/component: controller
     yurtingress.io/nodepool: {{.nodepool_name}}
   name: {{.nodepool_name}}-ingress-nginx-controller
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 spec:
   selector:
     matchLabels:
@@ -357,8 +386,8 @@ spec:
           args:
             - /nginx-ingress-controller
             - --election-id=ingress-controller-leader-edge
-            - --ingress-class={{.nodepool_name}}
-            - --configmap=$(POD_NAMESPACE)/ingress-nginx-controller
+            - --ingress-class={{.name_prefix}}{{.nodepool_name}}-nginx
+            - --configmap=$(POD_NAMESPACE)/{{.nodepool_name}}-ingress-nginx-controller
           securityContext:
             capabilities:
               drop:
@@ -430,7 +459,7 @@ metadata:
     app.kubernetes.io/instance: ingress-nginx-webhook
     app.kubernetes.io/component: controller-webhook
   name: {{.nodepool_name}}-ingress-nginx-admission-webhook
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
 spec:
   selector:
     matchLabels:
@@ -462,9 +491,9 @@ spec:
           args:
             - /nginx-ingress-controller
             - --election-id=ingress-controller-leader-webhook
-            - --ingress-class={{.nodepool_name}}
+            - --ingress-class={{.name_prefix}}{{.nodepool_name}}-nginx
             - --update-status=false
-            - --configmap=$(POD_NAMESPACE)/ingress-nginx-controller
+            - --configmap=$(POD_NAMESPACE)/{{.nodepool_name}}-ingress-nginx-controller
             - --validating-webhook=:8443
             - --validating-webhook-certificate=/usr/local/certificates/cert
             - --validating-webhook-key=/usr/local/certificates/key
@@ -538,7 +567,7 @@ spec:
       volumes:
         - name: webhook-cert
           secret:
-            secretName: {{.nodepool_name}}-ingress-nginx-admission
+            secretName: {{.name_prefix}}{{.nodepool_name}}-ingress-nginx-admission
 `
 	NginxIngressValidatingWebhookConfiguration = `
 # Source: ingress-nginx/templates/admission-webhooks/validating-webhook.yaml
@@ -551,7 +580,7 @@ metadata:
     app.kubernetes.io/name: ingress-nginx-webhook
     app.kubernetes.io/instance: ingress-nginx-webhook
     app.kubernetes.io/component: admission-webhook
-  name: {{.nodepool_name}}-ingress-nginx-admission
+  name: {{.name_prefix}}{{.nodepool_name}}-ingress-nginx-admission
 webhooks:
   - name: validate.nginx.ingress.kubernetes.io
     matchPolicy: Equivalent
@@ -571,7 +600,7 @@ webhooks:
       - v1
     clientConfig:
       service:
-        namespace: ingress-nginx
+        namespace: {{.namespace}}
         name: {{.nodepool_name}}-ingress-nginx-controller-admission
         path: /networking/v1/ingresses
 `
@@ -581,7 +610,7 @@ apiVersion: v1
 kind: ServiceAccount
 metadata:
   name: ingress-nginx-admission
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
   annotations:
     helm.sh/hook: pre-install,pre-upgrade,post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -595,7 +624,7 @@ metadata:
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRoleBinding
 metadata:
-  name: ingress-nginx-admission
+  name: {{.name_prefix}}ingress-nginx-admission
   annotations:
     helm.sh/hook: pre-install,pre-upgrade,post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -606,11 +635,11 @@ metadata:
 roleRef:
   apiGroup: rbac.authorization.k8s.io
   kind: ClusterRole
-  name: ingress-nginx-admission
+  name: {{.name_prefix}}ingress-nginx-admission
 subjects:
   - kind: ServiceAccount
     name: ingress-nginx-admission
-    namespace: ingress-nginx
+    namespace: {{.namespace}}
 `
 	NginxIngressAdmissionWebhookRole = `
 # Source: ingress-nginx/templates/admission-webhooks/job-patch/role.yaml
@@ -618,7 +647,7 @@ apiVersion: rbac.authorization.k8s.io/v1
 kind: Role
 metadata:
   name: ingress-nginx-admission
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
   annotations:
     helm.sh/hook: pre-install,pre-upgrade,post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -641,7 +670,7 @@ apiVersion: rbac.authorization.k8s.io/v1
 kind: RoleBinding
 metadata:
   name: ingress-nginx-admission
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
   annotations:
     helm.sh/hook: pre-install,pre-upgrade,post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -656,7 +685,7 @@ roleRef:
 subjects:
   - kind: ServiceAccount
     name: ingress-nginx-admission
-    namespace: ingress-nginx
+    namespace: {{.namespace}}
 `
 	NginxIngressAdmissionWebhookJob = `
 # Source: ingress-nginx/templates/admission-webhooks/job-patch/job-createSecret.yaml
@@ -664,7 +693,7 @@ apiVersion: batch/v1
 kind: Job
 metadata:
   name: {{.nodepool_name}}-ingress-nginx-admission-create
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
   annotations:
     helm.sh/hook: pre-install,pre-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -688,7 +717,7 @@ spec:
             - create
             - --host={{.nodepool_name}}-ingress-nginx-controller-admission,{{.nodepool_name}}-ingress-nginx-controller-admission.$(POD_NAMESPACE).svc
             - --namespace=$(POD_NAMESPACE)
-            - --secret-name={{.nodepool_name}}-ingress-nginx-admission
+            - --secret-name={{.name_prefix}}{{.nodepool_name}}-ingress-nginx-admission
           env:
             - name: POD_NAMESPACE
               valueFrom:
@@ -716,7 +745,7 @@ apiVersion: batch/v1
 kind: Job
 metadata:
   name: {{.nodepool_name}}-ingress-nginx-admission-patch
-  namespace: ingress-nginx
+  namespace: {{.namespace}}
   annotations:
     helm.sh/hook: post-install,post-upgrade
     helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
@@ -738,10 +767,10 @@ spec:
           imagePullPolicy: IfNotPresent
           args:
             - patch
-            - --webhook-name={{.nodepool_name}}-ingress-nginx-admission
+            - --webhook-name={{.name_prefix}}{{.nodepool_name}}-ingress-nginx-admission
             - --namespace=$(POD_NAMESPACE)
             - --patch-mutating=false
-            - --secret-name={{.nodepool_name}}-ingress-nginx-admission
+            - --secret-name={{.name_prefix}}{{.nodepool_name}}-ingress-nginx-admission
             - --patch-failure-policy=Fail
           env:
             - name: POD_NAMESPACE
@@ -6,7 +6,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -14,37 +14,50 @@ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 See the License for the specific language governing permissions and
 limitations under the License.
 */
-
 package controller
 
 import (
 	"context"
 
-	"k8s.io/apimachinery/pkg/api/meta"
-	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/nodepool"
 	yurtappdaemon "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappdaemon"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress"
 )
 
-var controllerAddFuncs []func(manager.Manager, context.Context) error
+// controllerInfo is a controller of a custom resource, it is added to the manager by its add func
+// once the CRD of the resource is installed and the resource is enabled
+type controllerInfo struct {
+	kind     string
+	resource client.Object
+	add      func(manager.Manager, context.Context) error
+}
+
+var controllerInfos []controllerInfo
 
 func init() {
-	controllerAddFuncs = append(controllerAddFuncs, yurtappset.Add, nodepool.Add, yurtappdaemon.Add, yurtingress.Add)
+	controllerInfos = append(controllerInfos,
+		controllerInfo{kind: "YurtAppSet", resource: &appsv1alpha1.YurtAppSet{}, add: yurtappset.Add},
+		controllerInfo{kind: "NodePool", resource: &appsv1alpha1.NodePool{}, add: nodepool.Add},
+		controllerInfo{kind: "YurtAppDaemon", resource: &appsv1alpha1.YurtAppDaemon{}, add: yurtappdaemon.Add},
+		controllerInfo{kind: "YurtIngress", resource: &appsv1alpha1.YurtIngress{}, add: yurtingress.Add},
+	)
 }
 
+// SetupWithManager adds the controllers of the installed and enabled custom resources to the manager, and adds
+// the controllers of the others once their CRDs are installed or they are enabled by the configuration after startup.
+// The states of the controllers are served at /controllers of the metrics server.
 func SetupWithManager(m manager.Manager, ctx context.Context) error {
-	for _, f := range controllerAddFuncs {
-		if err := f(m, ctx); err != nil {
-			if kindMatchErr, ok := err.(*meta.NoKindMatchError); ok {
-				klog.Infof("CRD %v is not installed, its controller will perform noops!", kindMatchErr.GroupKind)
-				continue
-			}
-			return err
-		}
+	s := newControllerSet(m, ctx, controllerInfos)
+	if err := s.sync(); err != nil {
+		return err
+	}
+	if err := m.AddMetricsExtraHandler(statusPath, s); err != nil {
+		return err
 	}
-	return nil
+	return m.Add(s)
 }
@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
+)
+
+const (
+	// statusPath is the path of the states of the controllers on the metrics server
+	statusPath = "/controllers"
+	// resyncPeriod is the period to check the custom resources again, which picks up the CRDs not yet
+	// served when they are created and the resources enabled by the configuration
+	resyncPeriod = 30 * time.Second
+	// maxRetryBackoff is the maximum backoff to add a failed controller again, which starts from resyncPeriod
+	// and doubles on every failure
+	maxRetryBackoff = 10 * time.Minute
+)
+
+// ControllerState is the state of the controller of a custom resource
+type ControllerState string
+
+const (
+	// ControllerEnabled is the state of the controller added to the manager, which runs on the leader
+	ControllerEnabled ControllerState = "Enabled"
+	// ControllerDisabled is the state of the controller of the resource disabled by the configuration,
+	// it skips the objects of the resource if it was added before
+	ControllerDisabled ControllerState = "Disabled"
+	// ControllerNotInstalled is the state of the controller whose CRD is not installed
+	ControllerNotInstalled ControllerState = "NotInstalled"
+	// ControllerFailed is the state of the controller which failed to be added to the manager
+	ControllerFailed ControllerState = "Failed"
+)
+
+// ControllerStatus is the status of the controller of a custom resource served on the status endpoint
+type ControllerStatus struct {
+	Kind    string          `json:"kind"`
+	State   ControllerState `json:"state"`
+	Message string          `json:"message,omitempty"`
+}
+
+// lazyController is a controller added to the manager once its custom resource is installed and enabled
+type lazyController struct {
+	controllerInfo
+	added  bool
+	status ControllerStatus
+	// failures is the number of the consecutive failures to add the controller, which is added again after retryTime
+	failures  int
+	retryTime time.Time
+}
+
+// retryBackoff returns the backoff to add the controller again after the failures.
+func retryBackoff(failures int) time.Duration {
+	backoff := resyncPeriod
+	for i := 1; i < failures && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
+// controllerSet adds the controllers of the custom resources lazily, it watches the CRDs of the resources
+// and checks the resources periodically.
+type controllerSet struct {
+	mgr manager.Manager
+	ctx context.Context
+
+	mu          sync.Mutex
+	controllers []*lazyController
+	trigger     chan struct{}
+}
+
+func newControllerSet(mgr manager.Manager, ctx context.Context, infos []controllerInfo) *controllerSet {
+	s := &controllerSet{mgr: mgr, ctx: ctx, trigger: make(chan struct{}, 1)}
+	for _, info := range infos {
+		s.controllers = append(s.controllers, &lazyController{controllerInfo: info, status: ControllerStatus{Kind: info.kind}})
+	}
+	return s
+}
+
+// sync adds the controllers of the installed and enabled resources which are not added yet, and updates the
+// states of all the controllers. It returns the error of adding a controller, the failed controller is added again
+// by the syncs after its backoff.
+func (s *controllerSet) sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var errs []error
+	for _, c := range s.controllers {
+		status := ControllerStatus{Kind: c.kind}
+		switch {
+		case c.status.State == ControllerFailed && now.Before(c.retryTime):
+			continue
+		case !gate.ConfigEnabled(c.resource):
+			status.State = ControllerDisabled
+			if c.added {
+				status.Message = "the added controller skips the objects"
+			}
+		case c.added:
+			status.State = ControllerEnabled
+		case !gate.ResourceEnabled(c.resource):
+			status.State = ControllerNotInstalled
+		default:
+			err := c.add(s.mgr, s.ctx)
+			if meta.IsNoMatchError(err) {
+				// the CRD is not served by the apiserver yet
+				status.State, status.Message = ControllerNotInstalled, err.Error()
+				break
+			}
+			if err != nil {
+				c.failures++
+				backoff := retryBackoff(c.failures)
+				c.retryTime = now.Add(backoff)
+				status.State, status.Message = ControllerFailed, err.Error()
+				errs = append(errs, fmt.Errorf("fail to add the controller of %s, retry after %v: %v", c.kind, backoff, err))
+				break
+			}
+			c.added, c.failures = true, 0
+			status.State = ControllerEnabled
+		}
+
+		if status.State != c.status.State {
+			klog.Infof("controller of %s is %s: %s", c.kind, status.State, status.Message)
+		}
+		c.status = status
+	}
+
+	if len(errs) != 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
+
+// Start watches the CRDs of the custom resources and syncs the controllers until ctx is done.
+func (s *controllerSet) Start(ctx context.Context) error {
+	crd := &metav1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	informer, err := s.mgr.GetCache().GetInformer(ctx, crd)
+	if err != nil {
+		return fmt.Errorf("fail to watch CustomResourceDefinitions: %v", err)
+	}
+	informer.AddEventHandler(toolscache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			o, ok := obj.(metav1.Object)
+			return ok && strings.HasSuffix(o.GetName(), "."+appsv1alpha1.GroupVersion.Group)
+		},
+		Handler: toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { s.enqueue() },
+			UpdateFunc: func(interface{}, interface{}) { s.enqueue() },
+			DeleteFunc: func(interface{}) { s.enqueue() },
+		},
+	})
+
+	ticker := time.NewTicker(resyncPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.trigger:
+		case <-ticker.C:
+		}
+		if err := s.sync(); err != nil {
+			klog.Errorf("fail to sync the controllers: %v", err)
+		}
+	}
+}
+
+// NeedLeaderElection is false, the controllers are added on every replica and started on the leader.
+func (s *controllerSet) NeedLeaderElection() bool {
+	return false
+}
+
+// enqueue triggers a sync, which is coalesced with the pending one
+func (s *controllerSet) enqueue() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Statuses returns the statuses of the controllers.
+func (s *controllerSet) Statuses() []ControllerStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]ControllerStatus, 0, len(s.controllers))
+	for _, c := range s.controllers {
+		statuses = append(statuses, c.status)
+	}
+	return statuses
+}
+
+// ServeHTTP serves the statuses of the controllers in json.
+func (s *controllerSet) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.Statuses()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
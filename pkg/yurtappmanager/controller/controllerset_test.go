@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
+)
+
+func TestControllerSetSync(t *testing.T) {
+	defer gate.SetEnabledResources(nil)
+
+	adds := map[string]int{}
+	var ingressErr error = &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: appsv1alpha1.GroupVersion.Group, Kind: "YurtIngress"}}
+	addFunc := func(kind string, err *error) func(manager.Manager, context.Context) error {
+		return func(manager.Manager, context.Context) error {
+			adds[kind]++
+			if err != nil {
+				return *err
+			}
+			return nil
+		}
+	}
+	daemonErr := errors.New("fail to watch")
+	s := newControllerSet(nil, context.TODO(), []controllerInfo{
+		{kind: "YurtAppSet", resource: &appsv1alpha1.YurtAppSet{}, add: addFunc("YurtAppSet", nil)},
+		{kind: "NodePool", resource: &appsv1alpha1.NodePool{}, add: addFunc("NodePool", nil)},
+		{kind: "YurtAppDaemon", resource: &appsv1alpha1.YurtAppDaemon{}, add: addFunc("YurtAppDaemon", &daemonErr)},
+		{kind: "YurtIngress", resource: &appsv1alpha1.YurtIngress{}, add: addFunc("YurtIngress", &ingressErr)},
+	})
+
+	gate.SetEnabledResources([]string{"YurtAppSet", "YurtAppDaemon", "YurtIngress"})
+	if err := s.sync(); err == nil {
+		t.Errorf("expect the error of adding the YurtAppDaemon controller")
+	}
+	expectStates(t, s, map[string]ControllerState{
+		"YurtAppSet":    ControllerEnabled,
+		"NodePool":      ControllerDisabled,
+		"YurtAppDaemon": ControllerFailed,
+		"YurtIngress":   ControllerNotInstalled,
+	})
+
+	// the CRD of YurtIngress is installed and NodePool is enabled after startup
+	ingressErr = nil
+	gate.SetEnabledResources(nil)
+	if err := s.sync(); err != nil {
+		t.Errorf("failed to sync the controllers: %v", err)
+	}
+	expectStates(t, s, map[string]ControllerState{
+		"YurtAppSet":    ControllerEnabled,
+		"NodePool":      ControllerEnabled,
+		"YurtAppDaemon": ControllerFailed,
+		"YurtIngress":   ControllerEnabled,
+	})
+
+	// the added controllers are not added again even if they are disabled and enabled again
+	gate.SetEnabledResources([]string{"NodePool"})
+	if err := s.sync(); err != nil {
+		t.Errorf("failed to sync the controllers: %v", err)
+	}
+	expectStates(t, s, map[string]ControllerState{"YurtAppSet": ControllerDisabled, "YurtIngress": ControllerDisabled})
+	gate.SetEnabledResources(nil)
+	if err := s.sync(); err != nil {
+		t.Errorf("failed to sync the controllers: %v", err)
+	}
+
+	expectedAdds := map[string]int{"YurtAppSet": 1, "NodePool": 1, "YurtAppDaemon": 1, "YurtIngress": 2}
+	for kind, n := range expectedAdds {
+		if adds[kind] != n {
+			t.Errorf("expect the controller of %s to be added %d times, got %d", kind, n, adds[kind])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", statusPath, nil))
+	var statuses []ControllerStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("failed to decode the statuses: %v", err)
+	}
+	if len(statuses) != 4 || statuses[2].Kind != "YurtAppDaemon" || statuses[2].State != ControllerFailed || statuses[2].Message != "fail to watch" {
+		t.Errorf("unexpected statuses %+v", statuses)
+	}
+
+	// the failed controller is added again after its backoff, which doubles on every failure
+	daemon := s.controllers[2]
+	daemon.retryTime = time.Now()
+	if err := s.sync(); err == nil {
+		t.Errorf("expect the error of adding the YurtAppDaemon controller again")
+	}
+	if daemon.failures != 2 || time.Until(daemon.retryTime) <= resyncPeriod {
+		t.Errorf("expect the backoff doubled after 2 failures, got retry time %v", daemon.retryTime)
+	}
+	daemonErr = nil
+	daemon.retryTime = time.Now()
+	if err := s.sync(); err != nil {
+		t.Errorf("failed to sync the controllers: %v", err)
+	}
+	expectStates(t, s, map[string]ControllerState{"YurtAppDaemon": ControllerEnabled})
+	if adds["YurtAppDaemon"] != 3 || daemon.failures != 0 {
+		t.Errorf("expect the controller of YurtAppDaemon to be added 3 times, got %d", adds["YurtAppDaemon"])
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	expected := map[int]time.Duration{1: resyncPeriod, 2: 2 * resyncPeriod, 3: 4 * resyncPeriod, 100: maxRetryBackoff}
+	for failures, backoff := range expected {
+		if got := retryBackoff(failures); got != backoff {
+			t.Errorf("expect the backoff after %d failures to be %v, got %v", failures, backoff, got)
+		}
+	}
+}
+
+func expectStates(t *testing.T, s *controllerSet, states map[string]ControllerState) {
+	t.Helper()
+	for _, status := range s.Statuses() {
+		if state, ok := states[status.Kind]; ok && status.State != state {
+			t.Errorf("expect the controller of %s to be %s, got %s", status.Kind, state, status.State)
+		}
+	}
+}
@@ -42,14 +42,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
-	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/metrics"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 )
 
 const controllerName = "nodepool-controller"
 
-var concurrentReconciles = 3
-
 // NodePoolReconciler reconciles a NodePool object
 type NodePoolReconciler struct {
 	client.Client
@@ -68,16 +68,10 @@ type NodePoolRelatedAttributes struct {
 // Add creates a new NodePool Controller and adds it to the Manager with default RBAC.
 // The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
+// It is called once the CRD of NodePool is installed and NodePool is enabled.
 func Add(mgr manager.Manager, ctx context.Context) error {
-	if !gate.ResourceEnabled(&appsv1alpha1.NodePool{}) {
-		return nil
-	}
-	inf := ctx.Value(constant.ContextKeyCreateDefaultPool)
-	cdp, ok := inf.(bool)
-	if !ok {
-		return errors.New("fail to assert interface to bool for command line option createDefaultPool")
-	}
-	return add(mgr, newReconciler(mgr, cdp))
+	c := util.ControllersConfiguration(ctx).NodePool
+	return add(mgr, newReconciler(mgr, c.CreateDefaultPool), c.ControllerConfiguration)
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -91,12 +85,9 @@ func newReconciler(mgr manager.Manager, createDefaultPool bool) reconcile.Reconc
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, cfg configv1alpha1.ControllerConfiguration) error {
 	// Create a new controller
-	c, err := controller.New(controllerName,
-		mgr, controller.Options{
-			Reconciler:              r,
-			MaxConcurrentReconciles: concurrentReconciles})
+	c, err := controller.New(controllerName, mgr, util.ControllerOptions(gate.NewReconciler(&appsv1alpha1.NodePool{}, r), cfg))
 	if err != nil {
 		return err
 	}
@@ -174,6 +165,9 @@ func (r *NodePoolReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctr
 	var nodePool appsv1alpha1.NodePool
 	// try to reconcile the NodePool object
 	if err := r.Get(ctx, req.NamespacedName, &nodePool); err != nil {
+		if apierrors.IsNotFound(err) {
+			metrics.DeleteNodePool(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -210,9 +204,11 @@ func (r *NodePoolReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctr
 
 	for _, rNode := range removedNodes {
 		if err := removePoolRelatedAttrs(&rNode); err != nil {
+			metrics.IncNodePoolAttributeSyncFailures(nodePool.GetName())
 			return ctrl.Result{}, err
 		}
 		if err := r.Update(ctx, &rNode); err != nil {
+			metrics.IncNodePoolAttributeSyncFailures(nodePool.GetName())
 			return ctrl.Result{}, err
 		}
 	}
@@ -240,6 +236,7 @@ func (r *NodePoolReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctr
 				Taints:      nodePool.Spec.Taints,
 			})
 		if err != nil {
+			metrics.IncNodePoolAttributeSyncFailures(nodePool.GetName())
 			return ctrl.Result{}, err
 		}
 		var ownerLabelUpdated bool
@@ -254,12 +251,14 @@ func (r *NodePoolReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctr
 		if attrUpdated || ownerLabelUpdated {
 			if err := r.Update(ctx, &node); err != nil {
 				klog.Errorf("Update Node %s error %v", node.Name, err)
+				metrics.IncNodePoolAttributeSyncFailures(nodePool.GetName())
 				return ctrl.Result{}, err
 			}
 		}
 	}
 
 	// 3. always update the node pool status if necessary
+	metrics.SetNodePoolNodes(nodePool.GetName(), readyNode, notReadyNode)
 	return conciliateNodePoolStatus(r.Client, readyNode, notReadyNode, nodes, &nodePool)
 }
 
@@ -25,6 +25,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/controller/history"
@@ -274,6 +275,8 @@ func getYurtAppDaemonPatch(ud *appsalphav1.YurtAppDaemon) ([]byte, error) {
 	// Create a patch of the YurtAppDaemon that replaces spec.template
 	spec := raw["spec"].(map[string]interface{})
 	template := spec["workloadTemplate"].(map[string]interface{})
+	// pausing or resuming the Deployments does not change the revision, so the pods are not restarted
+	unstructured.RemoveNestedField(template, "deploymentTemplate", "spec", "paused")
 	specCopy["workloadTemplate"] = template
 	template["$patch"] = "replace"
 	objCopy["spec"] = specCopy
@@ -29,4 +29,6 @@ type WorkloadControllor interface {
 	UpdateWorkload(load *Workload, set *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) error
 	DeleteWorkload(set *v1alpha1.YurtAppDaemon, load *Workload) error
 	GetTemplateType() v1alpha1.TemplateType
+	// RenderWorkload renders the workload of the nodepool without writing it. The load is nil if it is to be created.
+	RenderWorkload(load *Workload, set *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool, revision string) (client.Object, error)
 }
@@ -150,6 +150,20 @@ func (d *DeploymentControllor) CreateWorkload(yad *v1alpha1.YurtAppDaemon, nodep
 	return d.Client.Create(context.TODO(), &deploy)
 }
 
+// RenderWorkload renders the Deployment of the nodepool to the latest revision without writing it.
+func (d *DeploymentControllor) RenderWorkload(load *Workload, yad *v1alpha1.YurtAppDaemon, nodepool v1alpha1.NodePool,
+	revision string) (client.Object, error) {
+
+	deploy := &appsv1.Deployment{}
+	if load != nil {
+		deploy = load.Spec.Ref.(*appsv1.Deployment).DeepCopy()
+	}
+	if err := d.applyTemplate(d.Scheme, yad, nodepool, revision, deploy); err != nil {
+		return nil, err
+	}
+	return deploy, nil
+}
+
 func (d *DeploymentControllor) GetAllWorkloads(set *v1alpha1.YurtAppDaemon) ([]*Workload, error) {
 	allDeployments := appsv1.DeploymentList{}
 	// 获得 YurtAppDaemon 对应的 所有Deployment, 根据OwnerRef
@@ -192,6 +206,13 @@ func (d *DeploymentControllor) GetAllWorkloads(set *v1alpha1.YurtAppDaemon) ([]*
 				NodeSelector: spec.Template.Spec.NodeSelector,
 				Toleration:   spec.Template.Spec.Tolerations,
 			},
+			Status: WorkloadStatus{
+				ReadyReplicas:   deploy.Status.ReadyReplicas,
+				UpdatedReplicas: deploy.Status.UpdatedReplicas,
+			},
+		}
+		if spec.Replicas != nil {
+			w.Status.Replicas = *spec.Replicas
 		}
 		workloads = append(workloads, w)
 	}
@@ -40,6 +40,9 @@ type WorkloadSpec struct {
 
 // WorkloadStatus stores the observed state of the Workload.
 type WorkloadStatus struct {
+	Replicas        int32
+	ReadyReplicas   int32
+	UpdatedReplicas int32
 }
 
 func (w *Workload) GetRevision() string {
@@ -18,7 +18,6 @@ package yurtappdaemon
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"reflect"
 
@@ -38,17 +37,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappdaemon/workloadcontroller"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/metrics"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 )
 
-var (
-	concurrentReconciles = 3
-)
-
 const (
 	controllerName            = "yurtappdaemon-controller"
+	metricsKind               = "YurtAppDaemon"
 	slowStartInitialBatchSize = 1
 
 	eventTypeRevisionProvision  = "RevisionProvision"
@@ -59,24 +57,18 @@ const (
 	eventTypeWorkloadsDeleted = "DeleteWorkload"
 )
 
-func init() {
-	flag.IntVar(&concurrentReconciles, "yurtappdaemon-workers", concurrentReconciles, "Max concurrent workers for YurtAppDaemon controller.")
-}
-
 // Add creates a new YurtAppDaemon Controller and adds it to the Manager with default RBAC.
 // The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(mgr manager.Manager, _ context.Context) error {
-	if !gate.ResourceEnabled(&unitv1alpha1.YurtAppDaemon{}) {
-		return nil
-	}
-	return add(mgr, newReconciler(mgr))
+// It is called once the CRD of YurtAppDaemon is installed and YurtAppDaemon is enabled.
+func Add(mgr manager.Manager, ctx context.Context) error {
+	return add(mgr, newReconciler(mgr), util.ControllersConfiguration(ctx).YurtAppDaemon.ControllerConfiguration)
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, cfg configv1alpha1.ControllerConfiguration) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	c, err := controller.New(controllerName, mgr, util.ControllerOptions(gate.NewReconciler(&unitv1alpha1.YurtAppDaemon{}, r), cfg))
 	if err != nil {
 		return err
 	}
@@ -113,10 +105,14 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		scheme: mgr.GetScheme(),
 
 		recorder: mgr.GetEventRecorderFor(controllerName),
-		controls: map[unitv1alpha1.TemplateType]workloadcontroller.WorkloadControllor{
-			//			unitv1alpha1.StatefulSetTemplateType: &StatefulSetControllor{Client: mgr.GetClient(), scheme: mgr.GetScheme()},
-			unitv1alpha1.DeploymentTemplateType: &workloadcontroller.DeploymentControllor{Client: mgr.GetClient(), Scheme: mgr.GetScheme()},
-		},
+		controls: newWorkloadControls(mgr.GetClient(), mgr.GetScheme()),
+	}
+}
+
+func newWorkloadControls(c client.Client, scheme *runtime.Scheme) map[unitv1alpha1.TemplateType]workloadcontroller.WorkloadControllor {
+	return map[unitv1alpha1.TemplateType]workloadcontroller.WorkloadControllor{
+		//			unitv1alpha1.StatefulSetTemplateType: &StatefulSetControllor{Client: c, scheme: scheme},
+		unitv1alpha1.DeploymentTemplateType: &workloadcontroller.DeploymentControllor{Client: c, Scheme: scheme},
 	}
 }
 
@@ -132,6 +128,7 @@ func (r *ReconcileYurtAppDaemon) Reconcile(_ context.Context, request reconcile.
 	err := r.Get(context.TODO(), request.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			metrics.DeleteWorkload(metricsKind, request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -181,6 +178,8 @@ func (r *ReconcileYurtAppDaemon) Reconcile(_ context.Context, request reconcile.
 		return reconcile.Result{}, err
 	}
 
+	recordMetrics(instance, currentNPToWorkload, expectedRevision.Name)
+
 	newStatus, err := r.manageWorkloads(instance, currentNPToWorkload, allNameToNodePools, expectedRevision.Name, templateType)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -189,6 +188,26 @@ func (r *ReconcileYurtAppDaemon) Reconcile(_ context.Context, request reconcile.
 	return r.updateStatus(instance, newStatus, oldStatus, currentRevision, collisionCount, templateType)
 }
 
+// recordMetrics records the replicas of the workloads in the nodepools and the rollout of the expected revision of the
+// YurtAppDaemon.
+func recordMetrics(instance *unitv1alpha1.YurtAppDaemon, nodepoolToWorkload map[string]*workloadcontroller.Workload,
+	expectedRevision string) {
+	rolledOut := true
+	pools := make(map[string]metrics.PoolReplicas, len(nodepoolToWorkload))
+	for np, w := range nodepoolToWorkload {
+		replicas := metrics.PoolReplicas{Desired: w.Status.Replicas, Ready: w.Status.ReadyReplicas}
+		if w.GetRevision() == expectedRevision {
+			replicas.Updated = w.Status.UpdatedReplicas
+		}
+		if replicas.Updated != replicas.Desired || replicas.Ready != replicas.Desired {
+			rolledOut = false
+		}
+		pools[np] = replicas
+	}
+	metrics.SetWorkloadReplicas(metricsKind, instance.Namespace, instance.Name, pools)
+	metrics.SetWorkloadRevision(metricsKind, instance.Namespace, instance.Name, expectedRevision, rolledOut)
+}
+
 func (r *ReconcileYurtAppDaemon) updateStatus(instance *unitv1alpha1.YurtAppDaemon, newStatus, oldStatus *unitv1alpha1.YurtAppDaemonStatus,
 	currentRevision *appsv1.ControllerRevision, collisionCount int32, templateType unitv1alpha1.TemplateType) (reconcile.Result, error) {
 
@@ -370,6 +389,10 @@ func (r *ReconcileYurtAppDaemon) classifyWorkloads(instance *unitv1alpha1.YurtAp
 			if load.GetRevision() != expectedRevision {
 				match = false
 			}
+			// judge paused, which is not a part of the revisions
+			if isPausedChanged(instance, load) {
+				match = false
+			}
 
 			if !match {
 				klog.V(4).Infof("YurtAppDaemon[%s/%s] need update [%s/%s/%s]", instance.GetNamespace(),
@@ -394,6 +417,15 @@ func (r *ReconcileYurtAppDaemon) classifyWorkloads(instance *unitv1alpha1.YurtAp
 	return
 }
 
+// isPausedChanged returns whether the Deployment of the workload is to be paused or resumed.
+func isPausedChanged(instance *unitv1alpha1.YurtAppDaemon, load *workloadcontroller.Workload) bool {
+	deploy, ok := load.Spec.Ref.(*appsv1.Deployment)
+	if !ok || instance.Spec.WorkloadTemplate.DeploymentTemplate == nil {
+		return false
+	}
+	return deploy.Spec.Paused != instance.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Paused
+}
+
 func (r *ReconcileYurtAppDaemon) getNameToNodePools(instance *unitv1alpha1.YurtAppDaemon) (map[string]unitv1alpha1.NodePool, error) {
 	klog.V(4).Infof("YurtAppDaemon [%s/%s] prepare to get associated nodepools",
 		instance.Namespace, instance.Name)
@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappdaemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappdaemon/workloadcontroller"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/preview"
+)
+
+// previewUID is the uid of the YurtAppDaemon which does not exist yet, so that the owner references
+// of its workloads are valid in the dry-run requests.
+const previewUID = types.UID("00000000-0000-0000-0000-000000000000")
+
+// Preview renders the workload of every selected nodepool of the YurtAppDaemon against the current workloads
+// in the cluster, and returns what would change. Nothing is written to the cluster: every write is sent in
+// dry-run mode, so that the rendered workloads are defaulted by the apiserver before they are compared.
+func Preview(c client.Client, scheme *runtime.Scheme, yad *unitv1alpha1.YurtAppDaemon) ([]preview.WorkloadPreview, error) {
+	dryRunClient := client.NewDryRunClient(c)
+	yad = yad.DeepCopy()
+	unitv1alpha1.SetDefaultsYurtAppDaemon(yad)
+
+	live := &unitv1alpha1.YurtAppDaemon{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: yad.Namespace, Name: yad.Name}, live)
+	switch {
+	case err == nil:
+		yad.UID = live.UID
+		yad.ResourceVersion = live.ResourceVersion
+		yad.Status = live.Status
+	case errors.IsNotFound(err):
+		yad.UID = previewUID
+	default:
+		return nil, err
+	}
+
+	r := &ReconcileYurtAppDaemon{
+		Client:   dryRunClient,
+		scheme:   scheme,
+		controls: newWorkloadControls(dryRunClient, scheme),
+	}
+	currentRevision, updatedRevision, _, err := r.constructYurtAppDaemonRevisions(yad)
+	if err != nil {
+		return nil, err
+	}
+	expectedRevision := currentRevision
+	if updatedRevision != nil {
+		expectedRevision = updatedRevision
+	}
+
+	control, templateType, err := r.getTemplateControls(yad)
+	if err != nil {
+		return nil, err
+	}
+	if control == nil {
+		return nil, fmt.Errorf("template type %s is not supported", templateType)
+	}
+
+	currentNPToWorkload, err := r.getNodePoolToWorkLoad(yad, control)
+	if err != nil {
+		return nil, err
+	}
+	allNameToNodePools, err := r.getNameToNodePools(yad)
+	if err != nil {
+		return nil, err
+	}
+
+	var previews []preview.WorkloadPreview
+	for name, nodepool := range allNameToNodePools {
+		previews = append(previews, r.previewWorkload(control, templateType, currentNPToWorkload[name], yad,
+			nodepool, expectedRevision.Name))
+	}
+	for name, load := range currentNPToWorkload {
+		if _, ok := allNameToNodePools[name]; !ok {
+			previews = append(previews, preview.WorkloadPreview{
+				Pool:   name,
+				Kind:   string(templateType),
+				Name:   load.Name,
+				Action: preview.ActionDelete,
+			})
+		}
+	}
+	sort.Slice(previews, func(i, j int) bool {
+		return previews[i].Pool < previews[j].Pool
+	})
+	return previews, nil
+}
+
+// previewWorkload renders the workload of the nodepool and sends it to the apiserver in dry-run mode.
+func (r *ReconcileYurtAppDaemon) previewWorkload(control workloadcontroller.WorkloadControllor,
+	templateType unitv1alpha1.TemplateType, load *workloadcontroller.Workload, yad *unitv1alpha1.YurtAppDaemon,
+	nodepool unitv1alpha1.NodePool, revision string) preview.WorkloadPreview {
+
+	p := preview.WorkloadPreview{Pool: nodepool.Name, Kind: string(templateType), Action: preview.ActionCreate}
+	rendered, err := control.RenderWorkload(load, yad, nodepool, revision)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	var current runtime.Object
+	if load == nil {
+		err = r.Client.Create(context.TODO(), rendered)
+	} else {
+		p.Action = preview.ActionUpdate
+		current = load.Spec.Ref.(runtime.Object)
+		err = r.Client.Update(context.TODO(), rendered)
+	}
+	p.Name = rendered.GetName()
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	if p.Changes, err = preview.Diff(current, rendered); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if load != nil && len(p.Changes) == 0 {
+		p.Action = preview.ActionUnchanged
+	}
+	return p
+}
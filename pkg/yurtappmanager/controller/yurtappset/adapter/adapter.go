@@ -48,6 +48,7 @@ type Adapter interface {
 }
 
 type ReplicasInfo struct {
-	Replicas      int32
-	ReadyReplicas int32
+	Replicas        int32
+	ReadyReplicas   int32
+	UpdatedReplicas int32
 }
@@ -168,3 +168,39 @@ func TestCreateNewPatchedObject(t *testing.T) {
 		})
 	}
 }
+
+// newTestYurtAppSet returns the YurtAppSet default/foo selecting the pods labeled app=foo, which deploys the
+// workload template into the pool hangzhou. The tests override the fields they care about.
+func newTestYurtAppSet(template unitv1alpha1.WorkloadTemplate) *unitv1alpha1.YurtAppSet {
+	replicas := int32(2)
+	return &unitv1alpha1.YurtAppSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: unitv1alpha1.GroupVersion.String(),
+			Kind:       "YurtAppSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+			UID:       "uid",
+		},
+		Spec: unitv1alpha1.YurtAppSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "foo"},
+			},
+			WorkloadTemplate: template,
+			Topology: unitv1alpha1.Topology{
+				Pools: []unitv1alpha1.Pool{{
+					Name:     "hangzhou",
+					Replicas: &replicas,
+					NodeSelectorTerm: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      unitv1alpha1.LabelCurrentNodePool,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{"hangzhou"},
+						}},
+					},
+				}},
+			},
+		},
+	}
+}
@@ -61,8 +61,9 @@ func (a *DeploymentAdapter) GetDetails(obj metav1.Object) (ReplicasInfo, error)
 		specReplicas = *set.Spec.Replicas
 	}
 	replicasInfo := ReplicasInfo{
-		Replicas:      specReplicas,
-		ReadyReplicas: set.Status.ReadyReplicas,
+		Replicas:        specReplicas,
+		ReadyReplicas:   set.Status.ReadyReplicas,
+		UpdatedReplicas: set.Status.UpdatedReplicas,
 	}
 	return replicasInfo, nil
 }
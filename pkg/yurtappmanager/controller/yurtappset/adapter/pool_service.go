@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1 "k8s.io/kubernetes/pkg/apis/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
+)
+
+// ApplyPoolServiceTemplate renders the ServiceTemplate of the YurtAppSet into the Service of the pool.
+// The selector of the Service is narrowed to the pods of the pool by the pool name label.
+func ApplyPoolServiceTemplate(yas *alpha1.YurtAppSet, poolName string, svc *corev1.Service, scheme *runtime.Scheme) error {
+	if yas.Spec.ServiceTemplate == nil {
+		return fmt.Errorf("YurtAppSet %s/%s has no service template", yas.Namespace, yas.Name)
+	}
+	template := yas.Spec.ServiceTemplate
+
+	svc.Namespace = yas.Namespace
+	svc.Name = util.GetPoolServiceName(yas.Name, poolName)
+
+	if svc.Labels == nil {
+		svc.Labels = map[string]string{}
+	}
+	for k, v := range template.Labels {
+		svc.Labels[k] = v
+	}
+	for k, v := range yas.Spec.Selector.MatchLabels {
+		svc.Labels[k] = v
+	}
+	svc.Labels[alpha1.PoolNameLabelKey] = poolName
+
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	for k, v := range template.Annotations {
+		svc.Annotations[k] = v
+	}
+
+	existing := svc.Spec.DeepCopy()
+	svc.Spec = *template.Spec.DeepCopy()
+	svc.Spec.Selector = map[string]string{}
+	for k, v := range template.Spec.Selector {
+		svc.Spec.Selector[k] = v
+	}
+	for k, v := range yas.Spec.Selector.MatchLabels {
+		svc.Spec.Selector[k] = v
+	}
+	svc.Spec.Selector[alpha1.PoolNameLabelKey] = poolName
+	// default the rendered Service like the apiserver does, so that it can be compared with the existing one
+	v1.SetObjectDefaults_Service(svc)
+	keepAllocatedServiceFields(existing, &svc.Spec)
+
+	return controllerutil.SetControllerReference(yas, svc, scheme)
+}
+
+// keepAllocatedServiceFields copies the fields allocated by the apiserver from the existing Service spec,
+// they are not allowed to be changed once they have been set.
+func keepAllocatedServiceFields(existing, spec *corev1.ServiceSpec) {
+	if spec.ClusterIP == "" {
+		spec.ClusterIP = existing.ClusterIP
+		spec.ClusterIPs = existing.ClusterIPs
+	}
+	if len(spec.IPFamilies) == 0 {
+		spec.IPFamilies = existing.IPFamilies
+	}
+	if spec.IPFamilyPolicy == nil {
+		spec.IPFamilyPolicy = existing.IPFamilyPolicy
+	}
+	if spec.HealthCheckNodePort == 0 {
+		spec.HealthCheckNodePort = existing.HealthCheckNodePort
+	}
+	for i := range spec.Ports {
+		if spec.Ports[i].NodePort != 0 {
+			continue
+		}
+		for _, port := range existing.Ports {
+			if port.Port == spec.Ports[i].Port && port.Protocol == spec.Ports[i].Protocol {
+				spec.Ports[i].NodePort = port.NodePort
+				break
+			}
+		}
+	}
+}
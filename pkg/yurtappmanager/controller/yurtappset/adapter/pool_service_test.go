@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func newServiceYurtAppSet() *unitv1alpha1.YurtAppSet {
+	yas := newTestYurtAppSet(unitv1alpha1.WorkloadTemplate{
+		StatefulSetTemplate: &unitv1alpha1.StatefulSetTemplateSpec{
+			Spec: appsv1.StatefulSetSpec{
+				ServiceName: "foo",
+			},
+		},
+	})
+	yas.Spec.ServiceTemplate = &unitv1alpha1.ServiceTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{"svc": "foo"},
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports:     []corev1.ServicePort{{Name: "peer", Port: 2380}},
+		},
+	}
+	return yas
+}
+
+func TestApplyPoolServiceTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := unitv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to add scheme: %v", err)
+	}
+	yas := newServiceYurtAppSet()
+
+	svc := &corev1.Service{}
+	if err := ApplyPoolServiceTemplate(yas, "hangzhou", svc, scheme); err != nil {
+		t.Fatalf("fail to apply pool service template: %v", err)
+	}
+	if svc.Name != "foo-hangzhou" || svc.Namespace != "default" {
+		t.Fatalf("expected Service default/foo-hangzhou, got %s/%s", svc.Namespace, svc.Name)
+	}
+	if svc.Spec.Selector["app"] != "foo" || svc.Spec.Selector[unitv1alpha1.PoolNameLabelKey] != "hangzhou" {
+		t.Fatalf("unexpected selector %v", svc.Spec.Selector)
+	}
+	if svc.Labels["svc"] != "foo" || svc.Labels[unitv1alpha1.PoolNameLabelKey] != "hangzhou" {
+		t.Fatalf("unexpected labels %v", svc.Labels)
+	}
+	if !metav1.IsControlledBy(svc, yas) {
+		t.Fatalf("expected Service to be controlled by YurtAppSet")
+	}
+
+	// the rendered Service is stable once it has been created
+	updated := svc.DeepCopy()
+	if err := ApplyPoolServiceTemplate(yas, "hangzhou", updated, scheme); err != nil {
+		t.Fatalf("fail to apply pool service template: %v", err)
+	}
+	if updated.Spec.Ports[0].Protocol != corev1.ProtocolTCP || updated.Spec.SessionAffinity != corev1.ServiceAffinityNone {
+		t.Fatalf("expected rendered Service to be defaulted, got %v", updated.Spec)
+	}
+
+	// allocated fields are kept
+	yas.Spec.ServiceTemplate.Spec.ClusterIP = ""
+	yas.Spec.ServiceTemplate.Spec.Type = corev1.ServiceTypeNodePort
+	svc.Spec.ClusterIP = "10.0.0.1"
+	svc.Spec.Ports[0].NodePort = 30080
+	if err := ApplyPoolServiceTemplate(yas, "hangzhou", svc, scheme); err != nil {
+		t.Fatalf("fail to apply pool service template: %v", err)
+	}
+	if svc.Spec.ClusterIP != "10.0.0.1" || svc.Spec.Ports[0].NodePort != 30080 {
+		t.Fatalf("expected allocated fields to be kept, got %v", svc.Spec)
+	}
+}
+
+func TestStatefulSetServiceNameWithServiceTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := unitv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to add scheme: %v", err)
+	}
+	yas := newServiceYurtAppSet()
+	a := &StatefulSetAdapter{Scheme: scheme}
+
+	set := &appsv1.StatefulSet{}
+	if err := a.ApplyPoolTemplate(yas, "hangzhou", "v1", 1, set); err != nil {
+		t.Fatalf("fail to apply pool template: %v", err)
+	}
+	if set.Spec.ServiceName != "foo-hangzhou" {
+		t.Fatalf("expected serviceName foo-hangzhou, got %s", set.Spec.ServiceName)
+	}
+
+	yas.Spec.ServiceTemplate = nil
+	set = &appsv1.StatefulSet{}
+	if err := a.ApplyPoolTemplate(yas, "hangzhou", "v1", 1, set); err != nil {
+		t.Fatalf("fail to apply pool template: %v", err)
+	}
+	if set.Spec.ServiceName != "foo" {
+		t.Fatalf("expected serviceName foo, got %s", set.Spec.ServiceName)
+	}
+}
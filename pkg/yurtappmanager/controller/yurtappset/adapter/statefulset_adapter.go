@@ -36,6 +36,7 @@ import (
 
 	alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
 	yurtctlutil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/util"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/refmanager"
 )
 
@@ -71,8 +72,9 @@ func (a *StatefulSetAdapter) GetDetails(obj metav1.Object) (ReplicasInfo, error)
 		specReplicas = *set.Spec.Replicas
 	}
 	replicasInfo := ReplicasInfo{
-		Replicas:      specReplicas,
-		ReadyReplicas: set.Status.ReadyReplicas,
+		Replicas:        specReplicas,
+		ReadyReplicas:   set.Status.ReadyReplicas,
+		UpdatedReplicas: set.Status.UpdatedReplicas,
 	}
 
 	return replicasInfo, nil
@@ -145,6 +147,10 @@ func (a *StatefulSetAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName,
 	set.Spec.RevisionHistoryLimit = yas.Spec.RevisionHistoryLimit
 	set.Spec.PodManagementPolicy = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.PodManagementPolicy
 	set.Spec.ServiceName = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.ServiceName
+	if yas.Spec.ServiceTemplate != nil {
+		// every pool is governed by its own Service
+		set.Spec.ServiceName = util.GetPoolServiceName(yas.Name, poolName)
+	}
 	set.Spec.VolumeClaimTemplates = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Spec.VolumeClaimTemplates
 
 	attachNodeAffinityAndTolerations(&set.Spec.Template.Spec, poolConfig)
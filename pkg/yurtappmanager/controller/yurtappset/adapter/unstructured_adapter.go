@@ -0,0 +1,273 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// UnstructuredAdapter drives the custom workloads declared in the workload registry.
+// The pool patches of the custom workloads are applied as json merge patches,
+// because there is no strategic merge schema for them.
+type UnstructuredAdapter struct {
+	client.Client
+
+	Scheme     *runtime.Scheme
+	GVK        schema.GroupVersionKind
+	Definition *WorkloadDefinition
+}
+
+var _ Adapter = &UnstructuredAdapter{}
+
+// NewResourceObject creates a empty workload object of the custom kind.
+func (a *UnstructuredAdapter) NewResourceObject() runtime.Object {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(a.GVK)
+	return obj
+}
+
+// NewResourceListObject creates a empty workload list object of the custom kind.
+func (a *UnstructuredAdapter) NewResourceListObject() runtime.Object {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(a.GVK.GroupVersion().WithKind(a.GVK.Kind + "List"))
+	return list
+}
+
+// GetStatusObservedGeneration returns the observed generation of the pool.
+// The generation of the workload is returned if the definition has no observed generation path.
+func (a *UnstructuredAdapter) GetStatusObservedGeneration(obj metav1.Object) int64 {
+	if a.Definition.ObservedGenerationPath == "" {
+		return obj.GetGeneration()
+	}
+	generation, _, _ := unstructured.NestedInt64(obj.(*unstructured.Unstructured).Object,
+		splitFieldPath(a.Definition.ObservedGenerationPath)...)
+	return generation
+}
+
+// GetDetails returns the replicas detail the pool needs.
+func (a *UnstructuredAdapter) GetDetails(obj metav1.Object) (ReplicasInfo, error) {
+	u := obj.(*unstructured.Unstructured)
+
+	specReplicas, _, err := unstructured.NestedInt64(u.Object, splitFieldPath(a.Definition.ReplicasPath)...)
+	if err != nil {
+		return ReplicasInfo{}, err
+	}
+	var readyReplicas int64
+	if a.Definition.ReadyReplicasPath != "" {
+		readyReplicas, _, err = unstructured.NestedInt64(u.Object, splitFieldPath(a.Definition.ReadyReplicasPath)...)
+		if err != nil {
+			return ReplicasInfo{}, err
+		}
+	}
+	// the rollout of the custom workloads is not understood, all the replicas are regarded as updated
+	return ReplicasInfo{
+		Replicas:        int32(specReplicas),
+		ReadyReplicas:   int32(readyReplicas),
+		UpdatedReplicas: int32(specReplicas),
+	}, nil
+}
+
+// GetPoolFailure returns the failure information of the pool.
+// The conditions of the custom workloads are not understood.
+func (a *UnstructuredAdapter) GetPoolFailure() *string {
+	return nil
+}
+
+// ApplyPoolTemplate updates the pool to the latest revision, depending on the CustomTemplate.
+func (a *UnstructuredAdapter) ApplyPoolTemplate(yas *alpha1.YurtAppSet, poolName, revision string,
+	replicas int32, obj runtime.Object) error {
+	set := obj.(*unstructured.Unstructured)
+
+	var poolConfig *alpha1.Pool
+	for i, pool := range yas.Spec.Topology.Pools {
+		if pool.Name == poolName {
+			poolConfig = &(yas.Spec.Topology.Pools[i])
+			break
+		}
+	}
+	if poolConfig == nil {
+		return fmt.Errorf("fail to find pool config %s", poolName)
+	}
+
+	template := &unstructured.Unstructured{}
+	if err := utiljson.Unmarshal(yas.Spec.WorkloadTemplate.CustomTemplate.Template.Raw, &template.Object); err != nil {
+		return fmt.Errorf("fail to unmarshal custom template: %v", err)
+	}
+
+	set.SetGroupVersionKind(a.GVK)
+	set.SetNamespace(yas.Namespace)
+
+	labels := set.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for k, v := range template.GetLabels() {
+		labels[k] = v
+	}
+	for k, v := range yas.Spec.Selector.MatchLabels {
+		labels[k] = v
+	}
+	labels[alpha1.ControllerRevisionHashLabelKey] = revision
+	// record the pool name as a label
+	labels[alpha1.PoolNameLabelKey] = poolName
+	set.SetLabels(labels)
+
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range template.GetAnnotations() {
+		annotations[k] = v
+	}
+	if poolConfig.Patch == nil {
+		// If No Patches, Must Set patches annotation to ""
+		annotations[alpha1.AnnotationPatchKey] = ""
+	}
+	set.SetAnnotations(annotations)
+
+	set.SetGenerateName(getPoolPrefix(yas.Name, poolName))
+
+	if err := controllerutil.SetControllerReference(yas, set, a.Scheme); err != nil {
+		return err
+	}
+
+	spec, found, err := unstructured.NestedMap(template.Object, "spec")
+	if err != nil {
+		return err
+	}
+	if !found {
+		spec = map[string]interface{}{}
+	}
+	set.Object["spec"] = spec
+
+	if a.Definition.SelectorPath != "" {
+		selectors := yas.Spec.Selector.DeepCopy()
+		selectors.MatchLabels[alpha1.PoolNameLabelKey] = poolName
+		selectorMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(selectors)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(set.Object, selectorMap, splitFieldPath(a.Definition.SelectorPath)...); err != nil {
+			return err
+		}
+	}
+
+	if err := unstructured.SetNestedField(set.Object, int64(replicas), splitFieldPath(a.Definition.ReplicasPath)...); err != nil {
+		return err
+	}
+
+	if err := a.applyPodTemplate(set, poolConfig, revision); err != nil {
+		return err
+	}
+
+	if poolConfig.Patch == nil {
+		klog.Infof("%s[%s/%s-] has no patches, do not need merge", a.GVK.Kind, set.GetNamespace(),
+			set.GetGenerateName())
+		return nil
+	}
+
+	if err := MergeByPatch(set, poolConfig.Patch); err != nil {
+		klog.Errorf("%s[%s/%s-] json merge by patch %s error %v", a.GVK.Kind, set.GetNamespace(),
+			set.GetGenerateName(), string(poolConfig.Patch.Raw), err)
+		return err
+	}
+
+	klog.Infof("%s [%s/%s-] has patches configure successfully:%v", a.GVK.Kind, set.GetNamespace(),
+		set.GetGenerateName(), string(poolConfig.Patch.Raw))
+	return nil
+}
+
+// applyPodTemplate adds the pool labels, node affinity and tolerations to the pod template of the workload.
+func (a *UnstructuredAdapter) applyPodTemplate(set *unstructured.Unstructured, poolConfig *alpha1.Pool, revision string) error {
+	path := splitFieldPath(a.Definition.PodTemplatePath)
+	templateMap, found, err := unstructured.NestedMap(set.Object, path...)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("pod template is not found at %s of %s", a.Definition.PodTemplatePath, a.GVK.Kind)
+	}
+
+	podTemplate := &corev1.PodTemplateSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateMap, podTemplate); err != nil {
+		return err
+	}
+	if podTemplate.Labels == nil {
+		podTemplate.Labels = map[string]string{}
+	}
+	podTemplate.Labels[alpha1.PoolNameLabelKey] = poolConfig.Name
+	podTemplate.Labels[alpha1.ControllerRevisionHashLabelKey] = revision
+	attachNodeAffinityAndTolerations(&podTemplate.Spec, poolConfig)
+
+	templateMap, err = runtime.DefaultUnstructuredConverter.ToUnstructured(podTemplate)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedMap(set.Object, templateMap, path...)
+}
+
+// PostUpdate does some works after pool updated.
+func (a *UnstructuredAdapter) PostUpdate(yas *alpha1.YurtAppSet, obj runtime.Object, revision string) error {
+	// Do nothing,
+	return nil
+}
+
+// IsExpected checks the pool is the expected revision or not.
+// The revision label can tell the current pool revision.
+func (a *UnstructuredAdapter) IsExpected(obj metav1.Object, revision string) bool {
+	return obj.GetLabels()[alpha1.ControllerRevisionHashLabelKey] != revision
+}
+
+// MergeByPatch applies the pool patch to the unstructured object as a json merge patch,
+// and records the patch in the annotations of the object.
+func MergeByPatch(set *unstructured.Unstructured, patch *runtime.RawExtension) error {
+	original, err := json.Marshal(set.Object)
+	if err != nil {
+		return err
+	}
+	patched, err := jsonpatch.MergePatch(original, patch.Raw)
+	if err != nil {
+		return err
+	}
+	object := map[string]interface{}{}
+	if err := utiljson.Unmarshal(patched, &object); err != nil {
+		return err
+	}
+	set.Object = object
+
+	annotations := set.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[alpha1.AnnotationPatchKey] = string(patch.Raw)
+	set.SetAnnotations(annotations)
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+var cloneSetGVK = schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "CloneSet"}
+
+const cloneSetDefinition = `
+apiVersion: apps.kruise.io/v1alpha1
+kind: CloneSet
+replicasPath: spec.replicas
+podTemplatePath: spec.template
+selectorPath: spec.selector
+readyReplicasPath: status.readyReplicas
+observedGenerationPath: status.observedGeneration
+`
+
+const cloneSetTemplate = `{
+	"metadata": {"labels": {"app": "foo"}},
+	"spec": {
+		"updateStrategy": {"type": "InPlaceIfPossible"},
+		"template": {
+			"metadata": {"labels": {"app": "foo"}},
+			"spec": {"containers": [{"name": "nginx", "image": "nginx:1.19"}]}
+		}
+	}
+}`
+
+func newCustomYurtAppSet(patch string) *unitv1alpha1.YurtAppSet {
+	yas := newTestYurtAppSet(unitv1alpha1.WorkloadTemplate{
+		CustomTemplate: &unitv1alpha1.CustomTemplateSpec{
+			APIVersion: cloneSetGVK.GroupVersion().String(),
+			Kind:       cloneSetGVK.Kind,
+			Template:   runtime.RawExtension{Raw: []byte(cloneSetTemplate)},
+		},
+	})
+	if patch != "" {
+		yas.Spec.Topology.Pools[0].Patch = &runtime.RawExtension{Raw: []byte(patch)}
+	}
+	return yas
+}
+
+func newCloneSetAdapter(t *testing.T) *UnstructuredAdapter {
+	scheme := runtime.NewScheme()
+	if err := unitv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("fail to add scheme: %v", err)
+	}
+	cm := &corev1.ConfigMap{Data: map[string]string{"cloneset": cloneSetDefinition}}
+	def, err := FindWorkloadDefinition(cm, cloneSetGVK)
+	if err != nil {
+		t.Fatalf("fail to find workload definition: %v", err)
+	}
+	return &UnstructuredAdapter{Scheme: scheme, GVK: cloneSetGVK, Definition: def}
+}
+
+func TestFindWorkloadDefinition(t *testing.T) {
+	cm := &corev1.ConfigMap{Data: map[string]string{"cloneset": cloneSetDefinition}}
+	if _, err := FindWorkloadDefinition(cm, schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1beta1", Kind: "CloneSet"}); err != nil {
+		t.Fatalf("expected definition to match any version of the group: %v", err)
+	}
+	if _, err := FindWorkloadDefinition(cm, schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "Advanced"}); err == nil {
+		t.Fatalf("expected error for undeclared workload")
+	}
+
+	cm.Data["broken"] = "apiVersion: apps.kruise.io/v1alpha1\nkind: SidecarSet\n"
+	if _, err := FindWorkloadDefinition(cm, schema.GroupVersionKind{Group: "apps.kruise.io", Version: "v1alpha1", Kind: "SidecarSet"}); err == nil {
+		t.Fatalf("expected error for definition without field paths")
+	}
+}
+
+func TestUnstructuredApplyPoolTemplate(t *testing.T) {
+	a := newCloneSetAdapter(t)
+	yas := newCustomYurtAppSet("")
+
+	obj := a.NewResourceObject()
+	if err := a.ApplyPoolTemplate(yas, "hangzhou", "rev-1", 2, obj); err != nil {
+		t.Fatalf("fail to apply pool template: %v", err)
+	}
+	set := obj.(*unstructured.Unstructured)
+
+	if set.GetKind() != "CloneSet" || set.GetNamespace() != "default" || set.GetGenerateName() != "foo-hangzhou-" {
+		t.Fatalf("unexpected metadata %v", set.Object["metadata"])
+	}
+	if set.GetLabels()[unitv1alpha1.PoolNameLabelKey] != "hangzhou" ||
+		set.GetLabels()[unitv1alpha1.ControllerRevisionHashLabelKey] != "rev-1" {
+		t.Fatalf("unexpected labels %v", set.GetLabels())
+	}
+	if len(set.GetOwnerReferences()) != 1 || set.GetOwnerReferences()[0].Name != "foo" {
+		t.Fatalf("unexpected owner references %v", set.GetOwnerReferences())
+	}
+	if replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas"); replicas != 2 {
+		t.Fatalf("unexpected replicas %d", replicas)
+	}
+	if strategy, _, _ := unstructured.NestedString(set.Object, "spec", "updateStrategy", "type"); strategy != "InPlaceIfPossible" {
+		t.Fatalf("expected the template spec to be kept, got %v", set.Object["spec"])
+	}
+	if pool, _, _ := unstructured.NestedString(set.Object, "spec", "selector", "matchLabels", unitv1alpha1.PoolNameLabelKey); pool != "hangzhou" {
+		t.Fatalf("unexpected selector %v", set.Object["spec"])
+	}
+	if pool, _, _ := unstructured.NestedString(set.Object, "spec", "template", "metadata", "labels", unitv1alpha1.PoolNameLabelKey); pool != "hangzhou" {
+		t.Fatalf("unexpected pod template labels %v", set.Object["spec"])
+	}
+	terms, _, _ := unstructured.NestedSlice(set.Object, "spec", "template", "spec", "affinity", "nodeAffinity",
+		"requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+	if len(terms) != 1 {
+		t.Fatalf("expected node affinity of the pool, got %v", terms)
+	}
+	if set.GetAnnotations()[unitv1alpha1.AnnotationPatchKey] != "" {
+		t.Fatalf("expected empty patch annotation, got %v", set.GetAnnotations())
+	}
+
+	info, err := a.GetDetails(set)
+	if err != nil || info.Replicas != 2 || info.ReadyReplicas != 0 {
+		t.Fatalf("unexpected details %v, %v", info, err)
+	}
+}
+
+func TestUnstructuredApplyPoolTemplateWithPatch(t *testing.T) {
+	a := newCloneSetAdapter(t)
+	patch := `{"spec":{"template":{"spec":{"containers":[{"name":"nginx","image":"nginx:1.20"}]}}}}`
+	yas := newCustomYurtAppSet(patch)
+
+	obj := a.NewResourceObject()
+	if err := a.ApplyPoolTemplate(yas, "hangzhou", "rev-1", 2, obj); err != nil {
+		t.Fatalf("fail to apply pool template: %v", err)
+	}
+	set := obj.(*unstructured.Unstructured)
+
+	containers, _, _ := unstructured.NestedSlice(set.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 1 || containers[0].(map[string]interface{})["image"] != "nginx:1.20" {
+		t.Fatalf("unexpected containers %v", containers)
+	}
+	if set.GetAnnotations()[unitv1alpha1.AnnotationPatchKey] != patch {
+		t.Fatalf("unexpected patch annotation %v", set.GetAnnotations())
+	}
+	if replicas, _, _ := unstructured.NestedInt64(set.Object, "spec", "replicas"); replicas != 2 {
+		t.Fatalf("unexpected replicas %d after patch", replicas)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkloadDefinition declares where the replicas, pod template and status of a custom workload kind are.
+// Every field path is a dot separated list of the map keys, e.g. spec.template.
+type WorkloadDefinition struct {
+	// APIVersion of the workload, only its group is used to match the workload.
+	APIVersion string `json:"apiVersion"`
+	// Kind of the workload.
+	Kind string `json:"kind"`
+	// ReplicasPath is the path of the replicas in the workload, e.g. spec.replicas.
+	ReplicasPath string `json:"replicasPath"`
+	// PodTemplatePath is the path of the pod template in the workload, e.g. spec.template.
+	PodTemplatePath string `json:"podTemplatePath"`
+	// SelectorPath is the path of the label selector in the workload, e.g. spec.selector.
+	// The selector is not set if it is empty.
+	SelectorPath string `json:"selectorPath,omitempty"`
+	// ReadyReplicasPath is the path of the ready replicas in the workload, e.g. status.readyReplicas.
+	ReadyReplicasPath string `json:"readyReplicasPath,omitempty"`
+	// ObservedGenerationPath is the path of the observed generation in the workload, e.g. status.observedGeneration.
+	ObservedGenerationPath string `json:"observedGenerationPath,omitempty"`
+}
+
+// Validate checks the required fields of the definition.
+func (d *WorkloadDefinition) Validate() error {
+	if d.APIVersion == "" || d.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required")
+	}
+	if d.ReplicasPath == "" {
+		return fmt.Errorf("replicasPath is required")
+	}
+	if d.PodTemplatePath == "" {
+		return fmt.Errorf("podTemplatePath is required")
+	}
+	return nil
+}
+
+// GetWorkloadDefinition finds the definition of the workload kind in the registry ConfigMap.
+// Every data item of the ConfigMap holds one WorkloadDefinition in yaml, the keys are not significant.
+func GetWorkloadDefinition(c client.Reader, registry types.NamespacedName, gvk schema.GroupVersionKind) (*WorkloadDefinition, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), registry, cm); err != nil {
+		return nil, fmt.Errorf("fail to get workload registry %s: %v", registry, err)
+	}
+	return FindWorkloadDefinition(cm, gvk)
+}
+
+// FindWorkloadDefinition finds the definition of the workload kind in the data of the registry ConfigMap.
+func FindWorkloadDefinition(cm *corev1.ConfigMap, gvk schema.GroupVersionKind) (*WorkloadDefinition, error) {
+	for key, data := range cm.Data {
+		def := &WorkloadDefinition{}
+		if err := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(data), 4096).Decode(def); err != nil {
+			return nil, fmt.Errorf("fail to parse workload definition %s of registry %s/%s: %v", key, cm.Namespace, cm.Name, err)
+		}
+		gv, err := schema.ParseGroupVersion(def.APIVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid apiVersion of workload definition %s: %v", key, err)
+		}
+		if gv.Group != gvk.Group || def.Kind != gvk.Kind {
+			continue
+		}
+		if err := def.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid workload definition %s: %v", key, err)
+		}
+		return def, nil
+	}
+	return nil, fmt.Errorf("workload %s is not declared in registry %s/%s", gvk.GroupKind(), cm.Namespace, cm.Name)
+}
+
+func splitFieldPath(path string) []string {
+	return strings.Split(path, ".")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// EnqueueYurtAppSetForNodePool enqueues the YurtAppSets which enable the autonomy compensation
+// and have a pool of the NodePool.
+type EnqueueYurtAppSetForNodePool struct {
+	client client.Client
+}
+
+func (e *EnqueueYurtAppSetForNodePool) Create(event event.CreateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtAppSetsToWorkQueue(event.Object.GetName(), limitingInterface)
+}
+
+func (e *EnqueueYurtAppSetForNodePool) Update(event event.UpdateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	oldNp, oldOK := event.ObjectOld.(*v1alpha1.NodePool)
+	newNp, newOK := event.ObjectNew.(*v1alpha1.NodePool)
+	if oldOK && newOK && oldNp.Status.ReadyNodeNum == newNp.Status.ReadyNodeNum &&
+		oldNp.Status.UnreadyNodeNum == newNp.Status.UnreadyNodeNum {
+		return
+	}
+	e.addYurtAppSetsToWorkQueue(event.ObjectNew.GetName(), limitingInterface)
+}
+
+func (e *EnqueueYurtAppSetForNodePool) Delete(event event.DeleteEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtAppSetsToWorkQueue(event.Object.GetName(), limitingInterface)
+}
+
+func (e *EnqueueYurtAppSetForNodePool) Generic(event event.GenericEvent, limitingInterface workqueue.RateLimitingInterface) {
+	return
+}
+
+func (e *EnqueueYurtAppSetForNodePool) addYurtAppSetsToWorkQueue(nodePoolName string, q workqueue.RateLimitingInterface) {
+	yass := &v1alpha1.YurtAppSetList{}
+	if err := e.client.List(context.TODO(), yass); err != nil {
+		return
+	}
+
+	for _, yas := range yass.Items {
+		if yas.Spec.AutonomyCompensation == nil {
+			continue
+		}
+		for _, pool := range yas.Spec.Topology.Pools {
+			if pool.Name == nodePoolName {
+				q.Add(reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: yas.GetName(), Namespace: yas.GetNamespace()},
+				})
+				break
+			}
+		}
+	}
+}
+
+var _ handler.EventHandler = &EnqueueYurtAppSetForNodePool{}
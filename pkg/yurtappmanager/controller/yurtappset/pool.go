@@ -19,6 +19,7 @@ package yurtappset
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset/adapter"
@@ -63,4 +64,6 @@ type ControlInterface interface {
 	GetPoolFailure(*Pool) *string
 	// IsExpected check the pool is the expected revision
 	IsExpected(pool *Pool, revision string) bool
+	// RenderPool renders the workload of the pool without writing it. The pool is nil if it is to be created.
+	RenderPool(pool *Pool, yas *unitv1alpha1.YurtAppSet, poolName string, revision string, replicas int32) (client.Object, error)
 }
@@ -148,6 +148,24 @@ func (m *PoolControl) IsExpected(pool *Pool, revision string) bool {
 	return m.adapter.IsExpected(pool.Spec.PoolRef, revision)
 }
 
+// RenderPool renders the workload of the pool to the latest revision without writing it.
+func (m *PoolControl) RenderPool(pool *Pool, yas *alpha1.YurtAppSet, poolName string, revision string,
+	replicas int32) (client.Object, error) {
+
+	set := m.adapter.NewResourceObject()
+	if pool != nil {
+		set = pool.Spec.PoolRef.(runtime.Object).DeepCopyObject()
+	}
+	if err := m.adapter.ApplyPoolTemplate(yas, poolName, revision, replicas, set); err != nil {
+		return nil, err
+	}
+	cliSet, ok := set.(client.Object)
+	if !ok {
+		return nil, errors.New("fail to convert runtime.Object to client.Object")
+	}
+	return cliSet, nil
+}
+
 func (m *PoolControl) convertToPool(set metav1.Object) (*Pool, error) {
 	poolName, err := getPoolNameFrom(set)
 	if err != nil {
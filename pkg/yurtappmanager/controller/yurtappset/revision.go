@@ -27,6 +27,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/controller/history"
@@ -232,6 +233,12 @@ func (r *ReconcileYurtAppSet) newRevision(yas *appsalphav1.YurtAppSet, revision
 		selectedLabels = yas.Spec.WorkloadTemplate.StatefulSetTemplate.Labels
 	case yas.Spec.WorkloadTemplate.DeploymentTemplate != nil:
 		selectedLabels = yas.Spec.WorkloadTemplate.DeploymentTemplate.Labels
+	case yas.Spec.WorkloadTemplate.CustomTemplate != nil:
+		template := &unstructured.Unstructured{}
+		if err := json.Unmarshal(yas.Spec.WorkloadTemplate.CustomTemplate.Template.Raw, &template.Object); err != nil {
+			return nil, err
+		}
+		selectedLabels = template.GetLabels()
 	default:
 		klog.Errorf("YurtAppSet(%s/%s) need specific WorkloadTemplate", yas.GetNamespace(), yas.GetName())
 		return nil, fmt.Errorf("YurtAppSet(%s/%s) need specific WorkloadTemplate", yas.GetNamespace(), yas.GetName())
@@ -278,6 +285,8 @@ func getYurtAppSetPatch(yas *appsalphav1.YurtAppSet) ([]byte, error) {
 	// Create a patch of the YurtAppSet that replaces spec.template
 	spec := raw["spec"].(map[string]interface{})
 	template := spec["workloadTemplate"].(map[string]interface{})
+	// pausing or resuming the Deployments does not change the revision, so the pods are not restarted
+	unstructured.RemoveNestedField(template, "deploymentTemplate", "spec", "paused")
 	specCopy["workloadTemplate"] = template
 	template["$patch"] = "replace"
 	objCopy["spec"] = specCopy
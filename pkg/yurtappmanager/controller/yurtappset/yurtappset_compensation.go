@@ -0,0 +1,215 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+const (
+	defaultMinReadyNodePercentage  = 50
+	defaultOfflineThresholdSeconds = 300
+)
+
+// manageCompensations calculates the compensating replicas for the offline pools, and adds them to the next
+// patches of the compensation pools. It returns the compensations to record in status, and the duration after
+// which the YurtAppSet should be checked again.
+func (r *ReconcileYurtAppSet) manageCompensations(yas *unitv1alpha1.YurtAppSet,
+	nextPatches map[string]YurtAppSetPatches) ([]unitv1alpha1.PoolCompensation, time.Duration, error) {
+
+	nodePools := map[string]*unitv1alpha1.NodePool{}
+	if yas.Spec.AutonomyCompensation != nil {
+		for _, pool := range yas.Spec.Topology.Pools {
+			np := &unitv1alpha1.NodePool{}
+			if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: pool.Name}, np); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				// keep the replicas compensated before, and check the pools waiting for the threshold again in time
+				applyCompensations(nextPatches, yas.Status.Compensations)
+				return yas.Status.Compensations, waitingRequeueAfter(yas, metav1.Now()), err
+			}
+			nodePools[pool.Name] = np
+		}
+	}
+
+	compensations, events, requeueAfter := calculateCompensations(yas, nodePools, nextPatches, metav1.Now())
+	for _, event := range events {
+		klog.Infof("YurtAppSet %s/%s: %s", yas.Namespace, yas.Name, event.message)
+		r.recorder.Event(yas.DeepCopy(), corev1.EventTypeNormal, event.reason, event.message)
+	}
+	applyCompensations(nextPatches, compensations)
+	return compensations, requeueAfter, nil
+}
+
+// isNodePoolOffline checks whether the percentage of ready nodes in the NodePool is below the minimum.
+// A NodePool without nodes is not considered offline, because there is nothing running in it.
+func isNodePoolOffline(np *unitv1alpha1.NodePool, minReadyNodePercentage int32) bool {
+	total := np.Status.ReadyNodeNum + np.Status.UnreadyNodeNum
+	if total == 0 {
+		return false
+	}
+	return np.Status.ReadyNodeNum*100 < minReadyNodePercentage*total
+}
+
+// offlineThreshold returns how long a pool stays offline before its replicas are compensated.
+func offlineThreshold(policy *unitv1alpha1.AutonomyCompensationPolicy) time.Duration {
+	if policy.OfflineThresholdSeconds != nil && *policy.OfflineThresholdSeconds > 0 {
+		return time.Duration(*policy.OfflineThresholdSeconds) * time.Second
+	}
+	return time.Duration(defaultOfflineThresholdSeconds) * time.Second
+}
+
+// waitingRequeueAfter returns the duration after which the first of the offline pools recorded in status
+// stays offline longer than the threshold, zero if none of them is waiting for it.
+func waitingRequeueAfter(yas *unitv1alpha1.YurtAppSet, now metav1.Time) time.Duration {
+	if yas.Spec.AutonomyCompensation == nil {
+		return 0
+	}
+	threshold := offlineThreshold(yas.Spec.AutonomyCompensation)
+	var requeueAfter time.Duration
+	for _, c := range yas.Status.Compensations {
+		if wait := threshold - now.Sub(c.OfflineSince.Time); wait > 0 && (requeueAfter == 0 || wait < requeueAfter) {
+			requeueAfter = wait
+		}
+	}
+	return requeueAfter
+}
+
+// calculateCompensations calculates the compensations of the offline pools, starting from the ones recorded in status.
+// A pool is recorded once its NodePool is observed offline, and all of its replicas in the next patches, which are
+// adjusted by the overflow, are compensated in the first healthy compensation pool after it stays offline longer than
+// the threshold. The compensation is withdrawn as soon as the pool recovers.
+func calculateCompensations(yas *unitv1alpha1.YurtAppSet, nodePools map[string]*unitv1alpha1.NodePool,
+	nextPatches map[string]YurtAppSetPatches, now metav1.Time) ([]unitv1alpha1.PoolCompensation, []placementEvent, time.Duration) {
+
+	var events []placementEvent
+	policy := yas.Spec.AutonomyCompensation
+	if policy == nil {
+		for _, c := range yas.Status.Compensations {
+			if c.Replicas > 0 {
+				events = append(events, newWithdrawEvent(c))
+			}
+		}
+		return nil, events, 0
+	}
+
+	minReadyNodePercentage := int32(defaultMinReadyNodePercentage)
+	if policy.MinReadyNodePercentage != nil {
+		minReadyNodePercentage = *policy.MinReadyNodePercentage
+	}
+	threshold := offlineThreshold(policy)
+
+	offline := map[string]bool{}
+	for name, np := range nodePools {
+		offline[name] = isNodePoolOffline(np, minReadyNodePercentage)
+	}
+	recorded := map[string]unitv1alpha1.PoolCompensation{}
+	for _, c := range yas.Status.Compensations {
+		recorded[c.Pool] = c
+	}
+
+	var compensations []unitv1alpha1.PoolCompensation
+	var requeueAfter time.Duration
+	for _, pool := range yas.Spec.Topology.Pools {
+		c, exist := recorded[pool.Name]
+		if !offline[pool.Name] {
+			if exist && c.Replicas > 0 {
+				events = append(events, newWithdrawEvent(c))
+			}
+			continue
+		}
+		if !exist {
+			c = unitv1alpha1.PoolCompensation{Pool: pool.Name, OfflineSince: now}
+		}
+
+		if wait := threshold - now.Sub(c.OfflineSince.Time); wait > 0 {
+			if requeueAfter == 0 || wait < requeueAfter {
+				requeueAfter = wait
+			}
+			compensations = append(compensations, c)
+			continue
+		}
+
+		replicas := nextPatches[pool.Name].Replicas
+		compensationPool := pickCompensationPool(policy.CompensationPools, pool.Name, nodePools, offline)
+		if compensationPool != c.CompensationPool || replicas != c.Replicas {
+			if c.Replicas > 0 {
+				events = append(events, newWithdrawEvent(c))
+			}
+			c.CompensationPool = compensationPool
+			c.Replicas = 0
+			if compensationPool != "" {
+				c.Replicas = replicas
+				events = append(events, placementEvent{
+					reason: eventTypeCompensateReplicas,
+					message: fmt.Sprintf("Pool %s is offline since %s, add %d compensating replicas to pool %s",
+						pool.Name, c.OfflineSince.Format(time.RFC3339), replicas, compensationPool),
+				})
+			}
+		}
+		compensations = append(compensations, c)
+	}
+
+	return compensations, events, requeueAfter
+}
+
+// pickCompensationPool returns the first compensation pool whose NodePool is healthy.
+func pickCompensationPool(compensationPools []string, offlinePool string, nodePools map[string]*unitv1alpha1.NodePool,
+	offline map[string]bool) string {
+
+	for _, name := range compensationPools {
+		if name == offlinePool {
+			continue
+		}
+		if _, exist := nodePools[name]; exist && !offline[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+func newWithdrawEvent(c unitv1alpha1.PoolCompensation) placementEvent {
+	return placementEvent{
+		reason: eventTypeWithdrawReplicas,
+		message: fmt.Sprintf("Withdraw %d compensating replicas of pool %s from pool %s",
+			c.Replicas, c.Pool, c.CompensationPool),
+	}
+}
+
+// applyCompensations adds the compensating replicas to the compensation pools in the next patches.
+// The replicas of the offline pools are kept unchanged, so that their pods are never deleted.
+func applyCompensations(nextPatches map[string]YurtAppSetPatches, compensations []unitv1alpha1.PoolCompensation) {
+	for _, c := range compensations {
+		to, exist := nextPatches[c.CompensationPool]
+		if !exist || c.Replicas <= 0 {
+			continue
+		}
+		to.Replicas += c.Replicas
+		nextPatches[c.CompensationPool] = to
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func newNodePool(name string, ready, unready int32) *unitv1alpha1.NodePool {
+	return &unitv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: unitv1alpha1.NodePoolStatus{
+			ReadyNodeNum:   ready,
+			UnreadyNodeNum: unready,
+		},
+	}
+}
+
+func newCompensationYurtAppSet(compensations ...unitv1alpha1.PoolCompensation) *unitv1alpha1.YurtAppSet {
+	yas := newTestYurtAppSet(newTestPool("edge", 2), newTestPool("cloud", 1))
+	yas.Spec.AutonomyCompensation = &unitv1alpha1.AutonomyCompensationPolicy{
+		CompensationPools:       []string{"cloud"},
+		MinReadyNodePercentage:  utilpointer.Int32Ptr(50),
+		OfflineThresholdSeconds: utilpointer.Int32Ptr(60),
+	}
+	yas.Status.Compensations = compensations
+	return yas
+}
+
+func TestCalculateCompensations(t *testing.T) {
+	now := metav1.Now()
+	longAgo := metav1.NewTime(now.Add(-time.Hour))
+
+	cases := []struct {
+		name          string
+		yas           *unitv1alpha1.YurtAppSet
+		nodePools     map[string]*unitv1alpha1.NodePool
+		expectedEdge  int32
+		expectedCloud int32
+		expectRecord  bool
+	}{
+		{
+			name: "healthy pools",
+			yas:  newCompensationYurtAppSet(),
+			nodePools: map[string]*unitv1alpha1.NodePool{
+				"edge":  newNodePool("edge", 2, 0),
+				"cloud": newNodePool("cloud", 1, 0),
+			},
+			expectedEdge:  2,
+			expectedCloud: 1,
+		},
+		{
+			name: "record the offline pool before the threshold",
+			yas:  newCompensationYurtAppSet(),
+			nodePools: map[string]*unitv1alpha1.NodePool{
+				"edge":  newNodePool("edge", 0, 2),
+				"cloud": newNodePool("cloud", 1, 0),
+			},
+			expectedEdge:  2,
+			expectedCloud: 1,
+			expectRecord:  true,
+		},
+		{
+			name: "compensate the offline pool after the threshold",
+			yas:  newCompensationYurtAppSet(unitv1alpha1.PoolCompensation{Pool: "edge", OfflineSince: longAgo}),
+			nodePools: map[string]*unitv1alpha1.NodePool{
+				"edge":  newNodePool("edge", 1, 3),
+				"cloud": newNodePool("cloud", 1, 0),
+			},
+			expectedEdge:  2,
+			expectedCloud: 3,
+			expectRecord:  true,
+		},
+		{
+			name: "no healthy compensation pool",
+			yas:  newCompensationYurtAppSet(unitv1alpha1.PoolCompensation{Pool: "edge", OfflineSince: longAgo}),
+			nodePools: map[string]*unitv1alpha1.NodePool{
+				"edge":  newNodePool("edge", 0, 2),
+				"cloud": newNodePool("cloud", 0, 1),
+			},
+			expectedEdge:  2,
+			expectedCloud: 1,
+			expectRecord:  true,
+		},
+		{
+			name: "withdraw when the pool recovers",
+			yas: newCompensationYurtAppSet(unitv1alpha1.PoolCompensation{
+				Pool: "edge", CompensationPool: "cloud", Replicas: 2, OfflineSince: longAgo}),
+			nodePools: map[string]*unitv1alpha1.NodePool{
+				"edge":  newNodePool("edge", 2, 0),
+				"cloud": newNodePool("cloud", 1, 0),
+			},
+			expectedEdge:  2,
+			expectedCloud: 1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := GetNextPatches(c.yas)
+			compensations, _, _ := calculateCompensations(c.yas, c.nodePools, next, now)
+			if (len(compensations) > 0) != c.expectRecord {
+				t.Fatalf("unexpected compensations %v", compensations)
+			}
+
+			applyCompensations(next, compensations)
+			if next["edge"].Replicas != c.expectedEdge || next["cloud"].Replicas != c.expectedCloud {
+				t.Fatalf("unexpected next patches %v", next)
+			}
+		})
+	}
+}
+
+func TestCompensateAdjustedReplicas(t *testing.T) {
+	now := metav1.Now()
+	yas := newCompensationYurtAppSet(unitv1alpha1.PoolCompensation{
+		Pool: "edge", OfflineSince: metav1.NewTime(now.Add(-time.Hour))})
+	nodePools := map[string]*unitv1alpha1.NodePool{
+		"edge":  newNodePool("edge", 0, 2),
+		"cloud": newNodePool("cloud", 1, 0),
+	}
+	// one of the replicas of the edge pool overflows to the cloud pool
+	next := map[string]YurtAppSetPatches{"edge": {Replicas: 1}, "cloud": {Replicas: 2}}
+	compensations, _, _ := calculateCompensations(yas, nodePools, next, now)
+	if len(compensations) != 1 || compensations[0].Replicas != 1 {
+		t.Fatalf("expected the replicas left in the offline pool to be compensated, got %v", compensations)
+	}
+	applyCompensations(next, compensations)
+	if next["edge"].Replicas != 1 || next["cloud"].Replicas != 3 {
+		t.Fatalf("unexpected next patches %v", next)
+	}
+}
+
+func TestWaitingRequeueAfter(t *testing.T) {
+	now := metav1.Now()
+	yas := newCompensationYurtAppSet(
+		unitv1alpha1.PoolCompensation{Pool: "edge", OfflineSince: metav1.NewTime(now.Add(-20 * time.Second))},
+		unitv1alpha1.PoolCompensation{Pool: "cloud", OfflineSince: metav1.NewTime(now.Add(-time.Hour))})
+	if requeueAfter := waitingRequeueAfter(yas, now); requeueAfter != 40*time.Second {
+		t.Fatalf("expected to check the waiting pool after 40s, got %v", requeueAfter)
+	}
+	yas.Spec.AutonomyCompensation = nil
+	if requeueAfter := waitingRequeueAfter(yas, now); requeueAfter != 0 {
+		t.Fatalf("expected no requeue without the compensation policy, got %v", requeueAfter)
+	}
+}
+
+func TestIsNodePoolOffline(t *testing.T) {
+	if isNodePoolOffline(newNodePool("empty", 0, 0), 50) {
+		t.Fatalf("expected empty NodePool not to be offline")
+	}
+	if !isNodePoolOffline(newNodePool("edge", 1, 2), 50) {
+		t.Fatalf("expected NodePool with 1/3 ready nodes to be offline")
+	}
+	if isNodePoolOffline(newNodePool("edge", 1, 1), 50) {
+		t.Fatalf("expected NodePool with 1/2 ready nodes not to be offline")
+	}
+}
@@ -23,9 +23,9 @@ package yurtappset
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"reflect"
+	"sync"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -41,37 +41,42 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset/adapter"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/metrics"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 )
 
-func init() {
-	flag.IntVar(&concurrentReconciles, "yurtappset-workers", concurrentReconciles, "Max concurrent workers for YurtAppSet controller.")
-}
-
-var (
-	concurrentReconciles = 3
-)
+// workloadRegistry is the namespace/name of the ConfigMap which declares the custom workloads of YurtAppSet
+var workloadRegistry = configv1alpha1.DefaultWorkloadRegistry
 
 const (
 	controllerName = "yurtappset-controller"
+	// metricsKind is the kind label of the metrics of YurtAppSet
+	metricsKind = "YurtAppSet"
 
 	eventTypeRevisionProvision  = "RevisionProvision"
 	eventTypeFindPools          = "FindPools"
 	eventTypeDupPoolsDelete     = "DeleteDuplicatedPools"
 	eventTypePoolsUpdate        = "UpdatePool"
 	eventTypeTemplateController = "TemplateController"
+	eventTypePoolServicesSync   = "SyncPoolServices"
+	eventTypeOverflowReplicas   = "OverflowReplicas"
+	eventTypeReturnReplicas     = "ReturnReplicas"
+	eventTypeCompensateReplicas = "CompensateReplicas"
+	eventTypeWithdrawReplicas   = "WithdrawReplicas"
 
 	slowStartInitialBatchSize = 1
 )
 
 // Add creates a new YurtAppSet Controller and adds it to the Manager with default RBAC. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
-func Add(mgr manager.Manager, _ context.Context) error {
-	if !gate.ResourceEnabled(&unitv1alpha1.YurtAppSet{}) {
-		return nil
-	}
-	return add(mgr, newReconciler(mgr))
+// It is called once the CRD of YurtAppSet is installed and YurtAppSet is enabled.
+func Add(mgr manager.Manager, ctx context.Context) error {
+	c := util.ControllersConfiguration(ctx).YurtAppSet
+	workloadRegistry = c.WorkloadRegistry
+	return add(mgr, newReconciler(mgr), c.ControllerConfiguration)
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -80,23 +85,31 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		Client: mgr.GetClient(),
 		scheme: mgr.GetScheme(),
 
-		recorder: mgr.GetEventRecorderFor(controllerName),
-		poolControls: map[unitv1alpha1.TemplateType]ControlInterface{
-			unitv1alpha1.StatefulSetTemplateType: &PoolControl{Client: mgr.GetClient(), scheme: mgr.GetScheme(),
-				adapter: &adapter.StatefulSetAdapter{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
-			unitv1alpha1.DeploymentTemplateType: &PoolControl{Client: mgr.GetClient(), scheme: mgr.GetScheme(),
-				adapter: &adapter.DeploymentAdapter{Client: mgr.GetClient(), Scheme: mgr.GetScheme()}},
-		},
+		recorder:     mgr.GetEventRecorderFor(controllerName),
+		poolControls: newPoolControls(mgr.GetClient(), mgr.GetScheme()),
+	}
+}
+
+func newPoolControls(c client.Client, scheme *runtime.Scheme) map[unitv1alpha1.TemplateType]ControlInterface {
+	return map[unitv1alpha1.TemplateType]ControlInterface{
+		unitv1alpha1.StatefulSetTemplateType: &PoolControl{Client: c, scheme: scheme,
+			adapter: &adapter.StatefulSetAdapter{Client: c, Scheme: scheme}},
+		unitv1alpha1.DeploymentTemplateType: &PoolControl{Client: c, scheme: scheme,
+			adapter: &adapter.DeploymentAdapter{Client: c, Scheme: scheme}},
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, cfg configv1alpha1.ControllerConfiguration) error {
 	// Create a new controller
-	c, err := controller.New(controllerName, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: concurrentReconciles})
+	c, err := controller.New(controllerName, mgr, util.ControllerOptions(gate.NewReconciler(&unitv1alpha1.YurtAppSet{}, r), cfg))
 	if err != nil {
 		return err
 	}
+	if reconciler, ok := r.(*ReconcileYurtAppSet); ok {
+		// the custom workloads are watched once they are used
+		reconciler.controller = c
+	}
 
 	// Watch for changes to YurtAppSet
 	err = c.Watch(&source.Kind{Type: &unitv1alpha1.YurtAppSet{}}, &handler.EnqueueRequestForObject{})
@@ -120,6 +133,21 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &unitv1alpha1.YurtAppSet{},
+	})
+	if err != nil {
+		return err
+	}
+
+	if gate.ResourceEnabled(&unitv1alpha1.NodePool{}) {
+		err = c.Watch(&source.Kind{Type: &unitv1alpha1.NodePool{}}, &EnqueueYurtAppSetForNodePool{client: mgr.GetClient()})
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -132,6 +160,9 @@ type ReconcileYurtAppSet struct {
 
 	recorder     record.EventRecorder
 	poolControls map[unitv1alpha1.TemplateType]ControlInterface
+
+	controller       controller.Controller
+	watchedWorkloads sync.Map
 }
 
 // +kubebuilder:rbac:groups=apps.openyurt.io,resources=yurtappsets,verbs=get;list;watch;create;update;patch;delete
@@ -143,6 +174,10 @@ type ReconcileYurtAppSet struct {
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile reads that state of the cluster for a YurtAppSet object and makes changes based on the state read
@@ -154,6 +189,7 @@ func (r *ReconcileYurtAppSet) Reconcile(_ context.Context, request reconcile.Req
 	err := r.Get(context.TODO(), request.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			metrics.DeleteWorkload(metricsKind, request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		return reconcile.Result{}, err
@@ -188,19 +224,48 @@ func (r *ReconcileYurtAppSet) Reconcile(_ context.Context, request reconcile.Req
 	}
 
 	nextPatches := GetNextPatches(instance)
+	overflows, requeueAfter, err := r.manageOverflows(instance, nextPatches)
+	if err != nil {
+		klog.Errorf("Fail to manage overflow replicas of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeOverflowReplicas), err.Error())
+	}
+	compensations, compensationRequeueAfter, err := r.manageCompensations(instance, nextPatches)
+	if err != nil {
+		klog.Errorf("Fail to manage compensating replicas of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypeCompensateReplicas), err.Error())
+	}
+	if compensationRequeueAfter > 0 && (requeueAfter == 0 || compensationRequeueAfter < requeueAfter) {
+		requeueAfter = compensationRequeueAfter
+	}
 	klog.V(4).Infof("Get YurtAppSet %s/%s next Patches %v", instance.Namespace, instance.Name, nextPatches)
 
 	expectedRevision := currentRevision
 	if updatedRevision != nil {
 		expectedRevision = updatedRevision
 	}
-	newStatus, err := r.managePools(instance, nameToPool, nextPatches, expectedRevision, poolType)
+	newStatus, err := r.managePools(instance, nameToPool, nextPatches, expectedRevision, poolType, control)
 	if err != nil {
 		klog.Errorf("Fail to update YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, err)
 		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), err.Error())
 	}
+	recordMetrics(instance, nameToPool, expectedRevision.Name)
+	newStatus.OverflowReplicas = overflows
+	newStatus.Compensations = compensations
+
+	svcErr := r.managePoolServices(instance)
+	if svcErr != nil {
+		klog.Errorf("Fail to manage pool Services of YurtAppSet %s/%s: %s", instance.Namespace, instance.Name, svcErr)
+		r.recorder.Event(instance.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolServicesSync), svcErr.Error())
+	}
 
-	return r.updateStatus(instance, newStatus, oldStatus, nameToPool, currentRevision, collisionCount, control)
+	result, err := r.updateStatus(instance, newStatus, oldStatus, nameToPool, currentRevision, collisionCount, control)
+	if err == nil && svcErr != nil {
+		return result, svcErr
+	}
+	if err == nil && requeueAfter > 0 {
+		result.RequeueAfter = requeueAfter
+	}
+	return result, err
 }
 
 func (r *ReconcileYurtAppSet) getNameToPool(instance *unitv1alpha1.YurtAppSet, control ControlInterface) (map[string]*Pool, error) {
@@ -253,10 +318,13 @@ func (r *ReconcileYurtAppSet) getPoolControls(instance *unitv1alpha1.YurtAppSet)
 		return r.poolControls[unitv1alpha1.StatefulSetTemplateType], unitv1alpha1.StatefulSetTemplateType, nil
 	case instance.Spec.WorkloadTemplate.DeploymentTemplate != nil:
 		return r.poolControls[unitv1alpha1.DeploymentTemplateType], unitv1alpha1.DeploymentTemplateType, nil
+	case instance.Spec.WorkloadTemplate.CustomTemplate != nil:
+		control, err := r.getCustomPoolControl(instance.Spec.WorkloadTemplate.CustomTemplate)
+		return control, unitv1alpha1.CustomTemplateType, err
 	default:
 		klog.Errorf("The appropriate WorkloadTemplate was not found")
-		return nil, "", fmt.Errorf("The appropriate WorkloadTemplate was not found, Now Support(%s/%s)",
-			unitv1alpha1.StatefulSetTemplateType, unitv1alpha1.DeploymentTemplateType)
+		return nil, "", fmt.Errorf("The appropriate WorkloadTemplate was not found, Now Support(%s/%s/%s)",
+			unitv1alpha1.StatefulSetTemplateType, unitv1alpha1.DeploymentTemplateType, unitv1alpha1.CustomTemplateType)
 	}
 }
 
@@ -270,6 +338,24 @@ func (r *ReconcileYurtAppSet) classifyPoolByPoolName(pools []*Pool) map[string][
 	return mapping
 }
 
+// recordMetrics records the replicas of the pools and the rollout of the expected revision of the YurtAppSet.
+func recordMetrics(instance *unitv1alpha1.YurtAppSet, nameToPool map[string]*Pool, expectedRevision string) {
+	rolledOut := true
+	pools := make(map[string]metrics.PoolReplicas, len(nameToPool))
+	for name, pool := range nameToPool {
+		replicas := metrics.PoolReplicas{Desired: pool.Status.Replicas, Ready: pool.Status.ReadyReplicas}
+		if pool.Spec.PoolRef.GetLabels()[unitv1alpha1.ControllerRevisionHashLabelKey] == expectedRevision {
+			replicas.Updated = pool.Status.UpdatedReplicas
+		}
+		if replicas.Updated != replicas.Desired || replicas.Ready != replicas.Desired {
+			rolledOut = false
+		}
+		pools[name] = replicas
+	}
+	metrics.SetWorkloadReplicas(metricsKind, instance.Namespace, instance.Name, pools)
+	metrics.SetWorkloadRevision(metricsKind, instance.Namespace, instance.Name, expectedRevision, rolledOut)
+}
+
 func (r *ReconcileYurtAppSet) updateStatus(instance *unitv1alpha1.YurtAppSet, newStatus, oldStatus *unitv1alpha1.YurtAppSetStatus,
 	nameToPool map[string]*Pool, currentRevision *appsv1.ControllerRevision,
 	collisionCount int32, control ControlInterface) (reconcile.Result, error) {
@@ -328,6 +414,8 @@ func getPoolTemplateType(obj *unitv1alpha1.YurtAppSet) (templateType unitv1alpha
 		templateType = unitv1alpha1.StatefulSetTemplateType
 	case template.DeploymentTemplate != nil:
 		templateType = unitv1alpha1.DeploymentTemplateType
+	case template.CustomTemplate != nil:
+		templateType = unitv1alpha1.CustomTemplateType
 	default:
 		klog.Warning("YurtAppSet.Spec.WorkloadTemplate exist wrong template")
 	}
@@ -341,6 +429,8 @@ func (r *ReconcileYurtAppSet) updateYurtAppSet(yas *unitv1alpha1.YurtAppSet, old
 		oldStatus.ReadyReplicas == newStatus.ReadyReplicas &&
 		yas.Generation == newStatus.ObservedGeneration &&
 		reflect.DeepEqual(oldStatus.PoolReplicas, newStatus.PoolReplicas) &&
+		reflect.DeepEqual(oldStatus.OverflowReplicas, newStatus.OverflowReplicas) &&
+		reflect.DeepEqual(oldStatus.Compensations, newStatus.Compensations) &&
 		reflect.DeepEqual(oldStatus.Conditions, newStatus.Conditions) {
 		return yas, nil
 	}
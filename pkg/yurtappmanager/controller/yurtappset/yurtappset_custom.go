@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset/adapter"
+)
+
+// getCustomPoolControl returns the pool control of the custom workload kind, which is driven by
+// the unstructured adapter with the field paths declared in the workload registry.
+func (r *ReconcileYurtAppSet) getCustomPoolControl(template *unitv1alpha1.CustomTemplateSpec) (ControlInterface, error) {
+	gv, err := schema.ParseGroupVersion(template.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	gvk := gv.WithKind(template.Kind)
+
+	registry, err := parseWorkloadRegistry(workloadRegistry)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := adapter.GetWorkloadDefinition(r.Client, registry, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.watchCustomWorkload(gvk); err != nil {
+		return nil, err
+	}
+
+	return &PoolControl{Client: r.Client, scheme: r.scheme,
+		adapter: &adapter.UnstructuredAdapter{Client: r.Client, Scheme: r.scheme, GVK: gvk, Definition: definition}}, nil
+}
+
+// watchCustomWorkload watches the custom workload kind for the YurtAppSet controller, once for every kind.
+func (r *ReconcileYurtAppSet) watchCustomWorkload(gvk schema.GroupVersionKind) error {
+	if r.controller == nil {
+		return nil
+	}
+	if _, loaded := r.watchedWorkloads.LoadOrStore(gvk, struct{}{}); loaded {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := r.controller.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &unitv1alpha1.YurtAppSet{},
+	})
+	if err != nil {
+		r.watchedWorkloads.Delete(gvk)
+		return fmt.Errorf("fail to watch custom workload %s: %v", gvk, err)
+	}
+	klog.Infof("YurtAppSet controller starts watching custom workload %s", gvk)
+	return nil
+}
+
+func parseWorkloadRegistry(registry string) (types.NamespacedName, error) {
+	parts := strings.Split(registry, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid workload registry %q, expect namespace/name", registry)
+	}
+	return types.NamespacedName{Namespace: parts[0], Name: parts[1]}, nil
+}
@@ -0,0 +1,323 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+const (
+	defaultUnschedulableThresholdSeconds = 300
+	maxReturnBackoff                     = time.Hour
+)
+
+// placementEvent describes one change of the replicas placement which is recorded as an event of the YurtAppSet.
+type placementEvent struct {
+	reason  string
+	message string
+}
+
+// manageOverflows calculates the replicas which should overflow to the fallback pools, and applies them to
+// the next patches of the pools. It returns the overflows to record in status, and the duration after which
+// the YurtAppSet should be checked again.
+func (r *ReconcileYurtAppSet) manageOverflows(yas *unitv1alpha1.YurtAppSet,
+	nextPatches map[string]YurtAppSetPatches) ([]unitv1alpha1.PoolOverflow, time.Duration, error) {
+
+	now := metav1.Now()
+	var unschedulable, pending map[string]int32
+	var requeueAfter time.Duration
+	if yas.Spec.ElasticPlacement != nil {
+		threshold := getUnschedulableThreshold(yas.Spec.ElasticPlacement)
+		var err error
+		unschedulable, pending, err = r.getUnschedulablePods(yas, threshold, now.Time)
+		if err != nil {
+			// keep the replicas moved before
+			applyOverflows(nextPatches, yas.Status.OverflowReplicas)
+			return yas.Status.OverflowReplicas, threshold, err
+		}
+		requeueAfter = threshold
+	}
+
+	overflows, events := calculateOverflows(yas, unschedulable, pending, now)
+	for _, event := range events {
+		klog.Infof("YurtAppSet %s/%s: %s", yas.Namespace, yas.Name, event.message)
+		r.recorder.Event(yas.DeepCopy(), corev1.EventTypeNormal, event.reason, event.message)
+	}
+	applyOverflows(nextPatches, overflows)
+
+	if len(pending) == 0 && len(overflows) == 0 {
+		requeueAfter = 0
+	}
+	return overflows, requeueAfter, nil
+}
+
+// getUnschedulablePods counts the pods of every pool which stay unschedulable longer than the threshold.
+// It also counts the pending pods of every pool, however long they have been pending.
+func (r *ReconcileYurtAppSet) getUnschedulablePods(yas *unitv1alpha1.YurtAppSet, threshold time.Duration,
+	now time.Time) (map[string]int32, map[string]int32, error) {
+
+	selector, err := metav1.LabelSelectorAsSelector(yas.Spec.Selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	podList := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), podList, client.InNamespace(yas.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, nil, err
+	}
+
+	unschedulable := map[string]int32{}
+	pending := map[string]int32{}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		poolName := pod.Labels[unitv1alpha1.PoolNameLabelKey]
+		if poolName == "" || pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		pending[poolName]++
+		cond := getPodUnschedulableCondition(pod)
+		if cond == nil {
+			continue
+		}
+		if now.Sub(cond.LastTransitionTime.Time) >= threshold {
+			unschedulable[poolName]++
+		}
+	}
+	return unschedulable, pending, nil
+}
+
+// getPodUnschedulableCondition returns the PodScheduled condition of the pod if the scheduler has marked it unschedulable.
+func getPodUnschedulableCondition(pod *corev1.Pod) *corev1.PodCondition {
+	if pod.Status.Phase != corev1.PodPending {
+		return nil
+	}
+	for i := range pod.Status.Conditions {
+		cond := &pod.Status.Conditions[i]
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse &&
+			cond.Reason == corev1.PodReasonUnschedulable {
+			return cond
+		}
+	}
+	return nil
+}
+
+func getUnschedulableThreshold(strategy *unitv1alpha1.ElasticPlacementStrategy) time.Duration {
+	seconds := int32(defaultUnschedulableThresholdSeconds)
+	if strategy.UnschedulableThresholdSeconds != nil && *strategy.UnschedulableThresholdSeconds > 0 {
+		seconds = *strategy.UnschedulableThresholdSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// calculateOverflows calculates the replicas moved to the fallback pools, starting from the ones recorded in status.
+// The unschedulable replicas of a pool are moved to a fallback pool, and the moved replicas are returned one by one
+// once the pool has no pending pods. The replicas of one pool change at most once per threshold, so that
+// the previous change can take effect before the next one. Every time a returned replica overflows again,
+// the wait before the next return of the pool doubles, so that a pool without capacity does not flap.
+func calculateOverflows(yas *unitv1alpha1.YurtAppSet, unschedulable, pending map[string]int32,
+	now metav1.Time) ([]unitv1alpha1.PoolOverflow, []placementEvent) {
+
+	var events []placementEvent
+	strategy := yas.Spec.ElasticPlacement
+	if strategy == nil {
+		for _, o := range yas.Status.OverflowReplicas {
+			if o.Replicas > 0 {
+				events = append(events, newReturnEvent(o.Replicas, o.FallbackPool, o.Pool))
+			}
+		}
+		return nil, events
+	}
+
+	threshold := getUnschedulableThreshold(strategy)
+	poolReplicas := map[string]int32{}
+	for _, pool := range yas.Spec.Topology.Pools {
+		poolReplicas[pool.Name] = 0
+		if pool.Replicas != nil {
+			poolReplicas[pool.Name] = *pool.Replicas
+		}
+	}
+	fallbacks := sets.NewString(strategy.FallbackPools...)
+
+	// drop the overflows whose pools are no longer valid
+	var overflows []unitv1alpha1.PoolOverflow
+	for _, o := range yas.Status.OverflowReplicas {
+		_, poolExist := poolReplicas[o.Pool]
+		_, fallbackExist := poolReplicas[o.FallbackPool]
+		if !poolExist || !fallbackExist || fallbacks.Has(o.Pool) || !fallbacks.Has(o.FallbackPool) {
+			if o.Replicas > 0 {
+				events = append(events, newReturnEvent(o.Replicas, o.FallbackPool, o.Pool))
+			}
+			continue
+		}
+		// the overflows whose replicas are all returned are kept until the returned replicas get scheduled,
+		// so that the backoff still applies if they overflow again
+		if o.Replicas <= 0 && (o.LastReturnTime == nil ||
+			pending[o.Pool] == 0 && now.Sub(o.LastReturnTime.Time) >= getReturnWait(&o, threshold)) {
+			continue
+		}
+		overflows = append(overflows, *o.DeepCopy())
+	}
+
+	for _, pool := range yas.Spec.Topology.Pools {
+		if fallbacks.Has(pool.Name) {
+			continue
+		}
+
+		var moved int32
+		var lastUpdateTime metav1.Time
+		var lastReturnTime *metav1.Time
+		returnWait := threshold
+		last := -1
+		for i := range overflows {
+			o := &overflows[i]
+			if o.Pool != pool.Name {
+				continue
+			}
+			moved += o.Replicas
+			if lastUpdateTime.Before(&o.LastUpdateTime) {
+				lastUpdateTime = o.LastUpdateTime
+			}
+			if o.LastReturnTime != nil && (lastReturnTime == nil || lastReturnTime.Before(o.LastReturnTime)) {
+				lastReturnTime = o.LastReturnTime
+			}
+			if wait := getReturnWait(o, threshold); wait > returnWait {
+				returnWait = wait
+			}
+			if o.Replicas > 0 {
+				last = i
+			}
+		}
+		if moved > 0 && now.Sub(lastUpdateTime.Time) < threshold {
+			continue
+		}
+
+		if count := unschedulable[pool.Name]; count > 0 && moved < poolReplicas[pool.Name] {
+			if count > poolReplicas[pool.Name]-moved {
+				count = poolReplicas[pool.Name] - moved
+			}
+			fallback := pickFallbackPool(strategy.FallbackPools, poolReplicas, unschedulable)
+			if fallback == "" {
+				continue
+			}
+			overflows = addOverflow(overflows, pool.Name, fallback, count, now)
+			if lastReturnTime != nil && !lastReturnTime.Before(&lastUpdateTime) {
+				// the last change of the pool is a return, so the returned replica overflows again
+				backoff := 2 * returnWait
+				if backoff > maxReturnBackoff {
+					backoff = maxReturnBackoff
+				}
+				for i := range overflows {
+					if overflows[i].Pool == pool.Name {
+						overflows[i].ReturnBackoffSeconds = int32(backoff / time.Second)
+					}
+				}
+			}
+			events = append(events, placementEvent{
+				reason:  eventTypeOverflowReplicas,
+				message: fmt.Sprintf("Move %d replicas from pool %s to fallback pool %s", count, pool.Name, fallback),
+			})
+		} else if moved > 0 && count == 0 && pending[pool.Name] == 0 && now.Sub(lastUpdateTime.Time) >= returnWait {
+			o := &overflows[last]
+			o.Replicas--
+			o.LastUpdateTime = now
+			o.LastReturnTime = now.DeepCopy()
+			events = append(events, newReturnEvent(1, o.FallbackPool, o.Pool))
+		}
+	}
+
+	return overflows, events
+}
+
+// getReturnWait returns how long to wait after the last change of the overflow before returning a replica.
+func getReturnWait(o *unitv1alpha1.PoolOverflow, threshold time.Duration) time.Duration {
+	if backoff := time.Duration(o.ReturnBackoffSeconds) * time.Second; backoff > threshold {
+		return backoff
+	}
+	return threshold
+}
+
+// pickFallbackPool returns the first fallback pool which has no unschedulable pods,
+// or the first fallback pool if all of them have.
+func pickFallbackPool(fallbackPools []string, poolReplicas map[string]int32, unschedulable map[string]int32) string {
+	var picked string
+	for _, name := range fallbackPools {
+		if _, exist := poolReplicas[name]; !exist {
+			continue
+		}
+		if unschedulable[name] == 0 {
+			return name
+		}
+		if picked == "" {
+			picked = name
+		}
+	}
+	return picked
+}
+
+func addOverflow(overflows []unitv1alpha1.PoolOverflow, pool, fallback string, replicas int32,
+	now metav1.Time) []unitv1alpha1.PoolOverflow {
+
+	for i := range overflows {
+		if overflows[i].Pool == pool && overflows[i].FallbackPool == fallback {
+			overflows[i].Replicas += replicas
+			overflows[i].LastUpdateTime = now
+			return overflows
+		}
+	}
+	return append(overflows, unitv1alpha1.PoolOverflow{
+		Pool:           pool,
+		FallbackPool:   fallback,
+		Replicas:       replicas,
+		LastUpdateTime: now,
+	})
+}
+
+func newReturnEvent(replicas int32, fallback, pool string) placementEvent {
+	return placementEvent{
+		reason:  eventTypeReturnReplicas,
+		message: fmt.Sprintf("Return %d replicas from fallback pool %s to pool %s", replicas, fallback, pool),
+	}
+}
+
+// applyOverflows moves the overflow replicas from their pools to the fallback pools in the next patches.
+func applyOverflows(nextPatches map[string]YurtAppSetPatches, overflows []unitv1alpha1.PoolOverflow) {
+	for _, o := range overflows {
+		from, fromExist := nextPatches[o.Pool]
+		to, toExist := nextPatches[o.FallbackPool]
+		if !fromExist || !toExist {
+			continue
+		}
+		replicas := o.Replicas
+		if replicas > from.Replicas {
+			replicas = from.Replicas
+		}
+		from.Replicas -= replicas
+		to.Replicas += replicas
+		nextPatches[o.Pool] = from
+		nextPatches[o.FallbackPool] = to
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func newElasticYurtAppSet(overflows ...unitv1alpha1.PoolOverflow) *unitv1alpha1.YurtAppSet {
+	yas := newTestYurtAppSet(newTestPool("edge", 3), newTestPool("cloud", 1))
+	yas.Spec.ElasticPlacement = &unitv1alpha1.ElasticPlacementStrategy{
+		FallbackPools:                 []string{"cloud"},
+		UnschedulableThresholdSeconds: utilpointer.Int32Ptr(60),
+	}
+	yas.Status.OverflowReplicas = overflows
+	return yas
+}
+
+func TestCalculateOverflows(t *testing.T) {
+	now := metav1.Now()
+	longAgo := metav1.NewTime(now.Add(-time.Hour))
+	recently := metav1.NewTime(now.Add(-time.Second))
+
+	cases := []struct {
+		name          string
+		yas           *unitv1alpha1.YurtAppSet
+		unschedulable map[string]int32
+		pending       map[string]int32
+		expected      int32
+	}{
+		{
+			name:          "move unschedulable replicas to fallback pool",
+			yas:           newElasticYurtAppSet(),
+			unschedulable: map[string]int32{"edge": 2},
+			expected:      2,
+		},
+		{
+			name:          "never move more than the replicas of the pool",
+			yas:           newElasticYurtAppSet(),
+			unschedulable: map[string]int32{"edge": 5},
+			expected:      3,
+		},
+		{
+			name:          "fallback pool never overflows",
+			yas:           newElasticYurtAppSet(),
+			unschedulable: map[string]int32{"cloud": 1},
+			expected:      0,
+		},
+		{
+			name: "wait for the previous move to take effect",
+			yas: newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+				Pool: "edge", FallbackPool: "cloud", Replicas: 1, LastUpdateTime: recently}),
+			unschedulable: map[string]int32{"edge": 1},
+			expected:      1,
+		},
+		{
+			name: "move more replicas",
+			yas: newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+				Pool: "edge", FallbackPool: "cloud", Replicas: 1, LastUpdateTime: longAgo}),
+			unschedulable: map[string]int32{"edge": 1},
+			expected:      2,
+		},
+		{
+			name: "return one replica when the pool has capacity",
+			yas: newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+				Pool: "edge", FallbackPool: "cloud", Replicas: 2, LastUpdateTime: longAgo}),
+			expected: 1,
+		},
+		{
+			name: "never return replicas while the pool has pending pods",
+			yas: newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+				Pool: "edge", FallbackPool: "cloud", Replicas: 2, LastUpdateTime: longAgo}),
+			pending:  map[string]int32{"edge": 1},
+			expected: 2,
+		},
+		{
+			name: "wait for the backoff before returning replicas",
+			yas: newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+				Pool: "edge", FallbackPool: "cloud", Replicas: 2, LastUpdateTime: longAgo, ReturnBackoffSeconds: 7200}),
+			expected: 2,
+		},
+		{
+			name: "return all replicas when elastic placement is disabled",
+			yas: func() *unitv1alpha1.YurtAppSet {
+				yas := newElasticYurtAppSet(unitv1alpha1.PoolOverflow{
+					Pool: "edge", FallbackPool: "cloud", Replicas: 2, LastUpdateTime: recently})
+				yas.Spec.ElasticPlacement = nil
+				return yas
+			}(),
+			expected: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			overflows, _ := calculateOverflows(c.yas, c.unschedulable, c.pending, now)
+			var moved int32
+			for _, o := range overflows {
+				moved += o.Replicas
+			}
+			if moved != c.expected {
+				t.Fatalf("expected %d moved replicas, got %d: %v", c.expected, moved, overflows)
+			}
+
+			next := GetNextPatches(c.yas)
+			applyOverflows(next, overflows)
+			if next["edge"].Replicas != 3-c.expected || next["cloud"].Replicas != 1+c.expected {
+				t.Fatalf("unexpected next patches %v", next)
+			}
+		})
+	}
+}
+
+func TestCalculateOverflowsWithoutCapacity(t *testing.T) {
+	// the pool edge can never schedule more than 2 replicas, so the returned replica always stays unschedulable
+	yas := newElasticYurtAppSet()
+	threshold := 60 * time.Second
+	start := metav1.Now()
+	var returns []time.Duration
+	var unschedulableSince *metav1.Time
+	for elapsed := time.Duration(0); elapsed <= 2*time.Hour; elapsed += 10 * time.Second {
+		now := metav1.NewTime(start.Add(elapsed))
+		var moved int32
+		for _, o := range yas.Status.OverflowReplicas {
+			moved += o.Replicas
+		}
+		unschedulable := map[string]int32{}
+		pending := map[string]int32{}
+		if 3-moved > 2 {
+			if unschedulableSince == nil {
+				unschedulableSince = now.DeepCopy()
+			}
+			pending["edge"] = 3 - moved - 2
+			if now.Sub(unschedulableSince.Time) >= threshold {
+				unschedulable["edge"] = pending["edge"]
+			}
+		} else {
+			unschedulableSince = nil
+		}
+
+		overflows, events := calculateOverflows(yas, unschedulable, pending, now)
+		for _, event := range events {
+			if event.reason == eventTypeReturnReplicas {
+				returns = append(returns, elapsed)
+			}
+		}
+		yas.Status.OverflowReplicas = overflows
+	}
+
+	if len(returns) < 2 {
+		t.Fatalf("expected the replica to be returned more than once, got %v", returns)
+	}
+	for i := 2; i < len(returns); i++ {
+		if returns[i]-returns[i-1] <= returns[i-1]-returns[i-2] && returns[i]-returns[i-1] < maxReturnBackoff {
+			t.Fatalf("expected the returns to back off, got %v", returns)
+		}
+	}
+	if len(returns) > 8 {
+		t.Fatalf("expected the replicas not to flap, got %d returns: %v", len(returns), returns)
+	}
+}
+
+func TestGetPodUnschedulableCondition(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+			},
+		},
+	}
+	if getPodUnschedulableCondition(pod) == nil {
+		t.Fatalf("expected pod to be unschedulable")
+	}
+
+	pod.Status.Phase = corev1.PodRunning
+	if getPodUnschedulableCondition(pod) != nil {
+		t.Fatalf("expected running pod not to be unschedulable")
+	}
+}
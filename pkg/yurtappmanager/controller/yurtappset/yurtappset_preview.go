@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/preview"
+)
+
+// previewUID is the uid of the YurtAppSet which does not exist yet, so that the owner references
+// of its workloads are valid in the dry-run requests.
+const previewUID = types.UID("00000000-0000-0000-0000-000000000000")
+
+// Preview renders the workload of every pool of the YurtAppSet against the current workloads in the cluster,
+// and returns what would change. Nothing is written to the cluster: every write is sent in dry-run mode, so that
+// the rendered workloads are defaulted by the apiserver before they are compared.
+func Preview(c client.Client, scheme *runtime.Scheme, yas *unitv1alpha1.YurtAppSet) ([]preview.WorkloadPreview, error) {
+	dryRunClient := client.NewDryRunClient(c)
+	yas = yas.DeepCopy()
+	unitv1alpha1.SetDefaultsYurtAppSet(yas)
+
+	live := &unitv1alpha1.YurtAppSet{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: yas.Namespace, Name: yas.Name}, live)
+	switch {
+	case err == nil:
+		yas.UID = live.UID
+		yas.ResourceVersion = live.ResourceVersion
+		yas.Status = live.Status
+	case errors.IsNotFound(err):
+		yas.UID = previewUID
+	default:
+		return nil, err
+	}
+
+	r := &ReconcileYurtAppSet{
+		Client:       dryRunClient,
+		scheme:       scheme,
+		poolControls: newPoolControls(dryRunClient, scheme),
+	}
+	currentRevision, updatedRevision, _, err := r.constructYurtAppSetRevisions(yas)
+	if err != nil {
+		return nil, err
+	}
+	expectedRevision := currentRevision
+	if updatedRevision != nil {
+		expectedRevision = updatedRevision
+	}
+
+	control, poolType, err := r.getPoolControls(yas)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := control.GetAllPools(yas)
+	if err != nil {
+		return nil, err
+	}
+
+	nextPatches := GetNextPatches(yas)
+	applyOverflows(nextPatches, yas.Status.OverflowReplicas)
+	applyCompensations(nextPatches, yas.Status.Compensations)
+
+	var previews []preview.WorkloadPreview
+	nameToPools := r.classifyPoolByPoolName(pools)
+	for _, poolConfig := range yas.Spec.Topology.Pools {
+		var pool *Pool
+		if existing := nameToPools[poolConfig.Name]; len(existing) > 0 {
+			pool = existing[0]
+		}
+		previews = append(previews, r.previewPool(control, poolType, pool, yas, poolConfig.Name,
+			expectedRevision.Name, nextPatches[poolConfig.Name].Replicas))
+	}
+
+	// the pools which are not in the topology any more, the duplicated pools and the pools of the other types
+	// would be deleted
+	expected := map[string]bool{}
+	for _, poolConfig := range yas.Spec.Topology.Pools {
+		expected[poolConfig.Name] = true
+	}
+	var deletes []preview.WorkloadPreview
+	for name, pools := range nameToPools {
+		for i, pool := range pools {
+			if i > 0 || !expected[name] {
+				deletes = append(deletes, newDeletePreview(pool, poolType))
+			}
+		}
+	}
+	for t, other := range r.poolControls {
+		if t == poolType {
+			continue
+		}
+		otherPools, err := other.GetAllPools(yas)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range otherPools {
+			deletes = append(deletes, newDeletePreview(pool, t))
+		}
+	}
+	sort.Slice(deletes, func(i, j int) bool {
+		return deletes[i].Pool < deletes[j].Pool || deletes[i].Pool == deletes[j].Pool && deletes[i].Name < deletes[j].Name
+	})
+
+	return append(previews, deletes...), nil
+}
+
+// previewPool renders the workload of the pool and sends it to the apiserver in dry-run mode.
+func (r *ReconcileYurtAppSet) previewPool(control ControlInterface, poolType unitv1alpha1.TemplateType, pool *Pool,
+	yas *unitv1alpha1.YurtAppSet, poolName, revision string, replicas int32) preview.WorkloadPreview {
+
+	p := preview.WorkloadPreview{Pool: poolName, Kind: string(poolType), Action: preview.ActionCreate}
+	rendered, err := control.RenderPool(pool, yas, poolName, revision, replicas)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	var current runtime.Object
+	if pool == nil {
+		err = r.Client.Create(context.TODO(), rendered)
+	} else {
+		p.Action = preview.ActionUpdate
+		current = pool.Spec.PoolRef.(runtime.Object)
+		err = r.Client.Update(context.TODO(), rendered)
+	}
+	p.Name = rendered.GetName()
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	if p.Changes, err = preview.Diff(current, rendered); err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	if pool != nil && len(p.Changes) == 0 {
+		p.Action = preview.ActionUnchanged
+	}
+	return p
+}
+
+func newDeletePreview(pool *Pool, poolType unitv1alpha1.TemplateType) preview.WorkloadPreview {
+	return preview.WorkloadPreview{
+		Pool:   pool.Name,
+		Kind:   string(poolType),
+		Name:   pool.Spec.PoolRef.GetName(),
+		Action: preview.ActionDelete,
+	}
+}
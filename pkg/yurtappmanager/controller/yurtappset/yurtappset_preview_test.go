@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/preview"
+)
+
+func TestPreview(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = unitv1alpha1.AddToScheme(scheme)
+
+	live := newTestYurtAppSet(newTestPool("hangzhou", 2), newTestPool("beijing", 1))
+	unitv1alpha1.SetDefaultsYurtAppSet(live)
+	trueVar := true
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-hangzhou-abcde",
+			Namespace: "default",
+			Labels: map[string]string{
+				"app":                         "foo",
+				unitv1alpha1.PoolNameLabelKey: "hangzhou",
+				unitv1alpha1.ControllerRevisionHashLabelKey: "foo-old",
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: unitv1alpha1.GroupVersion.String(),
+				Kind:       "YurtAppSet",
+				Name:       "foo",
+				UID:        "uid",
+				Controller: &trueVar,
+			}},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: utilpointer.Int32Ptr(2),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.19"}},
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(live, deploy).Build()
+
+	desired := newTestYurtAppSet(newTestPool("hangzhou", 2), newTestPool("beijing", 1))
+	desired.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Template.Spec.Containers[0].Image = "nginx:1.20"
+	previews, err := Preview(c, scheme, desired)
+	if err != nil {
+		t.Fatalf("fail to preview: %v", err)
+	}
+	if len(previews) != 2 {
+		t.Fatalf("expected previews of 2 pools, got %v", previews)
+	}
+
+	hangzhou, beijing := previews[0], previews[1]
+	if hangzhou.Pool != "hangzhou" || hangzhou.Action != preview.ActionUpdate || hangzhou.Error != "" {
+		t.Fatalf("unexpected preview %v", hangzhou)
+	}
+	var imageChanged bool
+	for _, change := range hangzhou.Changes {
+		if change.Path == "spec.template.spec.containers" {
+			imageChanged = true
+		}
+	}
+	if !imageChanged {
+		t.Fatalf("expected the containers to change, got %v", hangzhou.Changes)
+	}
+	if beijing.Pool != "beijing" || beijing.Action != preview.ActionCreate || beijing.Error != "" {
+		t.Fatalf("unexpected preview %v", beijing)
+	}
+
+	// nothing is written to the cluster
+	deployList := &appsv1.DeploymentList{}
+	if err := c.List(context.TODO(), deployList); err != nil {
+		t.Fatalf("fail to list deployments: %v", err)
+	}
+	if len(deployList.Items) != 1 || deployList.Items[0].Spec.Template.Spec.Containers[0].Image != "nginx:1.19" {
+		t.Fatalf("expected deployments unchanged, got %v", deployList.Items)
+	}
+	revisionList := &appsv1.ControllerRevisionList{}
+	if err := c.List(context.TODO(), revisionList); err != nil {
+		t.Fatalf("fail to list revisions: %v", err)
+	}
+	if len(revisionList.Items) != 0 {
+		t.Fatalf("expected no controller revisions, got %d", len(revisionList.Items))
+	}
+}
+
+func TestYurtAppSetPatchIgnoresPaused(t *testing.T) {
+	yas := newTestYurtAppSet(newTestPool("hangzhou", 2))
+	want, err := getYurtAppSetPatch(yas)
+	if err != nil {
+		t.Fatalf("fail to get the patch: %v", err)
+	}
+	yas.Spec.WorkloadTemplate.DeploymentTemplate.Spec.Paused = true
+	got, err := getYurtAppSetPatch(yas)
+	if err != nil {
+		t.Fatalf("fail to get the patch: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("expected pausing not to change the revision patch, got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtappset/adapter"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
+)
+
+// managePoolServices makes sure every pool of the YurtAppSet has its own Service rendered from
+// the ServiceTemplate, and deletes the Services of the pools which are no longer expected.
+func (r *ReconcileYurtAppSet) managePoolServices(yas *unitv1alpha1.YurtAppSet) error {
+	svcList := &corev1.ServiceList{}
+	if err := r.Client.List(context.TODO(), svcList, client.InNamespace(yas.Namespace),
+		client.HasLabels{unitv1alpha1.PoolNameLabelKey}); err != nil {
+		return err
+	}
+
+	expected := map[string]string{}
+	if yas.Spec.ServiceTemplate != nil {
+		for _, pool := range yas.Spec.Topology.Pools {
+			expected[util.GetPoolServiceName(yas.Name, pool.Name)] = pool.Name
+		}
+	}
+
+	var errs []error
+	owned := map[string]*corev1.Service{}
+	for i := range svcList.Items {
+		svc := &svcList.Items[i]
+		if !metav1.IsControlledBy(svc, yas) {
+			continue
+		}
+		if _, ok := expected[svc.Name]; ok {
+			owned[svc.Name] = svc
+			continue
+		}
+
+		klog.Infof("YurtAppSet %s/%s deletes pool Service %s", yas.Namespace, yas.Name, svc.Name)
+		if err := r.Client.Delete(context.TODO(), svc); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("fail to delete pool Service %s/%s: %s", svc.Namespace, svc.Name, err))
+		}
+	}
+
+	for name, poolName := range expected {
+		svc, ok := owned[name]
+		if !ok {
+			svc = &corev1.Service{}
+			if err := adapter.ApplyPoolServiceTemplate(yas, poolName, svc, r.scheme); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			klog.Infof("YurtAppSet %s/%s creates Service %s for pool %s", yas.Namespace, yas.Name, name, poolName)
+			if err := r.Client.Create(context.TODO(), svc); err != nil {
+				errs = append(errs, fmt.Errorf("fail to create Service %s for pool %s: %s", name, poolName, err))
+			}
+			continue
+		}
+
+		updated := svc.DeepCopy()
+		if err := adapter.ApplyPoolServiceTemplate(yas, poolName, updated, r.scheme); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if apiequality.Semantic.DeepEqual(svc, updated) {
+			continue
+		}
+		klog.Infof("YurtAppSet %s/%s updates Service %s for pool %s", yas.Namespace, yas.Name, name, poolName)
+		if err := r.Client.Update(context.TODO(), updated); err != nil {
+			errs = append(errs, fmt.Errorf("fail to update Service %s for pool %s: %s", name, poolName, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
@@ -37,11 +37,11 @@ import (
 
 func (r *ReconcileYurtAppSet) managePools(yas *unitv1alpha1.YurtAppSet,
 	nameToPool map[string]*Pool, nextPatches map[string]YurtAppSetPatches,
-	expectedRevision *appsv1.ControllerRevision,
-	poolType unitv1alpha1.TemplateType) (newStatus *unitv1alpha1.YurtAppSetStatus, updateErr error) {
+	expectedRevision *appsv1.ControllerRevision, poolType unitv1alpha1.TemplateType,
+	control ControlInterface) (newStatus *unitv1alpha1.YurtAppSetStatus, updateErr error) {
 
 	newStatus = yas.Status.DeepCopy()
-	exists, provisioned, err := r.managePoolProvision(yas, nameToPool, nextPatches, expectedRevision, poolType)
+	exists, provisioned, err := r.managePoolProvision(yas, nameToPool, nextPatches, expectedRevision, poolType, control)
 	if err != nil {
 		SetYurtAppSetCondition(newStatus, NewYurtAppSetCondition(unitv1alpha1.PoolProvisioned, corev1.ConditionFalse, "Error", err.Error()))
 		return newStatus, fmt.Errorf("fail to manage Pool provision: %s", err)
@@ -54,9 +54,10 @@ func (r *ReconcileYurtAppSet) managePools(yas *unitv1alpha1.YurtAppSet,
 	var needUpdate []string
 	for _, name := range exists.List() {
 		pool := nameToPool[name]
-		if r.poolControls[poolType].IsExpected(pool, expectedRevision.Name) ||
+		if control.IsExpected(pool, expectedRevision.Name) ||
 			pool.Status.ReplicasInfo.Replicas != nextPatches[name].Replicas ||
-			pool.Status.PatchInfo != nextPatches[name].Patch {
+			pool.Status.PatchInfo != nextPatches[name].Patch ||
+			isPausedChanged(control, pool, yas, expectedRevision.Name, nextPatches[name].Replicas) {
 			needUpdate = append(needUpdate, name)
 		}
 	}
@@ -70,7 +71,7 @@ func (r *ReconcileYurtAppSet) managePools(yas *unitv1alpha1.YurtAppSet,
 			klog.Infof("YurtAppSet %s/%s needs to update Pool (%s) %s/%s with revision %s, replicas %d ",
 				yas.Namespace, yas.Name, poolType, pool.Namespace, pool.Name, expectedRevision.Name, replicas)
 
-			updatePoolErr := r.poolControls[poolType].UpdatePool(pool, yas, expectedRevision.Name, replicas)
+			updatePoolErr := control.UpdatePool(pool, yas, expectedRevision.Name, replicas)
 			if updatePoolErr != nil {
 				r.recorder.Event(yas.DeepCopy(), corev1.EventTypeWarning, fmt.Sprintf("Failed%s", eventTypePoolsUpdate), fmt.Sprintf("Error updating PodSet (%s) %s when updating: %s", poolType, pool.Name, updatePoolErr))
 			}
@@ -86,9 +87,26 @@ func (r *ReconcileYurtAppSet) managePools(yas *unitv1alpha1.YurtAppSet,
 	return
 }
 
+// isPausedChanged returns whether the Deployment of the pool is to be paused or resumed. The paused field of the
+// Deployment template is not a part of the revisions, so it is compared with the rendered Deployment of the pool.
+func isPausedChanged(control ControlInterface, pool *Pool, yas *unitv1alpha1.YurtAppSet, revision string, replicas int32) bool {
+	current, ok := pool.Spec.PoolRef.(*appsv1.Deployment)
+	if !ok {
+		return false
+	}
+	rendered, err := control.RenderPool(pool, yas, pool.Name, revision, replicas)
+	if err != nil {
+		klog.Errorf("YurtAppSet %s/%s fail to render Pool %s: %v", yas.Namespace, yas.Name, pool.Name, err)
+		return false
+	}
+	desired, ok := rendered.(*appsv1.Deployment)
+	return ok && desired.Spec.Paused != current.Spec.Paused
+}
+
 func (r *ReconcileYurtAppSet) managePoolProvision(yas *unitv1alpha1.YurtAppSet,
 	nameToPool map[string]*Pool, nextPatches map[string]YurtAppSetPatches,
-	expectedRevision *appsv1.ControllerRevision, workloadType unitv1alpha1.TemplateType) (sets.String, bool, error) {
+	expectedRevision *appsv1.ControllerRevision, workloadType unitv1alpha1.TemplateType,
+	workloadControl ControlInterface) (sets.String, bool, error) {
 	expectedPools := sets.String{}
 	gotPools := sets.String{}
 
@@ -137,7 +155,7 @@ func (r *ReconcileYurtAppSet) managePoolProvision(yas *unitv1alpha1.YurtAppSet,
 			poolName := createdPools[idx]
 
 			replicas := nextPatches[poolName].Replicas
-			err := r.poolControls[workloadType].CreatePool(yas, poolName, revision, replicas)
+			err := workloadControl.CreatePool(yas, poolName, revision, replicas)
 			if err != nil {
 				if !errors.IsTimeout(err) {
 					return fmt.Errorf("fail to create Pool (%s) %s: %s", workloadType, poolName, err.Error())
@@ -159,7 +177,7 @@ func (r *ReconcileYurtAppSet) managePoolProvision(yas *unitv1alpha1.YurtAppSet,
 		var deleteErrs []error
 		for _, poolName := range deletes {
 			pool := nameToPool[poolName]
-			if err := r.poolControls[workloadType].DeletePool(pool); err != nil {
+			if err := workloadControl.DeletePool(pool); err != nil {
 				deleteErrs = append(deleteErrs, fmt.Errorf("fail to delete Pool (%s) %s/%s for %s: %s", workloadType, pool.Namespace, pool.Name, poolName, err))
 			}
 		}
@@ -173,6 +191,7 @@ func (r *ReconcileYurtAppSet) managePoolProvision(yas *unitv1alpha1.YurtAppSet,
 
 	// clean the other kind of pools
 	// maybe user can chagne yas.Spec.WorkloadTemplate
+	// the custom workloads are not cleaned, because their kind is unknown once the template is changed
 	cleaned := false
 	for t, control := range r.poolControls {
 		if t == workloadType {
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtappset
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilpointer "k8s.io/utils/pointer"
+
+	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// newTestYurtAppSet returns the YurtAppSet default/foo, which deploys the pods labeled app=foo with an nginx:1.19
+// deployment template into the pools. The tests override the fields they care about.
+func newTestYurtAppSet(pools ...unitv1alpha1.Pool) *unitv1alpha1.YurtAppSet {
+	labels := map[string]string{"app": "foo"}
+	return &unitv1alpha1.YurtAppSet{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: unitv1alpha1.GroupVersion.String(),
+			Kind:       "YurtAppSet",
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", UID: "uid"},
+		Spec: unitv1alpha1.YurtAppSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			WorkloadTemplate: unitv1alpha1.WorkloadTemplate{
+				DeploymentTemplate: &unitv1alpha1.DeploymentTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: appsv1.DeploymentSpec{
+						Selector: &metav1.LabelSelector{MatchLabels: labels},
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{Labels: labels},
+							Spec: corev1.PodSpec{
+								Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.19"}},
+							},
+						},
+					},
+				},
+			},
+			Topology: unitv1alpha1.Topology{Pools: pools},
+		},
+	}
+}
+
+func newTestPool(name string, replicas int32) unitv1alpha1.Pool {
+	return unitv1alpha1.Pool{Name: name, Replicas: utilpointer.Int32Ptr(replicas)}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// Pool is the desired ingress controller of one nodepool.
+// Replicas, Image and Config are the effective values of the pool, with the per-pool overrides applied.
+// Namespace and NamePrefix are those of the YurtIngress, see Namespace.
+// TemplateVersion is the version of the templates of the ingress controller, see TemplateVersion.
+type Pool struct {
+	Name                string
+	Namespace           string
+	NamePrefix          string
+	IngressIPs          []string
+	Replicas            int32
+	Image               string
+	WebhookCertGenImage string
+	Resources           *corev1.ResourceRequirements
+	ExtraArgs           []string
+	NodeSelector        map[string]string
+	Tolerations         []corev1.Toleration
+	ServiceAnnotations  map[string]string
+	Service             *appsv1alpha1.IngressPoolService
+	Config              map[string]string
+	TemplateVersion     string
+}
+
+// IsHostNetwork returns whether the ingress controller of the pool runs with hostNetwork.
+func (p *Pool) IsHostNetwork() bool {
+	return p.Service != nil && p.Service.Type == corev1.ServiceTypeClusterIP
+}
+
+// Backend deploys one type of ingress controller into the nodepools.
+type Backend interface {
+	// IsCommonResourceReady returns whether the resources shared by all the pools are created and owned by ownerRef.
+	IsCommonResourceReady(c client.Client, ownerRef *metav1.OwnerReference) bool
+	// CreateCommonResource creates the resources shared by all the pools, such as the namespace and rbac, or adds
+	// ownerRef to their owners if they exist, since the YurtIngresses in the same namespace share them.
+	CreateCommonResource(c client.Client, ownerRef *metav1.OwnerReference) error
+	// DeleteCommonResource removes ownerRef from the owners of the resources shared by all the pools, and deletes them
+	// if no other YurtIngress owns them.
+	DeleteCommonResource(c client.Client, ownerRef *metav1.OwnerReference) error
+	// CreatePoolResource creates the ingress controller of the pool, the controller Deployment is owned by ownerRef.
+	CreatePoolResource(c client.Client, pool *Pool, ownerRef *metav1.OwnerReference) error
+	// DeletePoolResource deletes the ingress controller of the pool.
+	// If cleanup is true, the dependents of the resources are left to the garbage collector.
+	DeletePoolResource(c client.Client, pool *Pool, cleanup bool) error
+	// UpdateController updates the ingress controller of the pool to the image, replicas and overrides of the pool.
+	UpdateController(c client.Client, pool *Pool) error
+	// Scale updates the replicas of the ingress controller of the pool.
+	Scale(c client.Client, pool *Pool) error
+	// UpdateWebhookCertGenImage updates the webhook certificate generator of the pool, if the backend has one.
+	UpdateWebhookCertGenImage(c client.Client, pool *Pool) error
+	// EnsureWebhookCertificate generates or rotates the admission webhook certificate of the pool if it is managed by
+	// yurt-app-manager, and returns the time when it should be rotated next, zero if no certificate is managed.
+	EnsureWebhookCertificate(c client.Client, pool *Pool) (time.Time, error)
+	// UpdateService updates the type, ports, external ips and annotations of the ingress controller service of the pool.
+	UpdateService(c client.Client, pool *Pool) error
+	// GetEndpoints returns the addresses through which the ingress controller of the pool is accessed.
+	GetEndpoints(c client.Client, pool *Pool) ([]appsv1alpha1.IngressPoolEndpoint, error)
+	// IsPoolReady checks the ingress controller of the pool, and returns the reason if it is not ready.
+	// dply is the ingress controller Deployment of the pool, nil if it does not exist.
+	IsPoolReady(c client.Client, pool *Pool, dply *appsv1.Deployment) (bool, *appsv1alpha1.IngressNotReadyConditionInfo)
+}
+
+// The default namespaces of the ingress controller types.
+const (
+	nginxNamespace   = "ingress-nginx"
+	traefikNamespace = "ingress-traefik"
+)
+
+// defaultTemplateSets are the template sets used by the YurtIngresses without their own template sets.
+var defaultTemplateSets TemplateSets
+
+// SetDefaultTemplateSets sets the template sets used by the YurtIngresses without their own template sets,
+// the ingress controller types without template sets use the built-in templates.
+func SetDefaultTemplateSets(sets TemplateSets) {
+	defaultTemplateSets = sets
+}
+
+// New returns the backend of the ingress controller type of the YurtIngress.
+func New(c client.Client, ying *appsv1alpha1.YurtIngress) (Backend, error) {
+	namespace, namePrefix := Namespace(ying)
+	switch ying.Spec.ControllerType {
+	case "", appsv1alpha1.NginxIngressController:
+		templates, err := getTemplateSet(c, ying, appsv1alpha1.NginxIngressController)
+		if err != nil {
+			return nil, err
+		}
+		return &NginxBackend{Namespace: namespace, NamePrefix: namePrefix, Templates: templates}, nil
+	case appsv1alpha1.TraefikIngressController:
+		templates, err := getTemplateSet(c, ying, appsv1alpha1.TraefikIngressController)
+		if err != nil {
+			return nil, err
+		}
+		return &TraefikBackend{Namespace: namespace, NamePrefix: namePrefix, Templates: templates}, nil
+	case appsv1alpha1.TemplateIngressController:
+		if ying.Spec.ControllerTemplate == nil {
+			return nil, fmt.Errorf("controllerTemplate of YurtIngress %s is not set", ying.Name)
+		}
+		b, err := NewTemplateBackend(c, ying.Spec.ControllerTemplate)
+		if err != nil {
+			return nil, err
+		}
+		b.Namespace, b.NamePrefix = namespace, namePrefix
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown ingress controller type %s", ying.Spec.ControllerType)
+	}
+}
+
+// getTemplateSet returns the template set of the YurtIngress, or the default one of the ingress controller type.
+func getTemplateSet(c client.Client, ying *appsv1alpha1.YurtIngress, controllerType appsv1alpha1.IngressControllerType) (*TemplateSet, error) {
+	if ying.Spec.TemplateSet != nil {
+		return LoadTemplateSet(c, controllerType, ying.Spec.TemplateSet)
+	}
+	return defaultTemplateSets[controllerType], nil
+}
+
+// TemplateVersion returns the version of the templates of the ingress controllers of the YurtIngress,
+// empty for the built-in templates.
+func TemplateVersion(ying *appsv1alpha1.YurtIngress) string {
+	switch ying.Spec.ControllerType {
+	case "", appsv1alpha1.NginxIngressController, appsv1alpha1.TraefikIngressController:
+	default:
+		return ""
+	}
+	if ying.Spec.TemplateSet != nil {
+		return ying.Spec.TemplateSet.Version
+	}
+	controllerType := ying.Spec.ControllerType
+	if controllerType == "" {
+		controllerType = appsv1alpha1.NginxIngressController
+	}
+	if set := defaultTemplateSets[controllerType]; set != nil {
+		return set.Version
+	}
+	return ""
+}
+
+// Namespace returns the namespace of the ingress controllers of the YurtIngress, and the prefix of the names of
+// their cluster scoped resources. The prefix is empty in the default namespace of the controller type, so the
+// resources created before the namespace is configurable keep their names.
+func Namespace(ying *appsv1alpha1.YurtIngress) (namespace, namePrefix string) {
+	var defaultNamespace string
+	switch ying.Spec.ControllerType {
+	case "", appsv1alpha1.NginxIngressController:
+		defaultNamespace = nginxNamespace
+	case appsv1alpha1.TraefikIngressController:
+		defaultNamespace = traefikNamespace
+	}
+	namespace = ying.Spec.Namespace
+	if namespace == "" || namespace == defaultNamespace {
+		return defaultNamespace, ""
+	}
+	return namespace, namespace + "-"
+}
+
+func poolContext(pool *Pool) map[string]string {
+	return map[string]string{
+		"nodepool_name": pool.Name,
+		"namespace":     pool.Namespace,
+		"name_prefix":   pool.NamePrefix,
+	}
+}
+
+func commonContext(namespace, namePrefix, defaultNamespace string) map[string]string {
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return map[string]string{
+		"namespace":   namespace,
+		"name_prefix": namePrefix,
+	}
+}
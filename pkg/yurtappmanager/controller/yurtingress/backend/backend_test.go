@@ -0,0 +1,741 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+	addonfake "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon/fake"
+)
+
+const commonTemplate = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ingress-haproxy
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: haproxy
+  namespace: ingress-haproxy
+`
+
+const poolTemplate = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.nodepool_name}}-haproxy
+  namespace: ingress-haproxy
+  labels:
+    yurtingress.io/nodepool: {{.nodepool_name}}
+spec:
+  replicas: {{.replicas}}
+  selector:
+    matchLabels:
+      yurtingress.io/nodepool: {{.nodepool_name}}
+  template:
+    metadata:
+      labels:
+        yurtingress.io/nodepool: {{.nodepool_name}}
+    spec:
+      containers:
+      - name: haproxy
+        image: {{.image}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.nodepool_name}}-haproxy
+  namespace: ingress-haproxy
+spec:
+  externalIPs:
+{{- range .ingress_ips}}
+  - {{.}}
+{{- end}}
+  selector:
+    yurtingress.io/nodepool: {{.nodepool_name}}
+`
+
+func newTemplateClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "haproxy-templates"},
+		Data: map[string]string{
+			CommonTemplateKey: commonTemplate,
+			PoolTemplateKey:   poolTemplate,
+		},
+	}
+	return addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build())
+}
+
+func TestNew(t *testing.T) {
+	c := newTemplateClient()
+	tests := []struct {
+		spec    appsv1alpha1.YurtIngressSpec
+		backend Backend
+		wantErr bool
+	}{
+		{spec: appsv1alpha1.YurtIngressSpec{}, backend: &NginxBackend{}},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.NginxIngressController}, backend: &NginxBackend{}},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.TraefikIngressController}, backend: &TraefikBackend{}},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.TemplateIngressController}, wantErr: true},
+		{spec: appsv1alpha1.YurtIngressSpec{
+			ControllerType:     appsv1alpha1.TemplateIngressController,
+			ControllerTemplate: &appsv1alpha1.IngressControllerTemplate{Namespace: "kube-system", Name: "missing"},
+		}, wantErr: true},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: "unknown"}, wantErr: true},
+	}
+	for i, tt := range tests {
+		b, err := New(c, &appsv1alpha1.YurtIngress{Spec: tt.spec})
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("case %d: expected error, got backend %T", i, b)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+			continue
+		}
+		if reflect.TypeOf(b) != reflect.TypeOf(tt.backend) {
+			t.Errorf("case %d: expected backend %T, got %T", i, tt.backend, b)
+		}
+	}
+}
+
+func TestTemplateBackend(t *testing.T) {
+	c := newTemplateClient()
+	b, err := New(c, &appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{
+		ControllerType:     appsv1alpha1.TemplateIngressController,
+		ControllerTemplate: &appsv1alpha1.IngressControllerTemplate{Namespace: "kube-system", Name: "haproxy-templates"},
+	}})
+	if err != nil {
+		t.Fatalf("fail to get template backend: %v", err)
+	}
+
+	isController := true
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: "apps.openyurt.io/v1alpha1",
+		Kind:       "YurtIngress",
+		Name:       "ying",
+		UID:        "uid",
+		Controller: &isController,
+	}
+	if b.IsCommonResourceReady(c, ownerRef) {
+		t.Fatalf("expected common resources not ready before creation")
+	}
+	if err := b.CreateCommonResource(c, ownerRef); err != nil {
+		t.Fatalf("fail to create common resources: %v", err)
+	}
+	if !b.IsCommonResourceReady(c, ownerRef) {
+		t.Fatalf("expected common resources ready after creation")
+	}
+
+	pool := &Pool{Name: "hangzhou", IngressIPs: []string{"10.0.0.1"}, Replicas: 2, Image: "haproxy:2.4"}
+	if err := b.CreatePoolResource(c, pool, ownerRef); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+	dply := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-haproxy", Name: "hangzhou-haproxy"}, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if *dply.Spec.Replicas != 2 || dply.Spec.Template.Spec.Containers[0].Image != "haproxy:2.4" {
+		t.Fatalf("unexpected deployment spec %v", dply.Spec)
+	}
+	if len(dply.OwnerReferences) != 1 || dply.OwnerReferences[0].Name != "ying" {
+		t.Fatalf("unexpected owner references %v", dply.OwnerReferences)
+	}
+	svc := &corev1.Service{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-haproxy", Name: "hangzhou-haproxy"}, svc); err != nil {
+		t.Fatalf("fail to get the controller service: %v", err)
+	}
+	if len(svc.Spec.ExternalIPs) != 1 || svc.Spec.ExternalIPs[0] != "10.0.0.1" {
+		t.Fatalf("unexpected external ips %v", svc.Spec.ExternalIPs)
+	}
+
+	pool.Replicas = 3
+	pool.Image = "haproxy:2.5"
+	if err := b.UpdateController(c, pool); err != nil {
+		t.Fatalf("fail to update pool resources: %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-haproxy", Name: "hangzhou-haproxy"}, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if *dply.Spec.Replicas != 3 || dply.Spec.Template.Spec.Containers[0].Image != "haproxy:2.5" {
+		t.Fatalf("unexpected deployment spec %v after update", dply.Spec)
+	}
+	if len(dply.OwnerReferences) != 1 {
+		t.Fatalf("expected owner references to be kept, got %v", dply.OwnerReferences)
+	}
+
+	dply.Status.UpdatedReplicas = 3
+	dply.Status.AvailableReplicas = 3
+	if ready, info := b.IsPoolReady(c, pool, dply); ready || info.Reason != appsv1alpha1.IngressNoReadyEndpoints {
+		t.Fatalf("expected pool without endpoints not to be ready, got %v", info)
+	}
+	if err := c.Create(context.TODO(), newReadyEndpoints("ingress-haproxy", "hangzhou-haproxy")); err != nil {
+		t.Fatalf("fail to create endpoints: %v", err)
+	}
+	if ready, info := b.IsPoolReady(c, pool, dply); !ready {
+		t.Fatalf("expected pool to be ready, got %v", info)
+	}
+
+	// the resources removed from the templates are pruned
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "kube-system", Name: "haproxy-templates"}, cm); err != nil {
+		t.Fatalf("fail to get the templates: %v", err)
+	}
+	cm.Data[PoolTemplateKey] = poolTemplate[:strings.Index(poolTemplate, "---")]
+	if err := c.Update(context.TODO(), cm); err != nil {
+		t.Fatalf("fail to update the templates: %v", err)
+	}
+	pruned, err := New(c, &appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{
+		ControllerType:     appsv1alpha1.TemplateIngressController,
+		ControllerTemplate: &appsv1alpha1.IngressControllerTemplate{Namespace: "kube-system", Name: "haproxy-templates"},
+	}})
+	if err != nil {
+		t.Fatalf("fail to get template backend: %v", err)
+	}
+	if err := pruned.UpdateController(c, pool); err != nil {
+		t.Fatalf("fail to update pool resources: %v", err)
+	}
+	err = c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-haproxy", Name: "hangzhou-haproxy"}, &corev1.Service{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the controller service to be pruned, got %v", err)
+	}
+
+	if err := b.DeletePoolResource(c, pool, false); err != nil {
+		t.Fatalf("fail to delete pool resources: %v", err)
+	}
+	err = c.Get(context.TODO(), client.ObjectKey{Namespace: "kube-system", Name: "hangzhou-yurtingress-inventory"}, cm)
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the inventory to be deleted, got %v", err)
+	}
+	err = c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-haproxy", Name: "hangzhou-haproxy"}, &appsv1.Deployment{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the controller deployment to be deleted, got %v", err)
+	}
+	if err := b.DeleteCommonResource(c, ownerRef); err != nil {
+		t.Fatalf("fail to delete common resources: %v", err)
+	}
+	if b.IsCommonResourceReady(c, ownerRef) {
+		t.Fatalf("expected common resources deleted")
+	}
+}
+
+func TestTraefikIsPoolReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	b := &TraefikBackend{}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-traefik", Replicas: 2}
+
+	if ready, info := b.IsPoolReady(c, pool, nil); ready || info.Reason != appsv1alpha1.IngressControllerNotFound {
+		t.Fatalf("expected missing traefik to be not found, got %v", info)
+	}
+	dply := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "hangzhou-traefik", Generation: 2},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			AvailableReplicas:  2,
+			UpdatedReplicas:    2,
+		},
+	}
+	if ready, info := b.IsPoolReady(c, pool, dply); ready || info.Reason != appsv1alpha1.IngressControllerUpdating {
+		t.Fatalf("expected unobserved traefik to be updating, got %v", info)
+	}
+	dply.Status.ObservedGeneration = 2
+	dply.Status.UpdatedReplicas = 1
+	ready, info := b.IsPoolReady(c, pool, dply)
+	if ready || info.Type != appsv1alpha1.IngressPending || info.Reason != appsv1alpha1.IngressControllerUnavailable {
+		t.Fatalf("expected rolling traefik to be pending, got %v %v", ready, info)
+	}
+	dply.Status.Conditions = []appsv1.DeploymentCondition{{
+		Type:    appsv1.DeploymentReplicaFailure,
+		Status:  corev1.ConditionTrue,
+		Message: "exceeded quota",
+	}}
+	ready, info = b.IsPoolReady(c, pool, dply)
+	if ready || info.Type != appsv1alpha1.IngressFailure || info.Reason != appsv1alpha1.IngressReplicaFailure {
+		t.Fatalf("expected traefik with replica failure to fail, got %v %v", ready, info)
+	}
+	dply.Status.Conditions = nil
+	dply.Status.UpdatedReplicas = 2
+	if ready, info := b.IsPoolReady(c, pool, dply); ready || info.Reason != appsv1alpha1.IngressNoReadyEndpoints {
+		t.Fatalf("expected traefik without endpoints not to be ready, got %v", info)
+	}
+	if err := c.Create(context.TODO(), newReadyEndpoints("ingress-traefik", "hangzhou-traefik")); err != nil {
+		t.Fatalf("fail to create endpoints: %v", err)
+	}
+	if ready, info := b.IsPoolReady(c, pool, dply); !ready {
+		t.Fatalf("expected updated traefik to be ready, got %v", info)
+	}
+}
+
+func TestNginxIsPoolReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	replicas := int32(1)
+	webhook := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-webhook"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	createJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-create"},
+		Status:     batchv1.JobStatus{Succeeded: 1},
+	}
+	patchJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-patch"},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+			Type:    batchv1.JobFailed,
+			Status:  corev1.ConditionTrue,
+			Message: "BackoffLimitExceeded",
+		}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(webhook, createJob, patchJob,
+		newReadyEndpoints("ingress-nginx", "hangzhou-ingress-nginx-controller")).Build()
+	b := &NginxBackend{}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-nginx", Replicas: 1, WebhookCertGenImage: "certgen:v1"}
+	dply := &appsv1.Deployment{Status: appsv1.DeploymentStatus{AvailableReplicas: 1, UpdatedReplicas: 1}}
+
+	ready, info := b.IsPoolReady(c, pool, dply)
+	if ready || info.Type != appsv1alpha1.IngressFailure || info.Reason != appsv1alpha1.IngressCertGenFailed {
+		t.Fatalf("expected the failed certgen job to fail the pool, got %v", info)
+	}
+	patchJob.Status = batchv1.JobStatus{Succeeded: 1}
+	if err := c.Status().Update(context.TODO(), patchJob); err != nil {
+		t.Fatalf("fail to update job: %v", err)
+	}
+	if ready, info := b.IsPoolReady(c, pool, dply); ready || info.Reason != appsv1alpha1.IngressWebhookUnavailable {
+		t.Fatalf("expected the unavailable webhook to block the pool, got %v", info)
+	}
+	webhook.Status = appsv1.DeploymentStatus{AvailableReplicas: 1, UpdatedReplicas: 1}
+	if err := c.Status().Update(context.TODO(), webhook); err != nil {
+		t.Fatalf("fail to update webhook deployment: %v", err)
+	}
+	if ready, info := b.IsPoolReady(c, pool, dply); !ready {
+		t.Fatalf("expected the pool to be ready, got %v", info)
+	}
+}
+
+func newReadyEndpoints(namespace, name string) *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.244.0.10"}},
+			Ports:     []corev1.EndpointPort{{Name: "http", Port: 80}},
+		}},
+	}
+}
+
+func TestRenderControllerDeployment(t *testing.T) {
+	pool := &Pool{
+		Name:     "hangzhou",
+		Replicas: 3,
+		Image:    "nginx-ingress:v1",
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+		},
+		ExtraArgs: []string{"--enable-ssl-passthrough"},
+		NodeSelector: map[string]string{
+			"ingress":                   "true",
+			"apps.openyurt.io/nodepool": "beijing",
+		},
+		Tolerations: []corev1.Toleration{{Key: "edge", Operator: corev1.TolerationOpExists}},
+	}
+	dply, err := renderControllerDeployment(constant.NginxIngressControllerNodePoolDeployment, pool)
+	if err != nil {
+		t.Fatalf("fail to render deployment: %v", err)
+	}
+	if *dply.Spec.Replicas != 3 {
+		t.Fatalf("unexpected replicas %d", *dply.Spec.Replicas)
+	}
+	podSpec := dply.Spec.Template.Spec
+	container := podSpec.Containers[len(podSpec.Containers)-1]
+	if container.Image != "nginx-ingress:v1" {
+		t.Fatalf("unexpected image %s", container.Image)
+	}
+	if cpu := container.Resources.Limits[corev1.ResourceCPU]; cpu.String() != "500m" {
+		t.Fatalf("unexpected resources %v", container.Resources)
+	}
+	if args := container.Args; args[len(args)-1] != "--enable-ssl-passthrough" {
+		t.Fatalf("expected extra args to be appended, got %v", args)
+	}
+	if podSpec.NodeSelector["ingress"] != "true" || podSpec.NodeSelector["apps.openyurt.io/nodepool"] != "hangzhou" {
+		t.Fatalf("unexpected node selector %v", podSpec.NodeSelector)
+	}
+	if len(podSpec.Tolerations) != 2 || podSpec.Tolerations[1].Key != "edge" {
+		t.Fatalf("unexpected tolerations %v", podSpec.Tolerations)
+	}
+}
+
+func newReadyPod(name, node, hostIP, pool string, hostNetwork bool) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ingress-traefik",
+			Name:      name,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":     "traefik",
+				"app.kubernetes.io/instance": "traefik",
+				"yurtingress.io/nodepool":    pool,
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:    node,
+			HostNetwork: hostNetwork,
+			Containers: []corev1.Container{{
+				Name:  "traefik",
+				Ports: []corev1.ContainerPort{{Name: "web", ContainerPort: 8000}, {Name: "websecure", ContainerPort: 8443}},
+			}},
+		},
+		Status: corev1.PodStatus{
+			HostIP:     hostIP,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func newPoolNode(name, pool, internalIP string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"apps.openyurt.io/nodepool": pool}},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: internalIP},
+		}},
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newPoolNode("node1", "hangzhou", "192.168.0.1"),
+		newPoolNode("node2", "hangzhou", "192.168.0.2"),
+		newPoolNode("node3", "beijing", "192.168.1.1"),
+		newReadyPod("traefik-1", "node1", "192.168.0.1", "hangzhou", false),
+	).Build())
+	b := &TraefikBackend{}
+
+	pool := &Pool{
+		Name:       "hangzhou",
+		Namespace:  "ingress-traefik",
+		IngressIPs: []string{"10.0.0.1"},
+		Replicas:   1,
+		Service: &appsv1alpha1.IngressPoolService{
+			Type:                  corev1.ServiceTypeNodePort,
+			HTTPNodePort:          30080,
+			ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+		},
+	}
+	if err := b.CreatePoolResource(c, pool, nil); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+	ingressClass := &networkingv1.IngressClass{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "hangzhou-traefik"}, ingressClass); err != nil {
+		t.Fatalf("fail to get the ingress class of the pool: %v", err)
+	}
+	if ingressClass.Labels[appsv1alpha1.IngressNodePoolKey] != "hangzhou" {
+		t.Fatalf("expected the ingress class to be labeled with the pool, got %v", ingressClass.Labels)
+	}
+	endpoints, err := b.GetEndpoints(c, pool)
+	if err != nil {
+		t.Fatalf("fail to get endpoints: %v", err)
+	}
+	expected := []appsv1alpha1.IngressPoolEndpoint{
+		{Name: "web", Address: "10.0.0.1", Port: 80},
+		{Name: "websecure", Address: "10.0.0.1", Port: 443},
+		{Name: "web", Address: "192.168.0.1", Port: 30080},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Fatalf("expected endpoints %v, got %v", expected, endpoints)
+	}
+
+	// switch to hostNetwork
+	pool.IngressIPs = nil
+	pool.Service = &appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeClusterIP}
+	if err := b.UpdateService(c, pool); err != nil {
+		t.Fatalf("fail to update service: %v", err)
+	}
+	svc := &corev1.Service{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-traefik", Name: "hangzhou-traefik"}, svc); err != nil {
+		t.Fatalf("fail to get the controller service: %v", err)
+	}
+	if svc.Spec.Type != corev1.ServiceTypeClusterIP || svc.Spec.Ports[0].NodePort != 0 || svc.Spec.ExternalTrafficPolicy != "" {
+		t.Fatalf("unexpected service spec %v", svc.Spec)
+	}
+	dply, err := renderControllerDeployment(constant.TraefikIngressControllerNodePoolDeployment, pool)
+	if err != nil {
+		t.Fatalf("fail to render deployment: %v", err)
+	}
+	if !dply.Spec.Template.Spec.HostNetwork || dply.Spec.Template.Spec.DNSPolicy != corev1.DNSClusterFirstWithHostNet {
+		t.Fatalf("expected the controller to run with hostNetwork, got %v", dply.Spec.Template.Spec)
+	}
+
+	if err := c.Update(context.TODO(), newReadyPod("traefik-1", "node1", "192.168.0.1", "hangzhou", true)); err != nil {
+		t.Fatalf("fail to update pod: %v", err)
+	}
+	endpoints, err = b.GetEndpoints(c, pool)
+	if err != nil {
+		t.Fatalf("fail to get endpoints: %v", err)
+	}
+	expected = []appsv1alpha1.IngressPoolEndpoint{
+		{Name: "web", Address: "192.168.0.1", Port: 8000},
+		{Name: "websecure", Address: "192.168.0.1", Port: 8443},
+	}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Fatalf("expected endpoints %v, got %v", expected, endpoints)
+	}
+}
+
+func TestNginxPoolConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+	b := &NginxBackend{}
+
+	isController := true
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: "apps.openyurt.io/v1alpha1",
+		Kind:       "YurtIngress",
+		Name:       "ying",
+		UID:        "uid",
+		Controller: &isController,
+	}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-nginx", Replicas: 1, Config: map[string]string{"proxy-body-size": "8m"}}
+	if err := b.CreatePoolResource(c, pool, ownerRef); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+	cmKey := client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-controller"}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), cmKey, cm); err != nil {
+		t.Fatalf("fail to get the controller configmap: %v", err)
+	}
+	if cm.Data["proxy-body-size"] != "8m" || cm.Data["allow-snippet-annotations"] != "true" {
+		t.Fatalf("unexpected configmap data %v", cm.Data)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].Name != "ying" {
+		t.Fatalf("unexpected owner references %v", cm.OwnerReferences)
+	}
+	dply := &appsv1.Deployment{}
+	dplyKey := client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-controller"}
+	if err := c.Get(context.TODO(), dplyKey, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	hash := dply.Spec.Template.Annotations[configHashAnnotation]
+	if hash == "" {
+		t.Fatalf("expected the config hash annotation, got %v", dply.Spec.Template.Annotations)
+	}
+
+	pool.Config = map[string]string{"proxy-body-size": "16m"}
+	if err := b.UpdateController(c, pool); err != nil {
+		t.Fatalf("fail to update the controller: %v", err)
+	}
+	if err := c.Get(context.TODO(), cmKey, cm); err != nil {
+		t.Fatalf("fail to get the controller configmap: %v", err)
+	}
+	if cm.Data["proxy-body-size"] != "16m" {
+		t.Fatalf("unexpected configmap data %v after update", cm.Data)
+	}
+	if err := c.Get(context.TODO(), dplyKey, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if newHash := dply.Spec.Template.Annotations[configHashAnnotation]; newHash == "" || newHash == hash {
+		t.Fatalf("expected the config hash to change, got %s", newHash)
+	}
+
+	if err := b.DeletePoolResource(c, pool, false); err != nil {
+		t.Fatalf("fail to delete pool resources: %v", err)
+	}
+	if err := c.Get(context.TODO(), cmKey, cm); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the controller configmap to be deleted, got %v", err)
+	}
+}
+
+func TestNginxPoolInventory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+	b := &NginxBackend{}
+
+	isController := true
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: "apps.openyurt.io/v1alpha1",
+		Kind:       "YurtIngress",
+		Name:       "ying",
+		UID:        "uid",
+		Controller: &isController,
+	}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-nginx", Replicas: 1, Image: "nginx:v1",
+		WebhookCertGenImage: "certgen:v1"}
+	if err := b.CreatePoolResource(c, pool, ownerRef); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+	refs, err := addon.NewManager(c, fieldManager).Objects(context.TODO(), b.poolInventory(pool))
+	if err != nil || len(refs) != len(nginxPoolTemplates)+len(nginxJobTemplates) {
+		t.Fatalf("unexpected inventory %v, %v", refs, err)
+	}
+	for _, ref := range refs {
+		obj := ref.Object()
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Fatalf("fail to get %s: %v", ref, err)
+		}
+		if len(obj.GetOwnerReferences()) != 1 || obj.GetOwnerReferences()[0].Name != "ying" {
+			t.Fatalf("unexpected owner references of %s: %v", ref, obj.GetOwnerReferences())
+		}
+	}
+
+	// scaling does not roll out the image ahead of the revision of the pool
+	dplyKey := client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-controller"}
+	pool.Replicas, pool.Image = 2, "nginx:v2"
+	if err := b.Scale(c, pool); err != nil {
+		t.Fatalf("fail to scale: %v", err)
+	}
+	dply := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), dplyKey, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if *dply.Spec.Replicas != 2 || dply.Spec.Template.Spec.Containers[0].Image != "nginx:v1" {
+		t.Fatalf("expected only the replicas scaled, got %v", dply.Spec)
+	}
+
+	// the drift is corrected, and the certgen jobs are pruned once the certificate is managed by yurt-app-manager
+	dply.Spec.Template.Spec.Containers[0].Args = nil
+	if err := c.Update(context.TODO(), dply); err != nil {
+		t.Fatalf("fail to update the controller deployment: %v", err)
+	}
+	pool.WebhookCertGenImage = ""
+	if err := b.UpdateController(c, pool); err != nil {
+		t.Fatalf("fail to update the controller: %v", err)
+	}
+	if err := c.Get(context.TODO(), dplyKey, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if container := dply.Spec.Template.Spec.Containers[0]; container.Image != "nginx:v2" || len(container.Args) == 0 {
+		t.Fatalf("expected the controller deployment applied, got %v", container)
+	}
+	err = c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-create"},
+		&batchv1.Job{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the certgen job pruned, got %v", err)
+	}
+
+	if err := b.DeletePoolResource(c, pool, false); err != nil {
+		t.Fatalf("fail to delete pool resources: %v", err)
+	}
+	for _, ref := range refs {
+		obj := ref.Object()
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Fatalf("expected %s deleted, got %v", ref, err)
+		}
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		spec       appsv1alpha1.YurtIngressSpec
+		namespace  string
+		namePrefix string
+	}{
+		{spec: appsv1alpha1.YurtIngressSpec{}, namespace: "ingress-nginx"},
+		{spec: appsv1alpha1.YurtIngressSpec{Namespace: "ingress-nginx"}, namespace: "ingress-nginx"},
+		{spec: appsv1alpha1.YurtIngressSpec{Namespace: "edge"}, namespace: "edge", namePrefix: "edge-"},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.TraefikIngressController}, namespace: "ingress-traefik"},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.TraefikIngressController, Namespace: "edge"},
+			namespace: "edge", namePrefix: "edge-"},
+		{spec: appsv1alpha1.YurtIngressSpec{ControllerType: appsv1alpha1.TemplateIngressController}},
+	}
+	for i, tt := range tests {
+		namespace, namePrefix := Namespace(&appsv1alpha1.YurtIngress{Spec: tt.spec})
+		if namespace != tt.namespace || namePrefix != tt.namePrefix {
+			t.Errorf("case %d: expected %q and %q, got %q and %q", i, tt.namespace, tt.namePrefix, namespace, namePrefix)
+		}
+	}
+}
+
+func TestSharedCommonResource(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+	b := &NginxBackend{Namespace: "edge", NamePrefix: "edge-"}
+	newOwnerRef := func(name string) *metav1.OwnerReference {
+		isController := true
+		return &metav1.OwnerReference{
+			APIVersion: "apps.openyurt.io/v1alpha1",
+			Kind:       "YurtIngress",
+			Name:       name,
+			UID:        types.UID(name),
+			Controller: &isController,
+		}
+	}
+	ref1, ref2 := newOwnerRef("ying1"), newOwnerRef("ying2")
+
+	for _, ref := range []*metav1.OwnerReference{ref1, ref2} {
+		if err := b.CreateCommonResource(c, ref); err != nil {
+			t.Fatalf("fail to create common resources for %s: %v", ref.Name, err)
+		}
+		if !b.IsCommonResourceReady(c, ref) {
+			t.Fatalf("expected common resources ready for %s", ref.Name)
+		}
+	}
+	cr := &rbacv1.ClusterRole{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "edge-ingress-nginx"}, cr); err != nil {
+		t.Fatalf("fail to get the prefixed cluster role: %v", err)
+	}
+	if len(cr.OwnerReferences) != 2 || cr.OwnerReferences[0].Controller != nil {
+		t.Fatalf("expected the cluster role shared by both YurtIngresses, got %v", cr.OwnerReferences)
+	}
+	sa := &corev1.ServiceAccount{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "edge", Name: "ingress-nginx"}, sa); err != nil {
+		t.Fatalf("fail to get the service account in the namespace: %v", err)
+	}
+
+	if err := b.DeleteCommonResource(c, ref1); err != nil {
+		t.Fatalf("fail to release common resources: %v", err)
+	}
+	if b.IsCommonResourceReady(c, ref1) || !b.IsCommonResourceReady(c, ref2) {
+		t.Fatalf("expected common resources released by ying1 only")
+	}
+	if err := b.DeleteCommonResource(c, ref2); err != nil {
+		t.Fatalf("fail to release common resources: %v", err)
+	}
+	err := c.Get(context.TODO(), client.ObjectKey{Name: "edge"}, &corev1.Namespace{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the namespace deleted with the last owner, got %v", err)
+	}
+}
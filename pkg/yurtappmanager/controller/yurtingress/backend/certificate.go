@@ -0,0 +1,239 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/certificate"
+)
+
+const (
+	webhookCAValidity   = 10 * 365 * 24 * time.Hour
+	webhookCertValidity = 365 * 24 * time.Hour
+
+	// webhookCertHashAnnotation is set on the pod template of the admission webhook Deployment,
+	// so the webhook pods are restarted to serve the rotated certificate.
+	webhookCertHashAnnotation = "yurtingress.io/webhook-cert-hash"
+)
+
+// The keys of the serving certificate Secret, which are the same as the ones generated by kube-webhook-certgen,
+// so a pool can be switched between the certgen jobs and yurt-app-manager.
+const (
+	webhookSecretCAKey   = "ca"
+	webhookSecretCertKey = "cert"
+	webhookSecretKeyKey  = "key"
+)
+
+// webhookCertificate is the admission webhook of the ingress controller of a pool whose certificate is
+// managed by yurt-app-manager.
+type webhookCertificate struct {
+	// Secret is the Secret of the serving certificate mounted by the webhook pods.
+	Secret client.ObjectKey
+	// CASecret is the Secret of the CA which signs the serving certificate.
+	CASecret client.ObjectKey
+	// Service is the Service of the webhook, the serving certificate is issued for its dns names.
+	Service client.ObjectKey
+	// WebhookConfiguration is the name of the ValidatingWebhookConfiguration into which the CA is injected.
+	WebhookConfiguration string
+	// Deployment is the webhook Deployment which is restarted when the serving certificate is rotated.
+	Deployment client.ObjectKey
+}
+
+func (w *webhookCertificate) hosts() []string {
+	return []string{
+		w.Service.Name,
+		fmt.Sprintf("%s.%s", w.Service.Name, w.Service.Namespace),
+		fmt.Sprintf("%s.%s.svc", w.Service.Name, w.Service.Namespace),
+	}
+}
+
+// ensureWebhookCertificate generates the CA and the serving certificate of the webhook if they are missing or invalid,
+// rotates them when a third of their lifetime remains, and injects the CA into the ValidatingWebhookConfiguration.
+// It returns the time when the certificates should be rotated next.
+func ensureWebhookCertificate(cli client.Client, w *webhookCertificate, ownerRefs []metav1.OwnerReference) (time.Time, error) {
+	now := time.Now()
+	caSecret, ca, err := getKeyPairSecret(cli, w.CASecret, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if ca == nil || !ca.Cert.IsCA || now.After(certificate.RenewTime(ca.Cert)) {
+		klog.V(4).Infof("generate the webhook CA secret/%s", w.CASecret.Name)
+		if ca, err = certificate.NewCA(w.Service.Name+"-ca", webhookCAValidity); err != nil {
+			return time.Time{}, err
+		}
+		data := map[string][]byte{corev1.TLSCertKey: ca.CertPEM, corev1.TLSPrivateKeyKey: ca.KeyPEM}
+		if err := applySecret(cli, caSecret, w.CASecret, corev1.SecretTypeTLS, data, ownerRefs); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	secret, cert, err := getKeyPairSecret(cli, w.Secret, webhookSecretCertKey, webhookSecretKeyKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if cert == nil || !bytes.Equal(secret.Data[webhookSecretCAKey], ca.CertPEM) ||
+		certificate.VerifyServingCert(ca.Cert, cert.Cert, w.hosts(), now) != nil ||
+		now.After(certificate.RenewTime(cert.Cert)) {
+		klog.V(4).Infof("generate the webhook serving certificate secret/%s", w.Secret.Name)
+		if cert, err = certificate.NewServingCert(ca, w.Service.Name, w.hosts(), webhookCertValidity); err != nil {
+			return time.Time{}, err
+		}
+		data := map[string][]byte{
+			webhookSecretCAKey:   ca.CertPEM,
+			webhookSecretCertKey: cert.CertPEM,
+			webhookSecretKeyKey:  cert.KeyPEM,
+		}
+		if err := applySecret(cli, secret, w.Secret, corev1.SecretTypeOpaque, data, ownerRefs); err != nil {
+			return time.Time{}, err
+		}
+		// the webhook pods started before the secret exists pick up the certificate when they are running,
+		// the ones serving the old certificate need to be restarted
+		if secret != nil {
+			if err := restartWebhook(cli, w.Deployment, cert.CertPEM); err != nil {
+				return time.Time{}, err
+			}
+		}
+	}
+
+	if err := injectCABundle(cli, w.WebhookConfiguration, ca.CertPEM); err != nil {
+		return time.Time{}, err
+	}
+	renewTime := certificate.RenewTime(cert.Cert)
+	if caRenewTime := certificate.RenewTime(ca.Cert); caRenewTime.Before(renewTime) {
+		renewTime = caRenewTime
+	}
+	return renewTime, nil
+}
+
+// getKeyPairSecret returns the Secret and the key pair it holds. The key pair is nil if the Secret does not exist
+// or holds no valid key pair, and the Secret is nil if it does not exist.
+func getKeyPairSecret(cli client.Client, key client.ObjectKey, certKey, keyKey string) (*corev1.Secret, *certificate.KeyPair, error) {
+	secret := &corev1.Secret{}
+	if err := cli.Get(context.TODO(), key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("fail to get the secret/%s: %v", key.Name, err)
+	}
+	keyPair, err := certificate.ParseKeyPair(secret.Data[certKey], secret.Data[keyKey])
+	if err != nil {
+		klog.V(4).Infof("secret/%s holds no valid certificate: %v", key.Name, err)
+		return secret, nil, nil
+	}
+	return secret, keyPair, nil
+}
+
+// applySecret creates the Secret if it is nil, otherwise replaces its data.
+func applySecret(cli client.Client, secret *corev1.Secret, key client.ObjectKey, secretType corev1.SecretType,
+	data map[string][]byte, ownerRefs []metav1.OwnerReference) error {
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       key.Namespace,
+				Name:            key.Name,
+				OwnerReferences: ownerRefs,
+			},
+			Type: secretType,
+			Data: data,
+		}
+		if err := cli.Create(context.TODO(), secret); err != nil {
+			return fmt.Errorf("fail to create the secret/%s: %v", key.Name, err)
+		}
+		klog.V(4).Infof("secret/%s is created", key.Name)
+		return nil
+	}
+	secret.Data = data
+	if err := cli.Update(context.TODO(), secret); err != nil {
+		return fmt.Errorf("fail to update the secret/%s: %v", key.Name, err)
+	}
+	klog.V(4).Infof("secret/%s is updated", key.Name)
+	return nil
+}
+
+// injectCABundle sets the CA of all the webhooks of the ValidatingWebhookConfiguration.
+func injectCABundle(cli client.Client, name string, caBundle []byte) error {
+	vwc := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: name}, vwc); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("validatingwebhookconfiguration/%s is not found, skip injecting the CA", name)
+			return nil
+		}
+		return fmt.Errorf("fail to get the validatingwebhookconfiguration/%s: %v", name, err)
+	}
+	changed := false
+	for i := range vwc.Webhooks {
+		if !bytes.Equal(vwc.Webhooks[i].ClientConfig.CABundle, caBundle) {
+			vwc.Webhooks[i].ClientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := cli.Update(context.TODO(), vwc); err != nil {
+		return fmt.Errorf("fail to update the validatingwebhookconfiguration/%s: %v", name, err)
+	}
+	klog.V(4).Infof("the CA of validatingwebhookconfiguration/%s is injected", name)
+	return nil
+}
+
+// restartWebhook restarts the webhook pods by annotating their template with the hash of the certificate.
+func restartWebhook(cli client.Client, key client.ObjectKey, certPEM []byte) error {
+	dply := &appsv1.Deployment{}
+	if err := cli.Get(context.TODO(), key, dply); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("fail to get the deployment/%s: %v", key.Name, err)
+	}
+	h := fnv.New64a()
+	h.Write(certPEM)
+	if dply.Spec.Template.Annotations == nil {
+		dply.Spec.Template.Annotations = make(map[string]string)
+	}
+	dply.Spec.Template.Annotations[webhookCertHashAnnotation] = fmt.Sprintf("%x", h.Sum64())
+	if err := cli.Update(context.TODO(), dply); err != nil {
+		return fmt.Errorf("fail to update the deployment/%s: %v", key.Name, err)
+	}
+	klog.V(4).Infof("deployment/%s is restarted to serve the rotated certificate", key.Name)
+	return nil
+}
+
+// deleteWebhookCertificate deletes the Secrets of the CA and the serving certificate.
+func deleteWebhookCertificate(cli client.Client, w *webhookCertificate) error {
+	for _, key := range []client.ObjectKey{w.Secret, w.CASecret} {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		if err := cli.Delete(context.TODO(), secret); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("fail to delete the secret/%s: %v", key.Name, err)
+		}
+		klog.V(4).Infof("secret/%s is deleted", key.Name)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	addonfake "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon/fake"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/certificate"
+)
+
+func TestNginxWebhookCertificate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).Build())
+	b := &NginxBackend{}
+	isController := true
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: "apps.openyurt.io/v1alpha1",
+		Kind:       "YurtIngress",
+		Name:       "ying",
+		UID:        "uid",
+		Controller: &isController,
+	}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-nginx", Replicas: 1}
+	if err := b.CreatePoolResource(c, pool, ownerRef); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-create"},
+		&batchv1.Job{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("expected no certgen job, got %v", err)
+	}
+	secretKey := client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission"}
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), secretKey, secret); err != nil {
+		t.Fatalf("fail to get the webhook certificate: %v", err)
+	}
+	if len(secret.OwnerReferences) != 1 || secret.OwnerReferences[0].Name != "ying" {
+		t.Fatalf("unexpected owner references %v", secret.OwnerReferences)
+	}
+	cert, err := certificate.ParseKeyPair(secret.Data["cert"], secret.Data["key"])
+	if err != nil {
+		t.Fatalf("fail to parse the webhook certificate: %v", err)
+	}
+	caSecret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-ca"},
+		caSecret); err != nil {
+		t.Fatalf("fail to get the webhook CA: %v", err)
+	}
+	if !bytes.Equal(caSecret.Data[corev1.TLSCertKey], secret.Data["ca"]) {
+		t.Fatalf("expected the webhook certificate to hold its CA")
+	}
+	ca, err := certificate.ParseKeyPair(caSecret.Data[corev1.TLSCertKey], caSecret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		t.Fatalf("fail to parse the webhook CA: %v", err)
+	}
+	host := "hangzhou-ingress-nginx-controller-admission.ingress-nginx.svc"
+	if err := certificate.VerifyServingCert(ca.Cert, cert.Cert, []string{host}, time.Now()); err != nil {
+		t.Fatalf("expected the webhook certificate to be valid: %v", err)
+	}
+	vwc := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "hangzhou-ingress-nginx-admission"}, vwc); err != nil {
+		t.Fatalf("fail to get the validatingwebhookconfiguration: %v", err)
+	}
+	if !bytes.Equal(vwc.Webhooks[0].ClientConfig.CABundle, secret.Data["ca"]) {
+		t.Fatalf("expected the CA to be injected")
+	}
+
+	renewTime, err := b.EnsureWebhookCertificate(c, pool)
+	if err != nil {
+		t.Fatalf("fail to ensure the webhook certificate: %v", err)
+	}
+	if renewTime.Before(time.Now().Add(200 * 24 * time.Hour)) {
+		t.Fatalf("unexpected renew time %v", renewTime)
+	}
+	unchanged := &corev1.Secret{}
+	if err := c.Get(context.TODO(), secretKey, unchanged); err != nil {
+		t.Fatalf("fail to get the webhook certificate: %v", err)
+	}
+	if !bytes.Equal(unchanged.Data["cert"], secret.Data["cert"]) {
+		t.Fatalf("expected the valid webhook certificate not to be rotated")
+	}
+
+	// a certificate issued for other hosts is rotated, and the webhook is restarted to serve the new one
+	other, err := certificate.NewServingCert(ca, "other", []string{"other"}, time.Hour)
+	if err != nil {
+		t.Fatalf("fail to create certificate: %v", err)
+	}
+	unchanged.Data["cert"] = other.CertPEM
+	if err := c.Update(context.TODO(), unchanged); err != nil {
+		t.Fatalf("fail to update the webhook certificate: %v", err)
+	}
+	if _, err := b.EnsureWebhookCertificate(c, pool); err != nil {
+		t.Fatalf("fail to ensure the webhook certificate: %v", err)
+	}
+	rotated := &corev1.Secret{}
+	if err := c.Get(context.TODO(), secretKey, rotated); err != nil {
+		t.Fatalf("fail to get the webhook certificate: %v", err)
+	}
+	if bytes.Equal(rotated.Data["cert"], other.CertPEM) || !bytes.Equal(rotated.Data["ca"], secret.Data["ca"]) {
+		t.Fatalf("expected the webhook certificate to be rotated with the same CA")
+	}
+	webhook := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-nginx", Name: "hangzhou-ingress-nginx-admission-webhook"},
+		webhook); err != nil {
+		t.Fatalf("fail to get the webhook deployment: %v", err)
+	}
+	if webhook.Spec.Template.Annotations[webhookCertHashAnnotation] == "" {
+		t.Fatalf("expected the webhook to be restarted")
+	}
+
+	if err := b.DeletePoolResource(c, pool, false); err != nil {
+		t.Fatalf("fail to delete pool resources: %v", err)
+	}
+	if err := c.Get(context.TODO(), secretKey, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected the webhook certificate to be deleted, got %v", err)
+	}
+}
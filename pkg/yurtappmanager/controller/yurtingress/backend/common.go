@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+const yurtIngressKind = "YurtIngress"
+
+// sharedOwnerReference returns ownerRef as a non-controller owner, since a common resource is owned by all the
+// YurtIngresses in its namespace.
+func sharedOwnerReference(ownerRef *metav1.OwnerReference) metav1.OwnerReference {
+	ref := *ownerRef
+	ref.Controller = nil
+	ref.BlockOwnerDeletion = nil
+	return ref
+}
+
+func hasOwnerReference(refs []metav1.OwnerReference, ownerRef *metav1.OwnerReference) bool {
+	for _, ref := range refs {
+		if ref.UID == ownerRef.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// isCommonObjectsOwned returns whether all the common resources exist and are owned by ownerRef, and ownerRef is
+// among the owners of their inventory. A Namespace should not be terminating as well.
+func isCommonObjectsOwned(cli client.Client, inv addon.Inventory, objs []*unstructured.Unstructured, ownerRef *metav1.OwnerReference) bool {
+	if ownerRef != nil {
+		ownerRefs, err := addon.NewManager(cli, fieldManager).Owners(context.TODO(), inv)
+		if err != nil || !hasOwnerReference(ownerRefs, ownerRef) {
+			return false
+		}
+	}
+	for _, obj := range objs {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := cli.Get(context.TODO(), client.ObjectKeyFromObject(obj), existing); err != nil {
+			return false
+		}
+		if ownerRef != nil && !hasOwnerReference(existing.GetOwnerReferences(), ownerRef) {
+			return false
+		}
+		if obj.GetKind() == "Namespace" {
+			if phase, _, _ := unstructured.NestedString(existing.Object, "status", "phase"); phase == "Terminating" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ownCommonObjects applies the common resources owned by ownerRef together with the other owners of their
+// inventory, which are the other YurtIngresses in the namespace. The Namespaces are created ahead, since the
+// inventory is kept in the namespace of the ingress controllers.
+func ownCommonObjects(cli client.Client, inv addon.Inventory, objs []*unstructured.Unstructured, ownerRef *metav1.OwnerReference) error {
+	m := addon.NewManager(cli, fieldManager)
+	ownerRefs, err := m.Owners(context.TODO(), inv)
+	if err != nil {
+		return err
+	}
+	if ownerRef != nil && !hasOwnerReference(ownerRefs, ownerRef) {
+		ownerRefs = append(ownerRefs, sharedOwnerReference(ownerRef))
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "Namespace" {
+			continue
+		}
+		ns := obj.DeepCopy()
+		ns.SetOwnerReferences(ownerRefs)
+		if err := cli.Create(context.TODO(), ns); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("fail to create the namespace/%s: %v", ns.GetName(), err)
+		}
+	}
+	return m.Apply(context.TODO(), inv, objs, &addon.ApplyOptions{OwnerReferences: ownerRefs})
+}
+
+// releaseCommonObjects removes ownerRef from the owners of the common resources, and deletes them in the reverse
+// order of creation if no other YurtIngress owns them.
+func releaseCommonObjects(cli client.Client, inv addon.Inventory, objs []*unstructured.Unstructured, ownerRef *metav1.OwnerReference) error {
+	m := addon.NewManager(cli, fieldManager)
+	ownerRefs, err := m.Owners(context.TODO(), inv)
+	if err != nil {
+		return err
+	}
+	var remaining []metav1.OwnerReference
+	for _, ref := range ownerRefs {
+		if ownerRef != nil && ref.UID == ownerRef.UID {
+			continue
+		}
+		if ref.Kind == yurtIngressKind {
+			remaining = append(remaining, ref)
+		}
+	}
+	if len(remaining) > 0 {
+		return m.Apply(context.TODO(), inv, objs, &addon.ApplyOptions{OwnerReferences: remaining})
+	}
+	if err := m.Delete(context.TODO(), inv); err != nil {
+		return err
+	}
+	// the common resources created before the inventories are introduced are not recorded
+	return releaseUnrecordedObjects(cli, objs, ownerRef)
+}
+
+// releaseUnrecordedObjects removes ownerRef from the owners of the common resources in the reverse order of
+// creation, and deletes the ones which are not owned by any other YurtIngress.
+func releaseUnrecordedObjects(cli client.Client, objs []*unstructured.Unstructured, ownerRef *metav1.OwnerReference) error {
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := cli.Get(context.TODO(), client.ObjectKeyFromObject(obj), existing); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		var refs []metav1.OwnerReference
+		isShared := false
+		for _, ref := range existing.GetOwnerReferences() {
+			if ownerRef != nil && ref.UID == ownerRef.UID {
+				continue
+			}
+			if ref.Kind == yurtIngressKind {
+				isShared = true
+			}
+			refs = append(refs, ref)
+		}
+		if !isShared {
+			if err := cli.Delete(context.TODO(), existing); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("fail to delete the %s/%s: %v", strings.ToLower(obj.GetKind()), obj.GetName(), err)
+			}
+			klog.V(4).Infof("%s/%s is deleted", strings.ToLower(obj.GetKind()), obj.GetName())
+			continue
+		}
+		if len(refs) == len(existing.GetOwnerReferences()) {
+			continue
+		}
+		existing.SetOwnerReferences(refs)
+		if err := cli.Update(context.TODO(), existing); err != nil {
+			return fmt.Errorf("fail to release the %s/%s: %v", strings.ToLower(obj.GetKind()), obj.GetName(), err)
+		}
+		klog.V(4).Infof("%s/%s is released by %s %s", strings.ToLower(obj.GetKind()), obj.GetName(), ownerRef.Kind,
+			ownerRef.Name)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+const nodePoolLabel = "apps.openyurt.io/nodepool"
+
+// getServiceEndpoints returns the endpoints of the ingress controller Service of the pool:
+//   - the external ips with the service ports
+//   - the load balancer ingress addresses with the service ports for LoadBalancer services
+//   - the node addresses of the pool with the node ports for NodePort and LoadBalancer services,
+//     only the nodes running ready controller pods are used if the external traffic policy is Local
+//   - the host ips of the ready controller pods with the target ports for ClusterIP services,
+//     as the controller runs with hostNetwork then
+//
+// A Service that does not exist yet has no endpoints.
+func getServiceEndpoints(cli client.Client, svcKey client.ObjectKey, pool *Pool) ([]appsv1alpha1.IngressPoolEndpoint, error) {
+	svc := &corev1.Service{}
+	if err := cli.Get(context.TODO(), svcKey, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var endpoints []appsv1alpha1.IngressPoolEndpoint
+	for _, port := range svc.Spec.Ports {
+		for _, ip := range svc.Spec.ExternalIPs {
+			endpoints = append(endpoints, newEndpoint(port.Name, ip, port.Port))
+		}
+	}
+
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			addr := ingress.IP
+			if addr == "" {
+				addr = ingress.Hostname
+			}
+			for _, port := range svc.Spec.Ports {
+				endpoints = append(endpoints, newEndpoint(port.Name, addr, port.Port))
+			}
+		}
+	}
+
+	pods, err := getReadyPods(cli, svc)
+	if err != nil {
+		return nil, err
+	}
+
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		nodes := &corev1.NodeList{}
+		if err := cli.List(context.TODO(), nodes, client.MatchingLabels{nodePoolLabel: pool.Name}); err != nil {
+			return nil, err
+		}
+		podNodes := make(map[string]bool)
+		for _, pod := range pods {
+			podNodes[pod.Spec.NodeName] = true
+		}
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			if svc.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal && !podNodes[node.Name] {
+				continue
+			}
+			addr := getNodeAddress(node)
+			if addr == "" {
+				continue
+			}
+			for _, port := range svc.Spec.Ports {
+				if port.NodePort != 0 {
+					endpoints = append(endpoints, newEndpoint(port.Name, addr, port.NodePort))
+				}
+			}
+		}
+	case corev1.ServiceTypeClusterIP:
+		for i := range pods {
+			pod := &pods[i]
+			if !pod.Spec.HostNetwork || pod.Status.HostIP == "" {
+				continue
+			}
+			for _, port := range svc.Spec.Ports {
+				if targetPort := resolveTargetPort(pod, port); targetPort != 0 {
+					endpoints = append(endpoints, newEndpoint(port.Name, pod.Status.HostIP, targetPort))
+				}
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// getReadyPods lists the ready pods selected by the Service.
+func getReadyPods(cli client.Client, svc *corev1.Service) ([]corev1.Pod, error) {
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil
+	}
+	pods := &corev1.PodList{}
+	if err := cli.List(context.TODO(), pods, client.InNamespace(svc.Namespace),
+		client.MatchingLabels(svc.Spec.Selector)); err != nil {
+		return nil, err
+	}
+	var ready []corev1.Pod
+	for _, pod := range pods.Items {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodReady && cond.Status == corev1.ConditionTrue {
+				ready = append(ready, pod)
+				break
+			}
+		}
+	}
+	return ready, nil
+}
+
+// getNodeAddress prefers the external ip of the node, and falls back to the internal ip.
+func getNodeAddress(node *corev1.Node) string {
+	var internalIP string
+	for _, addr := range node.Status.Addresses {
+		switch addr.Type {
+		case corev1.NodeExternalIP:
+			return addr.Address
+		case corev1.NodeInternalIP:
+			if internalIP == "" {
+				internalIP = addr.Address
+			}
+		}
+	}
+	return internalIP
+}
+
+// resolveTargetPort returns the container port of the pod that the service port targets.
+func resolveTargetPort(pod *corev1.Pod, port corev1.ServicePort) int32 {
+	if port.TargetPort.IntValue() != 0 {
+		return int32(port.TargetPort.IntValue())
+	}
+	if port.TargetPort.StrVal == "" {
+		return port.Port
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, cp := range container.Ports {
+			if cp.Name == port.TargetPort.StrVal {
+				return cp.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+func newEndpoint(name, addr string, port int32) appsv1alpha1.IngressPoolEndpoint {
+	return appsv1alpha1.IngressPoolEndpoint{Name: name, Address: addr, Port: port}
+}
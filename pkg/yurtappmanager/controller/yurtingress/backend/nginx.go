@@ -0,0 +1,391 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+// NginxBackend deploys ingress-nginx, together with its admission webhook, in the pools.
+// The common resources are created in Namespace, ingress-nginx by default, and the names of the cluster scoped
+// resources are prefixed with NamePrefix.
+type NginxBackend struct {
+	Namespace  string
+	NamePrefix string
+	// Templates overrides the built-in templates, nil for the built-in templates.
+	Templates *TemplateSet
+}
+
+var _ Backend = &NginxBackend{}
+
+// nginxCommonTemplates are the names of the templates of the common resources of ingress-nginx in the order of creation.
+var nginxCommonTemplates = []string{
+	namespaceTemplate,
+	clusterRoleTemplate,
+	admissionClusterRoleTemplate,
+	clusterRoleBindingTemplate,
+	admissionClusterRoleBindingTemplate,
+	roleTemplate,
+	admissionRoleTemplate,
+	roleBindingTemplate,
+	admissionRoleBindingTemplate,
+	serviceAccountTemplate,
+	admissionServiceAccountTemplate,
+}
+
+// nginxPoolTemplates are the names of the templates of the resources of ingress-nginx of a pool in the order of
+// creation, the certgen jobs are created only if the webhook certgen image is set.
+var nginxPoolTemplates = []string{
+	configMapTemplate,
+	deploymentTemplate,
+	admissionDeploymentTemplate,
+	serviceTemplate,
+	admissionServiceTemplate,
+	admissionWebhookTemplate,
+	ingressClassTemplate,
+}
+
+// nginxJobTemplates are the names of the templates of the certgen jobs of a pool.
+var nginxJobTemplates = []string{
+	admissionCreateJobTemplate,
+	admissionPatchJobTemplate,
+}
+
+// IsCommonResourceReady returns whether the namespace and rbac of ingress-nginx are created and owned by ownerRef.
+func (b *NginxBackend) IsCommonResourceReady(cli client.Client, ownerRef *metav1.OwnerReference) bool {
+	objs, err := addon.Render(b.commonContext(), b.templates(nginxCommonTemplates)...)
+	if err != nil {
+		return false
+	}
+	return isCommonObjectsOwned(cli, b.commonInventory(), objs, ownerRef)
+}
+
+// CreateCommonResource applies the namespace and rbac of ingress-nginx owned by ownerRef.
+func (b *NginxBackend) CreateCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(b.commonContext(), b.templates(nginxCommonTemplates)...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := ownCommonObjects(cli, b.commonInventory(), objs, ownerRef); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteCommonResource releases the namespace and rbac of ingress-nginx from ownerRef, and deletes them if no other
+// YurtIngress owns them. The ConfigMap shared by all the pools before every pool has its own is deleted together
+// with the namespace.
+func (b *NginxBackend) DeleteCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(b.commonContext(), b.templates(nginxCommonTemplates)...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := releaseCommonObjects(cli, b.commonInventory(), objs, ownerRef); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// CreatePoolResource applies the ingress-nginx controller with its ConfigMap, admission webhook, the certgen jobs
+// and the IngressClass of the pool, which are all owned by ownerRef, and generates the webhook certificate if it is
+// managed by yurt-app-manager.
+func (b *NginxBackend) CreatePoolResource(cli client.Client, pool *Pool, ownerRef *metav1.OwnerReference) error {
+	var ownerRefs []metav1.OwnerReference
+	if ownerRef != nil {
+		ownerRefs = append(ownerRefs, *ownerRef)
+	}
+	if err := b.applyPoolResource(cli, pool, ownerRefs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if pool.WebhookCertGenImage != "" {
+		return nil
+	}
+	w, err := b.webhookCertificate(pool)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if _, err := ensureWebhookCertificate(cli, w, ownerRefs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePoolResource deletes the resources of the pool recorded in its inventory in the reverse order of creation,
+// the rendered ones, which are not recorded if the pool is created before the inventories are introduced,
+// and the webhook certificate of the pool.
+func (b *NginxBackend) DeletePoolResource(cli client.Client, pool *Pool, cleanup bool) error {
+	if err := addon.NewManager(cli, fieldManager).Delete(context.TODO(), b.poolInventory(pool)); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	objs, err := addon.Render(poolContext(pool), b.templates(append(append([]string{}, nginxPoolTemplates...), nginxJobTemplates...))...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := deleteObjects(cli, objs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	w, err := b.webhookCertificate(pool)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := deleteWebhookCertificate(cli, w); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateController applies the resources of the pool again, so the ingress-nginx controller is updated to the image,
+// replicas, config and overrides of the pool, the admission webhook to the image of the pool, their drift is
+// corrected and the resources no longer rendered are pruned.
+func (b *NginxBackend) UpdateController(cli client.Client, pool *Pool) error {
+	ownerRefs, err := getControllerOwnerReferences(cli, b.template(deploymentTemplate), pool)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := b.applyPoolResource(cli, pool, ownerRefs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// Scale updates the replicas of the ingress-nginx controller of the pool.
+func (b *NginxBackend) Scale(cli client.Client, pool *Pool) error {
+	if err := scaleControllerDeployment(cli, b.template(deploymentTemplate), pool); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateWebhookCertGenImage recreates the certgen jobs of the pool with the new image.
+// If the image is unset, the jobs are deleted and the webhook certificate is managed by yurt-app-manager.
+func (b *NginxBackend) UpdateWebhookCertGenImage(cli client.Client, pool *Pool) error {
+	jobs, err := renderBuiltinPoolObjects(pool, nginxJobTemplates, b.template)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	ownerRefs, err := getControllerOwnerReferences(cli, b.template(deploymentTemplate), pool)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := deleteObjects(cli, jobs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if pool.WebhookCertGenImage == "" {
+		w, err := b.webhookCertificate(pool)
+		if err != nil {
+			klog.Errorf("%v", err)
+			return err
+		}
+		if _, err := ensureWebhookCertificate(cli, w, ownerRefs); err != nil {
+			klog.Errorf("%v", err)
+			return err
+		}
+		return nil
+	}
+	// wait for the old jobs to be deleted, the jobs are recorded in the inventory when the pool is applied next time
+	time.Sleep(3 * time.Second)
+	for _, job := range jobs {
+		job.SetOwnerReferences(ownerRefs)
+		if err := cli.Create(context.TODO(), job); err != nil && !apierrors.IsAlreadyExists(err) {
+			klog.Errorf("fail to create the job/%s: %v", job.GetName(), err)
+			return err
+		}
+		klog.V(4).Infof("job/%s is created", job.GetName())
+	}
+	return nil
+}
+
+// EnsureWebhookCertificate rotates the admission webhook certificate of the pool if it is managed by yurt-app-manager,
+// that is the webhook certgen image is unset.
+func (b *NginxBackend) EnsureWebhookCertificate(cli client.Client, pool *Pool) (time.Time, error) {
+	if pool.WebhookCertGenImage != "" {
+		return time.Time{}, nil
+	}
+	w, err := b.webhookCertificate(pool)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ownerRefs, err := getControllerOwnerReferences(cli, b.template(deploymentTemplate), pool)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return ensureWebhookCertificate(cli, w, ownerRefs)
+}
+
+// applyPoolResource applies the resources of the pool owned by ownerRefs, or the owners of the inventory of the pool
+// if ownerRefs is nil, and prunes the ones no longer rendered. The certgen jobs are immutable, so they are only
+// created if they do not exist.
+func (b *NginxBackend) applyPoolResource(cli client.Client, pool *Pool, ownerRefs []metav1.OwnerReference) error {
+	names := nginxPoolTemplates
+	if pool.WebhookCertGenImage != "" {
+		names = append(append([]string{}, nginxPoolTemplates...), nginxJobTemplates...)
+	}
+	objs, err := renderBuiltinPoolObjects(pool, names, b.template)
+	if err != nil {
+		return err
+	}
+	return addon.NewManager(cli, fieldManager).Apply(context.TODO(), b.poolInventory(pool), objs,
+		&addon.ApplyOptions{OwnerReferences: ownerRefs, CreateOnly: isJob})
+}
+
+// poolInventory returns the inventory of the resources of the pool.
+func (b *NginxBackend) poolInventory(pool *Pool) addon.Inventory {
+	return addon.Inventory{Namespace: pool.Namespace, Name: pool.Name + "-ingress-nginx-inventory"}
+}
+
+// commonInventory returns the inventory of the common resources, which is kept in the namespace of ingress-nginx.
+func (b *NginxBackend) commonInventory() addon.Inventory {
+	return addon.Inventory{Namespace: b.commonContext()["namespace"], Name: "ingress-nginx-inventory"}
+}
+
+func (b *NginxBackend) commonContext() map[string]string {
+	return commonContext(b.Namespace, b.NamePrefix, nginxNamespace)
+}
+
+// webhookCertificate returns the admission webhook of ingress-nginx of the pool. The serving certificate Secret
+// is named after the ValidatingWebhookConfiguration, the same as the one generated by the certgen jobs.
+func (b *NginxBackend) webhookCertificate(pool *Pool) (*webhookCertificate, error) {
+	svc, err := renderObjectKey(b.template(admissionServiceTemplate), pool)
+	if err != nil {
+		return nil, err
+	}
+	vwc, err := renderObjectKey(b.template(admissionWebhookTemplate), pool)
+	if err != nil {
+		return nil, err
+	}
+	dply, err := renderObjectKey(b.template(admissionDeploymentTemplate), pool)
+	if err != nil {
+		return nil, err
+	}
+	return &webhookCertificate{
+		Secret:               client.ObjectKey{Namespace: svc.Namespace, Name: vwc.Name},
+		CASecret:             client.ObjectKey{Namespace: svc.Namespace, Name: vwc.Name + "-ca"},
+		Service:              svc,
+		WebhookConfiguration: vwc.Name,
+		Deployment:           dply,
+	}, nil
+}
+
+// UpdateService updates the type, ports, external ips and annotations of the ingress-nginx controller service of the pool.
+func (b *NginxBackend) UpdateService(cli client.Client, pool *Pool) error {
+	if err := updateControllerService(cli,
+		b.template(serviceTemplate),
+		pool); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// GetEndpoints returns the endpoints of the ingress-nginx controller service of the pool.
+func (b *NginxBackend) GetEndpoints(cli client.Client, pool *Pool) ([]appsv1alpha1.IngressPoolEndpoint, error) {
+	svc, err := renderControllerService(b.template(serviceTemplate), pool)
+	if err != nil {
+		return nil, err
+	}
+	return getServiceEndpoints(cli, client.ObjectKeyFromObject(svc), pool)
+}
+
+// IsPoolReady regards ingress-nginx of the pool ready when all the controller replicas are updated and available,
+// the webhook certificate is generated by yurt-app-manager or the certgen jobs, the admission webhook is available and the controller service has
+// ready endpoints.
+func (b *NginxBackend) IsPoolReady(cli client.Client, pool *Pool, dply *appsv1.Deployment) (bool, *appsv1alpha1.IngressNotReadyConditionInfo) {
+	if info := checkControllerDeployment(dply, pool.Replicas); info != nil {
+		return false, info
+	}
+	if info := b.checkWebhookCertificate(cli, pool); info != nil {
+		return false, info
+	}
+	key, err := renderObjectKey(b.template(admissionDeploymentTemplate), pool)
+	if err != nil {
+		return false, newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressWebhookUnavailable, err.Error())
+	}
+	if info := checkWebhookDeployment(cli, key); info != nil {
+		return false, info
+	}
+	key, err = renderObjectKey(b.template(serviceTemplate), pool)
+	if err != nil {
+		return false, newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressNoReadyEndpoints, err.Error())
+	}
+	if info := checkServiceEndpoints(cli, key, pool.Replicas); info != nil {
+		return false, info
+	}
+	return true, nil
+}
+
+// checkWebhookCertificate checks the webhook certificate generated by yurt-app-manager or the certgen jobs.
+func (b *NginxBackend) checkWebhookCertificate(cli client.Client, pool *Pool) *appsv1alpha1.IngressNotReadyConditionInfo {
+	if pool.WebhookCertGenImage == "" {
+		w, err := b.webhookCertificate(pool)
+		if err != nil {
+			return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending, err.Error())
+		}
+		return checkWebhookCertificate(cli, w.Secret)
+	}
+	for _, tmpl := range []string{b.template(admissionCreateJobTemplate), b.template(admissionPatchJobTemplate)} {
+		key, err := renderObjectKey(tmpl, pool)
+		if err != nil {
+			return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending, err.Error())
+		}
+		if info := checkCertGenJob(cli, key); info != nil {
+			return info
+		}
+	}
+	return nil
+}
+
+// template returns the template of the name of the template set of the backend, or the built-in one.
+func (b *NginxBackend) template(name string) string {
+	return getTemplate(b.Templates, appsv1alpha1.NginxIngressController, name)
+}
+
+// templates returns the templates of the names.
+func (b *NginxBackend) templates(names []string) []string {
+	tmpls := make([]string, 0, len(names))
+	for _, name := range names {
+		tmpls = append(tmpls, b.template(name))
+	}
+	return tmpls
+}
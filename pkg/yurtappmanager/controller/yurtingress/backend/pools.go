@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// DesiredPools returns the pools listed in the spec, followed by the NodePools selected by the pool selector
+// in the order of their names, which use the settings of the spec without overrides.
+func DesiredPools(ying *appsv1alpha1.YurtIngress, nodePools map[string]*appsv1alpha1.NodePool) ([]appsv1alpha1.IngressPool, error) {
+	pools := ying.Spec.Pools
+	if ying.Spec.PoolSelector == nil {
+		return pools, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ying.Spec.PoolSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poolSelector of YurtIngress %s: %v", ying.Name, err)
+	}
+	listed := make(map[string]bool, len(pools))
+	for _, pool := range pools {
+		listed[pool.Name] = true
+	}
+	var selected []string
+	for name, np := range nodePools {
+		if !listed[name] && selector.Matches(labels.Set(np.Labels)) {
+			selected = append(selected, name)
+		}
+	}
+	sort.Strings(selected)
+	pools = append([]appsv1alpha1.IngressPool{}, pools...)
+	for _, name := range selected {
+		pools = append(pools, appsv1alpha1.IngressPool{Name: name})
+	}
+	return pools, nil
+}
+
+// IsPoolListed returns whether the pool is listed in the spec of the YurtIngress rather than selected.
+func IsPoolListed(ying *appsv1alpha1.YurtIngress, poolname string) bool {
+	for _, pool := range ying.Spec.Pools {
+		if pool.Name == poolname {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSameControllers returns whether the two YurtIngresses deploy the same type of ingress controllers into the same
+// namespace, whose resources of a pool have the same names, so a pool is enabled by at most one of them.
+// The YurtIngresses of the template type are the same controllers only if they use the same controller template.
+func IsSameControllers(a, b *appsv1alpha1.YurtIngress) bool {
+	controllerType := func(ying *appsv1alpha1.YurtIngress) appsv1alpha1.IngressControllerType {
+		if ying.Spec.ControllerType == "" {
+			return appsv1alpha1.NginxIngressController
+		}
+		return ying.Spec.ControllerType
+	}
+	if controllerType(a) != controllerType(b) {
+		return false
+	}
+	if controllerType(a) == appsv1alpha1.TemplateIngressController {
+		if a.Spec.ControllerTemplate == nil || b.Spec.ControllerTemplate == nil ||
+			*a.Spec.ControllerTemplate != *b.Spec.ControllerTemplate {
+			return false
+		}
+	}
+	namespaceA, _ := Namespace(a)
+	namespaceB, _ := Namespace(b)
+	return namespaceA == namespaceB
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func TestDesiredPools(t *testing.T) {
+	edge := map[string]string{"ingress": "enabled"}
+	nodePools := map[string]*appsv1alpha1.NodePool{
+		"hangzhou": {ObjectMeta: metav1.ObjectMeta{Name: "hangzhou", Labels: edge}},
+		"beijing":  {ObjectMeta: metav1.ObjectMeta{Name: "beijing", Labels: edge}},
+		"shanghai": {ObjectMeta: metav1.ObjectMeta{Name: "shanghai"}},
+	}
+	replicas := int32(2)
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			Pools:        []appsv1alpha1.IngressPool{{Name: "hangzhou", Replicas: &replicas}, {Name: "shanghai"}},
+			PoolSelector: &metav1.LabelSelector{MatchLabels: edge},
+		},
+	}
+	pools, err := DesiredPools(ying, nodePools)
+	if err != nil {
+		t.Fatalf("fail to get desired pools: %v", err)
+	}
+	var names []string
+	for _, pool := range pools {
+		names = append(names, pool.Name)
+	}
+	if len(names) != 3 || names[0] != "hangzhou" || names[1] != "shanghai" || names[2] != "beijing" {
+		t.Fatalf("expected the listed pools followed by the selected ones, got %v", names)
+	}
+	if pools[0].Replicas == nil || *pools[0].Replicas != 2 {
+		t.Fatalf("expected the listed pool to keep its overrides")
+	}
+	if len(ying.Spec.Pools) != 2 {
+		t.Fatalf("expected spec.pools not to be changed, got %v", ying.Spec.Pools)
+	}
+
+	ying.Spec.PoolSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "ingress", Operator: "Unknown"}}}
+	if _, err := DesiredPools(ying, nodePools); err == nil {
+		t.Fatalf("expected an invalid pool selector to fail")
+	}
+}
+
+func TestIsSameControllers(t *testing.T) {
+	newYurtIngress := func(controllerType appsv1alpha1.IngressControllerType, namespace string) *appsv1alpha1.YurtIngress {
+		return &appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{ControllerType: controllerType, Namespace: namespace}}
+	}
+	newTemplateYurtIngress := func(namespace, template string) *appsv1alpha1.YurtIngress {
+		ying := newYurtIngress(appsv1alpha1.TemplateIngressController, namespace)
+		ying.Spec.ControllerTemplate = &appsv1alpha1.IngressControllerTemplate{Namespace: "kube-system", Name: template}
+		return ying
+	}
+	tests := []struct {
+		a, b     *appsv1alpha1.YurtIngress
+		expected bool
+	}{
+		{newYurtIngress("", ""), newYurtIngress(appsv1alpha1.NginxIngressController, nginxNamespace), true},
+		{newYurtIngress("", "tenant-a"), newYurtIngress("", "tenant-a"), true},
+		{newYurtIngress("", "tenant-a"), newYurtIngress("", "tenant-b"), false},
+		{newYurtIngress("", "tenant-a"), newYurtIngress(appsv1alpha1.TraefikIngressController, "tenant-a"), false},
+		{newTemplateYurtIngress("tenant-a", "haproxy"), newTemplateYurtIngress("tenant-a", "haproxy"), true},
+		{newTemplateYurtIngress("tenant-a", "haproxy"), newTemplateYurtIngress("tenant-a", "envoy"), false},
+		{newTemplateYurtIngress("tenant-a", "haproxy"), newTemplateYurtIngress("tenant-b", "haproxy"), false},
+	}
+	for i, test := range tests {
+		if got := IsSameControllers(test.a, test.b); got != test.expected {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+// The checks below return nil if the checked resource of the pool is ready, otherwise the reason why it is not.
+
+// checkControllerDeployment regards the ingress controller Deployment ready when it is observed to be updated
+// and all the replicas are updated and available.
+func checkControllerDeployment(dply *appsv1.Deployment, replicas int32) *appsv1alpha1.IngressNotReadyConditionInfo {
+	if dply == nil {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressControllerNotFound,
+			"the ingress controller deployment is not found")
+	}
+	return checkDeployment(dply, replicas, appsv1alpha1.IngressControllerUnavailable)
+}
+
+// checkWebhookDeployment regards the admission webhook Deployment of the ingress controller ready
+// when all its replicas are updated and available.
+func checkWebhookDeployment(cli client.Client, key client.ObjectKey) *appsv1alpha1.IngressNotReadyConditionInfo {
+	dply := &appsv1.Deployment{}
+	if err := cli.Get(context.TODO(), key, dply); err != nil {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressWebhookUnavailable,
+			fmt.Sprintf("fail to get deployment/%s: %v", key.Name, err))
+	}
+	replicas := int32(1)
+	if dply.Spec.Replicas != nil {
+		replicas = *dply.Spec.Replicas
+	}
+	return checkDeployment(dply, replicas, appsv1alpha1.IngressWebhookUnavailable)
+}
+
+func checkDeployment(dply *appsv1.Deployment, replicas int32, unavailableReason string) *appsv1alpha1.IngressNotReadyConditionInfo {
+	if dply.Status.ObservedGeneration < dply.Generation {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressControllerUpdating,
+			fmt.Sprintf("deployment/%s is being updated", dply.Name))
+	}
+	for _, cond := range dply.Status.Conditions {
+		switch {
+		case cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue:
+			info := newNotReadyInfo(appsv1alpha1.IngressFailure, appsv1alpha1.IngressReplicaFailure, cond.Message)
+			info.LastTransitionTime = cond.LastTransitionTime
+			return info
+		case cond.Type == appsv1.DeploymentProgressing && cond.Reason == "ProgressDeadlineExceeded":
+			info := newNotReadyInfo(appsv1alpha1.IngressFailure, unavailableReason, cond.Message)
+			info.LastTransitionTime = cond.LastTransitionTime
+			return info
+		}
+	}
+	if dply.Status.UpdatedReplicas < replicas || dply.Status.AvailableReplicas < replicas {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, unavailableReason,
+			fmt.Sprintf("%d of %d replicas of deployment/%s are updated and available",
+				minInt32(dply.Status.UpdatedReplicas, dply.Status.AvailableReplicas), replicas, dply.Name))
+	}
+	return nil
+}
+
+// checkServiceEndpoints regards the Service ready when it has ready endpoints. A Service of an ingress controller
+// scaled to zero is not expected to have any.
+func checkServiceEndpoints(cli client.Client, key client.ObjectKey, replicas int32) *appsv1alpha1.IngressNotReadyConditionInfo {
+	if replicas == 0 {
+		return nil
+	}
+	eps := &corev1.Endpoints{}
+	if err := cli.Get(context.TODO(), key, eps); err != nil {
+		if apierrors.IsNotFound(err) {
+			return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressNoReadyEndpoints,
+				fmt.Sprintf("service/%s has no ready endpoints", key.Name))
+		}
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressNoReadyEndpoints,
+			fmt.Sprintf("fail to get endpoints/%s: %v", key.Name, err))
+	}
+	for _, subset := range eps.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressNoReadyEndpoints,
+		fmt.Sprintf("service/%s has no ready endpoints", key.Name))
+}
+
+// checkCertGenJob regards the webhook certificate generated when the Job succeeds.
+func checkCertGenJob(cli client.Client, key client.ObjectKey) *appsv1alpha1.IngressNotReadyConditionInfo {
+	job := &batchv1.Job{}
+	if err := cli.Get(context.TODO(), key, job); err != nil {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending,
+			fmt.Sprintf("fail to get job/%s: %v", key.Name, err))
+	}
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			info := newNotReadyInfo(appsv1alpha1.IngressFailure, appsv1alpha1.IngressCertGenFailed,
+				fmt.Sprintf("job/%s failed: %s", key.Name, cond.Message))
+			info.LastTransitionTime = cond.LastTransitionTime
+			return info
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending,
+			fmt.Sprintf("job/%s is not completed", key.Name))
+	}
+	return nil
+}
+
+// checkWebhookCertificate regards the webhook certificate generated by yurt-app-manager when its Secret exists.
+func checkWebhookCertificate(cli client.Client, key client.ObjectKey) *appsv1alpha1.IngressNotReadyConditionInfo {
+	secret := &corev1.Secret{}
+	if err := cli.Get(context.TODO(), key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending,
+				fmt.Sprintf("secret/%s is not generated", key.Name))
+		}
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending,
+			fmt.Sprintf("fail to get secret/%s: %v", key.Name, err))
+	}
+	if len(secret.Data[webhookSecretCertKey]) == 0 {
+		return newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressCertGenPending,
+			fmt.Sprintf("secret/%s has no certificate", key.Name))
+	}
+	return nil
+}
+
+// renderObjectKey returns the key of the object rendered from the yaml template for the pool.
+func renderObjectKey(tmpl string, pool *Pool) (client.ObjectKey, error) {
+	objs, err := addon.Render(poolContext(pool), tmpl)
+	if err != nil {
+		return client.ObjectKey{}, err
+	}
+	if len(objs) != 1 {
+		return client.ObjectKey{}, fmt.Errorf("template should render one object, got %d", len(objs))
+	}
+	return client.ObjectKeyFromObject(objs[0]), nil
+}
+
+func newNotReadyInfo(typ appsv1alpha1.IngressNotReadyType, reason, message string) *appsv1alpha1.IngressNotReadyConditionInfo {
+	return &appsv1alpha1.IngressNotReadyConditionInfo{
+		Type:    typ,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+func minInt32(a, b int32) int32 {
+	if a < b {
+		return a
+	}
+	return b
+}
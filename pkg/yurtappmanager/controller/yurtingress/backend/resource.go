@@ -0,0 +1,327 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+// configHashAnnotation is the pod template annotation of the ingress controller, whose change restarts the
+// ingress controller when its configuration changes.
+const configHashAnnotation = "yurtingress.io/config-hash"
+
+// renderBuiltinPoolObjects renders the resources of the pool from the built-in templates of the names in order,
+// each of which renders one object. The replicas, overrides and configuration of the pool are applied to the
+// ingress controller Deployment and ConfigMap, the external ips and service settings of the pool to the ingress
+// controller Service, the image of the pool to the admission webhook Deployment, which runs one replica, and the
+// webhook certgen image to the certgen Jobs.
+func renderBuiltinPoolObjects(pool *Pool, names []string, template func(name string) string) ([]*unstructured.Unstructured, error) {
+	objs := make([]*unstructured.Unstructured, 0, len(names))
+	for _, name := range names {
+		rendered, err := addon.Render(poolContext(pool), template(name))
+		if err != nil {
+			return nil, err
+		}
+		if len(rendered) != 1 {
+			return nil, fmt.Errorf("template %s should render one object, got %d", name, len(rendered))
+		}
+		obj := rendered[0]
+		switch name {
+		case deploymentTemplate:
+			dply := &appsv1.Deployment{}
+			err = convertObject(obj, dply, func() { applyControllerDeployment(dply, pool) })
+		case admissionDeploymentTemplate:
+			dply := &appsv1.Deployment{}
+			err = convertObject(obj, dply, func() {
+				var replicas int32 = 1
+				dply.Spec.Replicas = &replicas
+				setLastContainerImage(&dply.Spec.Template.Spec, pool.Image)
+			})
+		case serviceTemplate:
+			svc := &corev1.Service{}
+			err = convertObject(obj, svc, func() {
+				svc.Spec.ExternalIPs = pool.IngressIPs
+				applyPoolService(svc, pool)
+			})
+		case configMapTemplate:
+			cm := &corev1.ConfigMap{}
+			err = convertObject(obj, cm, func() {
+				if len(pool.Config) > 0 && cm.Data == nil {
+					cm.Data = map[string]string{}
+				}
+				for k, v := range pool.Config {
+					cm.Data[k] = v
+				}
+			})
+		case admissionCreateJobTemplate, admissionPatchJobTemplate:
+			job := &batchv1.Job{}
+			err = convertObject(obj, job, func() { setLastContainerImage(&job.Spec.Template.Spec, pool.WebhookCertGenImage) })
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fail to render template %s: %v", name, err)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// convertObject converts the rendered object to typed, updates it with update and converts it back.
+func convertObject(obj *unstructured.Unstructured, typed interface{}, update func()) error {
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return err
+	}
+	update()
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(typed)
+	if err != nil {
+		return err
+	}
+	obj.Object = content
+	return nil
+}
+
+// renderObject renders the only object of the template for the pool into the typed obj.
+func renderObject(tmpl string, pool *Pool, obj interface{}) error {
+	objs, err := addon.Render(poolContext(pool), tmpl)
+	if err != nil {
+		return err
+	}
+	if len(objs) != 1 {
+		return fmt.Errorf("template should render one object, got %d", len(objs))
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(objs[0].Object, obj)
+}
+
+// renderControllerDeployment renders the ingress controller Deployment of the pool, with the overrides of the pool.
+func renderControllerDeployment(dplyTmpl string, pool *Pool) (*appsv1.Deployment, error) {
+	dply := &appsv1.Deployment{}
+	if err := renderObject(dplyTmpl, pool, dply); err != nil {
+		return nil, err
+	}
+	applyControllerDeployment(dply, pool)
+	return dply, nil
+}
+
+// applyControllerDeployment sets the replicas, overrides and configuration hash of the pool to the ingress
+// controller Deployment.
+func applyControllerDeployment(dply *appsv1.Deployment, pool *Pool) {
+	replicas := pool.Replicas
+	dply.Spec.Replicas = &replicas
+	applyPoolOverrides(&dply.Spec.Template.Spec, pool)
+	applyPoolConfigHash(&dply.Spec.Template, pool)
+}
+
+func setLastContainerImage(podSpec *corev1.PodSpec, image string) {
+	if image != "" && len(podSpec.Containers) > 0 {
+		podSpec.Containers[len(podSpec.Containers)-1].Image = image
+	}
+}
+
+// applyPoolConfigHash annotates the pod with the hash of the configuration of the pool.
+func applyPoolConfigHash(podTemplate *corev1.PodTemplateSpec, pool *Pool) {
+	if len(pool.Config) == 0 {
+		return
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[configHashAnnotation] = configHash(pool.Config)
+}
+
+func configHash(config map[string]string) string {
+	keys := make([]string, 0, len(config))
+	for k := range config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	hasher := fnv.New64a()
+	for _, k := range keys {
+		hasher.Write([]byte(k))
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(config[k]))
+		hasher.Write([]byte{0})
+	}
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
+
+// applyPoolOverrides sets the image, resources and extra args of the pool to the ingress controller container,
+// which is the last container of the pod, and adds the node selector and tolerations of the pool to the pod.
+// The pod runs with hostNetwork if the ingress controller of the pool is exposed by a ClusterIP service.
+func applyPoolOverrides(podSpec *corev1.PodSpec, pool *Pool) {
+	if pool.IsHostNetwork() {
+		podSpec.HostNetwork = true
+		podSpec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+	if len(podSpec.Containers) == 0 {
+		return
+	}
+	container := &podSpec.Containers[len(podSpec.Containers)-1]
+	if pool.Image != "" {
+		container.Image = pool.Image
+	}
+	if pool.Resources != nil {
+		container.Resources = *pool.Resources.DeepCopy()
+	}
+	container.Args = append(container.Args, pool.ExtraArgs...)
+
+	if len(pool.NodeSelector) > 0 && podSpec.NodeSelector == nil {
+		podSpec.NodeSelector = map[string]string{}
+	}
+	for k, v := range pool.NodeSelector {
+		// the node selector of the pool itself can not be overridden
+		if _, ok := podSpec.NodeSelector[k]; !ok {
+			podSpec.NodeSelector[k] = v
+		}
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, pool.Tolerations...)
+}
+
+// scaleControllerDeployment updates the replicas of the ingress controller Deployment of the pool only, so that
+// the pod is not rolled out ahead of the revision of the pool.
+func scaleControllerDeployment(cli client.Client, dplyTmpl string, pool *Pool) error {
+	key, err := renderObjectKey(dplyTmpl, pool)
+	if err != nil {
+		return err
+	}
+	dply := &appsv1.Deployment{}
+	if err := cli.Get(context.TODO(), key, dply); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("deployment/%s is not found", key.Name)
+			return nil
+		}
+		return fmt.Errorf("fail to get the deployment/%s: %v", key.Name, err)
+	}
+	patch := client.MergeFrom(dply.DeepCopy())
+	replicas := pool.Replicas
+	dply.Spec.Replicas = &replicas
+	if err := cli.Patch(context.TODO(), dply, patch); err != nil {
+		return fmt.Errorf("fail to scale the deployment/%s: %v", dply.Name, err)
+	}
+	klog.V(4).Infof("deployment/%s is scaled to %d", dply.Name, replicas)
+	return nil
+}
+
+// getControllerOwnerReferences returns the owner references of the existing ingress controller Deployment of the pool.
+func getControllerOwnerReferences(cli client.Client, dplyTmpl string, pool *Pool) ([]metav1.OwnerReference, error) {
+	desired, err := renderControllerDeployment(dplyTmpl, pool)
+	if err != nil {
+		return nil, err
+	}
+	dply := &appsv1.Deployment{}
+	err = cli.Get(context.Background(), client.ObjectKeyFromObject(desired), dply)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to get the deployment/%s: %v", desired.Name, err)
+	}
+	return dply.GetOwnerReferences(), nil
+}
+
+// updateControllerService updates the type, ports, external ips and annotations of the ingress controller Service
+// of the pool. The annotations are merged into the existing ones, so that the annotations set by others are kept.
+func updateControllerService(cli client.Client, svcTmpl string, pool *Pool) error {
+	desired, err := renderControllerService(svcTmpl, pool)
+	if err != nil {
+		return err
+	}
+	svc := &corev1.Service{}
+	if cli.Get(context.Background(), client.ObjectKey{Namespace: desired.Namespace, Name: desired.Name}, svc) != nil {
+		klog.V(4).Infof("get service/%s failed", desired.Name)
+		return nil
+	}
+	// start from the type and traffic policy of the template, so that the settings removed from the pool are reverted
+	svc.Spec.Type = desired.Spec.Type
+	svc.Spec.ExternalTrafficPolicy = desired.Spec.ExternalTrafficPolicy
+	svc.Spec.ExternalIPs = pool.IngressIPs
+	applyPoolService(svc, pool)
+	err = cli.Update(context.Background(), svc)
+	if err != nil {
+		return fmt.Errorf("fail to update the service/%s: %v", svc.Name, err)
+	}
+	klog.V(4).Infof("service/%s is updated", svc.Name)
+	return nil
+}
+
+func renderControllerService(svcTmpl string, pool *Pool) (*corev1.Service, error) {
+	svc := &corev1.Service{}
+	if err := renderObject(svcTmpl, pool, svc); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// applyPoolService sets the annotations, type, node ports and external traffic policy of the pool to the ingress
+// controller Service. The fixed node ports are set to the service ports 80 and 443.
+func applyPoolService(svc *corev1.Service, pool *Pool) {
+	svc.Annotations = mergeAnnotations(svc.Annotations, pool.ServiceAnnotations)
+	if pool.Service == nil {
+		return
+	}
+
+	if pool.Service.Type != "" {
+		svc.Spec.Type = pool.Service.Type
+	}
+	if svc.Spec.Type == corev1.ServiceTypeClusterIP {
+		// the node ports and external traffic policy are not allowed by ClusterIP services
+		for i := range svc.Spec.Ports {
+			svc.Spec.Ports[i].NodePort = 0
+		}
+		svc.Spec.ExternalTrafficPolicy = ""
+		return
+	}
+	for i := range svc.Spec.Ports {
+		port := &svc.Spec.Ports[i]
+		if port.Port == 80 && pool.Service.HTTPNodePort != 0 {
+			port.NodePort = pool.Service.HTTPNodePort
+		}
+		if port.Port == 443 && pool.Service.HTTPSNodePort != 0 {
+			port.NodePort = pool.Service.HTTPSNodePort
+		}
+	}
+	if pool.Service.ExternalTrafficPolicy != "" {
+		svc.Spec.ExternalTrafficPolicy = pool.Service.ExternalTrafficPolicy
+	}
+}
+
+func mergeAnnotations(annotations, added map[string]string) map[string]string {
+	if len(added) == 0 {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range added {
+		annotations[k] = v
+	}
+	return annotations
+}
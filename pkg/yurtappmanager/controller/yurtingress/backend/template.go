@@ -0,0 +1,334 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+const (
+	ingressDeploymentLabel = "yurtingress.io/nodepool"
+
+	// CommonTemplateKey is the key of the templates of the resources shared by all the pools.
+	CommonTemplateKey = "common.yaml"
+	// PoolTemplateKey is the key of the templates of the resources of every pool.
+	PoolTemplateKey = "pool.yaml"
+
+	// fieldManager is the field manager of the resources applied by yurt-app-manager.
+	fieldManager = "yurt-app-manager"
+)
+
+// TemplateBackend deploys the ingress controller from the templates in a configmap.
+// The pool templates are rendered with nodepool_name, replicas, image, webhook_certgen_image,
+// ingress_ips and config of the pool. The controller Deployment of the pool must be labeled with
+// yurtingress.io/nodepool: {{.nodepool_name}}, so that the readiness of the pool can be checked and the per-pool
+// overrides can be applied to it. The service annotations and settings of the pool are applied to all the Services
+// of the pool. Both the common and the pool templates are rendered with namespace and name_prefix as well, which are
+// the namespace of the YurtIngress and the prefix for the names of the cluster scoped resources.
+// The resources of a pool are applied with server-side apply and recorded in an inventory ConfigMap of the pool,
+// so their drift is corrected on update and the resources removed from the templates are pruned.
+type TemplateBackend struct {
+	Namespace  string
+	NamePrefix string
+
+	commonTemplate string
+	poolTemplate   string
+	// templateNamespace is the namespace of the template configmap, where the inventories are kept unless
+	// the namespace of the YurtIngress is set.
+	templateNamespace string
+	// templateName is the name of the template configmap, which names the inventory of the common resources.
+	templateName string
+}
+
+var _ Backend = &TemplateBackend{}
+
+// NewTemplateBackend loads the templates from the configmap.
+func NewTemplateBackend(c client.Client, ref *appsv1alpha1.IngressControllerTemplate) (*TemplateBackend, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("fail to get ingress controller template configmap %s/%s: %v", ref.Namespace, ref.Name, err)
+	}
+	poolTemplate, ok := cm.Data[PoolTemplateKey]
+	if !ok {
+		return nil, fmt.Errorf("ingress controller template configmap %s/%s has no %s", ref.Namespace, ref.Name,
+			PoolTemplateKey)
+	}
+	return &TemplateBackend{
+		commonTemplate:    cm.Data[CommonTemplateKey],
+		poolTemplate:      poolTemplate,
+		templateNamespace: ref.Namespace,
+		templateName:      ref.Name,
+	}, nil
+}
+
+// IsCommonResourceReady returns whether all the common resources exist and are owned by ownerRef.
+func (b *TemplateBackend) IsCommonResourceReady(cli client.Client, ownerRef *metav1.OwnerReference) bool {
+	objs, err := addon.Render(commonContext(b.Namespace, b.NamePrefix, ""), b.commonTemplate)
+	if err != nil {
+		return false
+	}
+	return isCommonObjectsOwned(cli, b.commonInventory(), objs, ownerRef)
+}
+
+// CreateCommonResource applies the common resources owned by ownerRef.
+func (b *TemplateBackend) CreateCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(commonContext(b.Namespace, b.NamePrefix, ""), b.commonTemplate)
+	if err != nil {
+		return err
+	}
+	return ownCommonObjects(cli, b.commonInventory(), objs, ownerRef)
+}
+
+// DeleteCommonResource releases the common resources from ownerRef in the reverse order of creation, and deletes
+// the ones which are not owned by any other YurtIngress.
+func (b *TemplateBackend) DeleteCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(commonContext(b.Namespace, b.NamePrefix, ""), b.commonTemplate)
+	if err != nil {
+		return err
+	}
+	return releaseCommonObjects(cli, b.commonInventory(), objs, ownerRef)
+}
+
+// CreatePoolResource applies the resources of the pool, which are all owned by ownerRef.
+func (b *TemplateBackend) CreatePoolResource(cli client.Client, pool *Pool, ownerRef *metav1.OwnerReference) error {
+	objs, err := b.renderPoolObjects(pool)
+	if err != nil {
+		return err
+	}
+	var ownerRefs []metav1.OwnerReference
+	if ownerRef != nil {
+		ownerRefs = append(ownerRefs, *ownerRef)
+	}
+	return addon.NewManager(cli, fieldManager).Apply(context.TODO(), b.poolInventory(pool), objs,
+		&addon.ApplyOptions{OwnerReferences: ownerRefs, CreateOnly: isJob})
+}
+
+// DeletePoolResource deletes the resources of the pool recorded in its inventory in the reverse order of creation,
+// and the rendered ones, which are not recorded if the pool is created before the inventories are introduced.
+func (b *TemplateBackend) DeletePoolResource(cli client.Client, pool *Pool, cleanup bool) error {
+	if err := addon.NewManager(cli, fieldManager).Delete(context.TODO(), b.poolInventory(pool)); err != nil {
+		return err
+	}
+	objs, err := addon.Render(templatePoolContext(pool), b.poolTemplate)
+	if err != nil {
+		return err
+	}
+	return deleteObjects(cli, objs)
+}
+
+// UpdateController renders the templates of the pool again and updates the resources of the pool.
+func (b *TemplateBackend) UpdateController(cli client.Client, pool *Pool) error {
+	return b.updatePoolResource(cli, pool, false)
+}
+
+// Scale renders the templates of the pool again and updates the resources of the pool.
+func (b *TemplateBackend) Scale(cli client.Client, pool *Pool) error {
+	return b.updatePoolResource(cli, pool, false)
+}
+
+// UpdateWebhookCertGenImage renders the templates of the pool again, updates the resources and recreates the jobs
+// of the pool.
+func (b *TemplateBackend) UpdateWebhookCertGenImage(cli client.Client, pool *Pool) error {
+	return b.updatePoolResource(cli, pool, true)
+}
+
+// EnsureWebhookCertificate does nothing, the templates bring the webhook certificates of their own.
+func (b *TemplateBackend) EnsureWebhookCertificate(cli client.Client, pool *Pool) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// UpdateService renders the templates of the pool again and updates the resources of the pool.
+func (b *TemplateBackend) UpdateService(cli client.Client, pool *Pool) error {
+	return b.updatePoolResource(cli, pool, false)
+}
+
+// GetEndpoints returns the endpoints of all the Services of the pool.
+func (b *TemplateBackend) GetEndpoints(cli client.Client, pool *Pool) ([]appsv1alpha1.IngressPoolEndpoint, error) {
+	objs, err := addon.Render(templatePoolContext(pool), b.poolTemplate)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []appsv1alpha1.IngressPoolEndpoint
+	for _, obj := range objs {
+		if obj.GetKind() != "Service" {
+			continue
+		}
+		eps, err := getServiceEndpoints(cli, client.ObjectKeyFromObject(obj), pool)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, eps...)
+	}
+	return endpoints, nil
+}
+
+// IsPoolReady regards the pool ready when all the replicas of the controller Deployment are updated and available,
+// all the Jobs of the pool succeed and all the Services of the pool with selectors have ready endpoints.
+func (b *TemplateBackend) IsPoolReady(cli client.Client, pool *Pool, dply *appsv1.Deployment) (bool, *appsv1alpha1.IngressNotReadyConditionInfo) {
+	if info := checkControllerDeployment(dply, pool.Replicas); info != nil {
+		return false, info
+	}
+	objs, err := b.renderPoolObjects(pool)
+	if err != nil {
+		return false, newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressControllerUnavailable, err.Error())
+	}
+	for _, obj := range objs {
+		if obj.GetKind() == "Job" {
+			if info := checkCertGenJob(cli, client.ObjectKeyFromObject(obj)); info != nil {
+				return false, info
+			}
+		}
+	}
+	for _, obj := range objs {
+		if obj.GetKind() != "Service" {
+			continue
+		}
+		selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+		if len(selector) == 0 {
+			continue
+		}
+		if info := checkServiceEndpoints(cli, client.ObjectKeyFromObject(obj), pool.Replicas); info != nil {
+			return false, info
+		}
+	}
+	return true, nil
+}
+
+// updatePoolResource applies the rendered resources of the pool, and prunes the ones removed from the templates.
+// The jobs are immutable, so they are left as they are unless recreateJobs is true.
+func (b *TemplateBackend) updatePoolResource(cli client.Client, pool *Pool, recreateJobs bool) error {
+	objs, err := b.renderPoolObjects(pool)
+	if err != nil {
+		return err
+	}
+	if recreateJobs {
+		for _, obj := range objs {
+			if !isJob(obj) {
+				continue
+			}
+			existing := &unstructured.Unstructured{}
+			existing.SetGroupVersionKind(obj.GroupVersionKind())
+			err := cli.Get(context.TODO(), client.ObjectKeyFromObject(obj), existing)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			policy := metav1.DeletePropagationBackground
+			if err := cli.Delete(context.TODO(), existing, &client.DeleteOptions{PropagationPolicy: &policy}); err != nil &&
+				!apierrors.IsNotFound(err) {
+				return fmt.Errorf("fail to delete the job/%s: %v", obj.GetName(), err)
+			}
+			obj.SetOwnerReferences(existing.GetOwnerReferences())
+			klog.V(4).Infof("job/%s is deleted to be recreated", obj.GetName())
+		}
+	}
+	return addon.NewManager(cli, fieldManager).Apply(context.TODO(), b.poolInventory(pool), objs,
+		&addon.ApplyOptions{CreateOnly: isJob})
+}
+
+// poolInventory returns the inventory of the resources of the pool.
+func (b *TemplateBackend) poolInventory(pool *Pool) addon.Inventory {
+	namespace := pool.Namespace
+	if namespace == "" {
+		namespace = b.templateNamespace
+	}
+	return addon.Inventory{Namespace: namespace, Name: pool.Name + "-yurtingress-inventory"}
+}
+
+// commonInventory returns the inventory of the common resources, which is named after the template configmap.
+func (b *TemplateBackend) commonInventory() addon.Inventory {
+	namespace := b.Namespace
+	if namespace == "" {
+		namespace = b.templateNamespace
+	}
+	return addon.Inventory{Namespace: namespace, Name: b.templateName + "-inventory"}
+}
+
+func isJob(obj *unstructured.Unstructured) bool {
+	return obj.GetKind() == "Job"
+}
+
+// renderPoolObjects renders the resources of the pool, and applies the overrides of the pool to the controller
+// Deployment, which is labeled with the pool name, and the annotations and service settings of the pool to the
+// Services.
+func (b *TemplateBackend) renderPoolObjects(pool *Pool) ([]*unstructured.Unstructured, error) {
+	objs, err := addon.Render(templatePoolContext(pool), b.poolTemplate)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objs {
+		switch {
+		case obj.GetKind() == "Deployment" && obj.GetLabels()[ingressDeploymentLabel] == pool.Name:
+			dply := &appsv1.Deployment{}
+			err = convertObject(obj, dply, func() {
+				applyPoolOverrides(&dply.Spec.Template.Spec, pool)
+				applyPoolConfigHash(&dply.Spec.Template, pool)
+			})
+		case obj.GetKind() == "Service":
+			svc := &corev1.Service{}
+			err = convertObject(obj, svc, func() { applyPoolService(svc, pool) })
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+func templatePoolContext(pool *Pool) map[string]interface{} {
+	return map[string]interface{}{
+		"nodepool_name":         pool.Name,
+		"replicas":              pool.Replicas,
+		"image":                 pool.Image,
+		"webhook_certgen_image": pool.WebhookCertGenImage,
+		"ingress_ips":           pool.IngressIPs,
+		"config":                pool.Config,
+		"namespace":             pool.Namespace,
+		"name_prefix":           pool.NamePrefix,
+	}
+}
+
+// deleteObjects deletes the objects in the reverse order, their dependents are deleted by the garbage collector.
+func deleteObjects(cli client.Client, objs []*unstructured.Unstructured) error {
+	policy := metav1.DeletePropagationBackground
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		if err := cli.Delete(context.TODO(), obj, &client.DeleteOptions{PropagationPolicy: &policy}); err != nil &&
+			!apierrors.IsNotFound(err) {
+			return fmt.Errorf("fail to delete the %s/%s: %v", strings.ToLower(obj.GetKind()), obj.GetName(), err)
+		}
+		klog.V(4).Infof("%s/%s is deleted", strings.ToLower(obj.GetKind()), obj.GetName())
+	}
+	return nil
+}
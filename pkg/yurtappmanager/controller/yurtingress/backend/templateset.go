@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+// TemplateVersionKey is the key of the version of a template set, in its configmap or its directory.
+const TemplateVersionKey = "version"
+
+// The names of the templates of the built-in ingress controllers, which are the keys in the template set configmaps
+// and the file names in the template set directories.
+const (
+	namespaceTemplate          = "namespace.yaml"
+	clusterRoleTemplate        = "clusterrole.yaml"
+	clusterRoleBindingTemplate = "clusterrolebinding.yaml"
+	roleTemplate               = "role.yaml"
+	roleBindingTemplate        = "rolebinding.yaml"
+	serviceAccountTemplate     = "serviceaccount.yaml"
+	configMapTemplate          = "configmap.yaml"
+	deploymentTemplate         = "deployment.yaml"
+	serviceTemplate            = "service.yaml"
+	ingressClassTemplate       = "ingressclass.yaml"
+
+	admissionClusterRoleTemplate        = "admission-clusterrole.yaml"
+	admissionClusterRoleBindingTemplate = "admission-clusterrolebinding.yaml"
+	admissionRoleTemplate               = "admission-role.yaml"
+	admissionRoleBindingTemplate        = "admission-rolebinding.yaml"
+	admissionServiceAccountTemplate     = "admission-serviceaccount.yaml"
+	admissionDeploymentTemplate         = "admission-deployment.yaml"
+	admissionServiceTemplate            = "admission-service.yaml"
+	admissionWebhookTemplate            = "validatingwebhookconfiguration.yaml"
+	admissionCreateJobTemplate          = "admission-create-job.yaml"
+	admissionPatchJobTemplate           = "admission-patch-job.yaml"
+)
+
+type builtinTemplate struct {
+	// kind is the kind of the only object rendered from the template
+	kind     string
+	template string
+}
+
+// builtinTemplates are the templates compiled into yurt-app-manager, by the ingress controller types.
+var builtinTemplates = map[appsv1alpha1.IngressControllerType]map[string]builtinTemplate{
+	appsv1alpha1.NginxIngressController: {
+		namespaceTemplate:                   {"Namespace", constant.NginxIngressControllerNamespace},
+		clusterRoleTemplate:                 {"ClusterRole", constant.NginxIngressControllerClusterRole},
+		clusterRoleBindingTemplate:          {"ClusterRoleBinding", constant.NginxIngressControllerClusterRoleBinding},
+		roleTemplate:                        {"Role", constant.NginxIngressControllerRole},
+		roleBindingTemplate:                 {"RoleBinding", constant.NginxIngressControllerRoleBinding},
+		serviceAccountTemplate:              {"ServiceAccount", constant.NginxIngressControllerServiceAccount},
+		configMapTemplate:                   {"ConfigMap", constant.NginxIngressControllerPoolConfigMap},
+		deploymentTemplate:                  {"Deployment", constant.NginxIngressControllerNodePoolDeployment},
+		serviceTemplate:                     {"Service", constant.NginxIngressControllerService},
+		ingressClassTemplate:                {"IngressClass", constant.NginxIngressControllerIngressClass},
+		admissionClusterRoleTemplate:        {"ClusterRole", constant.NginxIngressAdmissionWebhookClusterRole},
+		admissionClusterRoleBindingTemplate: {"ClusterRoleBinding", constant.NginxIngressAdmissionWebhookClusterRoleBinding},
+		admissionRoleTemplate:               {"Role", constant.NginxIngressAdmissionWebhookRole},
+		admissionRoleBindingTemplate:        {"RoleBinding", constant.NginxIngressAdmissionWebhookRoleBinding},
+		admissionServiceAccountTemplate:     {"ServiceAccount", constant.NginxIngressAdmissionWebhookServiceAccount},
+		admissionDeploymentTemplate:         {"Deployment", constant.NginxIngressAdmissionWebhookDeployment},
+		admissionServiceTemplate:            {"Service", constant.NginxIngressAdmissionWebhookService},
+		admissionWebhookTemplate:            {"ValidatingWebhookConfiguration", constant.NginxIngressValidatingWebhookConfiguration},
+		admissionCreateJobTemplate:          {"Job", constant.NginxIngressAdmissionWebhookJob},
+		admissionPatchJobTemplate:           {"Job", constant.NginxIngressAdmissionWebhookJobPatch},
+	},
+	appsv1alpha1.TraefikIngressController: {
+		namespaceTemplate:          {"Namespace", constant.TraefikIngressControllerNamespace},
+		clusterRoleTemplate:        {"ClusterRole", constant.TraefikIngressControllerClusterRole},
+		clusterRoleBindingTemplate: {"ClusterRoleBinding", constant.TraefikIngressControllerClusterRoleBinding},
+		serviceAccountTemplate:     {"ServiceAccount", constant.TraefikIngressControllerServiceAccount},
+		deploymentTemplate:         {"Deployment", constant.TraefikIngressControllerNodePoolDeployment},
+		serviceTemplate:            {"Service", constant.TraefikIngressControllerService},
+		ingressClassTemplate:       {"IngressClass", constant.TraefikIngressControllerIngressClass},
+	},
+}
+
+// TemplateSet overrides the built-in templates of an ingress controller type. The templates missing from the set
+// fall back to the built-in ones.
+type TemplateSet struct {
+	// Version of the templates, which is a part of the revisions of the ingress controllers, so a new version
+	// is rolled out to the pools.
+	Version   string
+	templates map[string]string
+}
+
+// TemplateSets are the template sets by the ingress controller types.
+type TemplateSets map[appsv1alpha1.IngressControllerType]*TemplateSet
+
+// getTemplate returns the template of the ingress controller type in the set, or the built-in one.
+func getTemplate(set *TemplateSet, controllerType appsv1alpha1.IngressControllerType, name string) string {
+	if set != nil {
+		if tmpl, ok := set.templates[name]; ok {
+			return tmpl
+		}
+	}
+	return builtinTemplates[controllerType][name].template
+}
+
+// NewTemplateSet validates the templates of the ingress controller type, and returns the template set of them.
+// Every template should be known by the ingress controller type, and render one object of the kind of the built-in
+// one, the Deployment of the ingress controller should be labeled with yurtingress.io/nodepool: {{.nodepool_name}}.
+func NewTemplateSet(controllerType appsv1alpha1.IngressControllerType, version string, templates map[string]string) (*TemplateSet, error) {
+	builtins, ok := builtinTemplates[controllerType]
+	if !ok {
+		return nil, fmt.Errorf("ingress controller type %s has no built-in templates", controllerType)
+	}
+	if version == "" {
+		return nil, fmt.Errorf("template set of %s has no %s", controllerType, TemplateVersionKey)
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pool := &Pool{Name: "template-validation", Namespace: "template-validation", NamePrefix: "template-validation-"}
+	for _, name := range names {
+		builtin, ok := builtins[name]
+		if !ok {
+			return nil, fmt.Errorf("template %s is unknown to %s", name, controllerType)
+		}
+		objs, err := addon.Render(poolContext(pool), templates[name])
+		if err != nil {
+			return nil, fmt.Errorf("template %s is invalid: %v", name, err)
+		}
+		if len(objs) != 1 || objs[0].GetKind() != builtin.kind {
+			return nil, fmt.Errorf("template %s should render one %s", name, builtin.kind)
+		}
+		if name == deploymentTemplate && objs[0].GetLabels()[ingressDeploymentLabel] != pool.Name {
+			return nil, fmt.Errorf("template %s should be labeled with %s: {{.nodepool_name}}", name,
+				ingressDeploymentLabel)
+		}
+	}
+	return &TemplateSet{Version: version, templates: templates}, nil
+}
+
+// LoadTemplateSet loads the template set of the ingress controller type from the configmap, whose keys are the
+// template names and the version. If version is not empty, the version of the configmap should be the same.
+func LoadTemplateSet(c client.Client, controllerType appsv1alpha1.IngressControllerType,
+	ref *appsv1alpha1.IngressTemplateSetReference) (*TemplateSet, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return nil, fmt.Errorf("fail to get template set configmap %s/%s: %v", ref.Namespace, ref.Name, err)
+	}
+	version := strings.TrimSpace(cm.Data[TemplateVersionKey])
+	if ref.Version != "" && ref.Version != version {
+		return nil, fmt.Errorf("template set configmap %s/%s is of version %q rather than %q", ref.Namespace,
+			ref.Name, version, ref.Version)
+	}
+	templates := make(map[string]string, len(cm.Data))
+	for name, tmpl := range cm.Data {
+		if name != TemplateVersionKey {
+			templates[name] = tmpl
+		}
+	}
+	set, err := NewTemplateSet(controllerType, version, templates)
+	if err != nil {
+		return nil, fmt.Errorf("template set configmap %s/%s: %v", ref.Namespace, ref.Name, err)
+	}
+	return set, nil
+}
+
+// LoadTemplateDir loads the template sets from the sub directories of dir named after the ingress controller types,
+// such as nginx and traefik. The files in a sub directory are the templates named after them and the version.
+// The ingress controller types without sub directories use the built-in templates.
+func LoadTemplateDir(dir string) (TemplateSets, error) {
+	sets := make(TemplateSets)
+	if dir == "" {
+		return sets, nil
+	}
+	for controllerType := range builtinTemplates {
+		typeDir := filepath.Join(dir, string(controllerType))
+		files, err := ioutil.ReadDir(typeDir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fail to read template directory %s: %v", typeDir, err)
+		}
+		var version string
+		templates := make(map[string]string, len(files))
+		for _, f := range files {
+			// the files of a mounted configmap are symbolic links to the ones in a hidden directory
+			if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+				continue
+			}
+			data, err := ioutil.ReadFile(filepath.Join(typeDir, f.Name()))
+			if err != nil {
+				return nil, fmt.Errorf("fail to read template %s: %v", filepath.Join(typeDir, f.Name()), err)
+			}
+			if f.Name() == TemplateVersionKey {
+				version = strings.TrimSpace(string(data))
+				continue
+			}
+			templates[f.Name()] = string(data)
+		}
+		set, err := NewTemplateSet(controllerType, version, templates)
+		if err != nil {
+			return nil, fmt.Errorf("template directory %s: %v", typeDir, err)
+		}
+		sets[controllerType] = set
+	}
+	return sets, nil
+}
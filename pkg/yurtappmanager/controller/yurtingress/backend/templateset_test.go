@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
+	addonfake "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon/fake"
+)
+
+// customTraefikDeployment is the built-in traefik deployment with an extra label.
+var customTraefikDeployment = strings.Replace(constant.TraefikIngressControllerNodePoolDeployment,
+	"yurtingress.io/nodepool: {{.nodepool_name}}",
+	"yurtingress.io/nodepool: {{.nodepool_name}}\n    template-version: v2", 1)
+
+func TestNewTemplateSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		templates map[string]string
+		valid     bool
+	}{
+		{name: "valid", version: "v2", templates: map[string]string{deploymentTemplate: customTraefikDeployment}, valid: true},
+		{name: "no version", templates: map[string]string{deploymentTemplate: customTraefikDeployment}},
+		{name: "unknown template", version: "v2", templates: map[string]string{"job.yaml": constant.NginxIngressAdmissionWebhookJob}},
+		{name: "wrong kind", version: "v2", templates: map[string]string{serviceTemplate: customTraefikDeployment}},
+		{name: "invalid template", version: "v2", templates: map[string]string{serviceTemplate: "{{.unclosed"}},
+		{name: "unlabeled deployment", version: "v2", templates: map[string]string{deploymentTemplate: strings.Replace(
+			customTraefikDeployment, "yurtingress.io/nodepool: {{.nodepool_name}}", "app: traefik", -1)}},
+	}
+	for _, tt := range tests {
+		_, err := NewTemplateSet(appsv1alpha1.TraefikIngressController, tt.version, tt.templates)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestLoadTemplateSet(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "traefik-templates"},
+		Data: map[string]string{
+			TemplateVersionKey: "v2",
+			deploymentTemplate: customTraefikDeployment,
+		},
+	}
+	c := addonfake.NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build())
+	ying := &appsv1alpha1.YurtIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ying"},
+		Spec: appsv1alpha1.YurtIngressSpec{
+			ControllerType: appsv1alpha1.TraefikIngressController,
+			TemplateSet:    &appsv1alpha1.IngressTemplateSetReference{Namespace: "kube-system", Name: "traefik-templates", Version: "v1"},
+		},
+	}
+	if _, err := New(c, ying); err == nil {
+		t.Fatalf("expected an error for the mismatched version")
+	}
+
+	ying.Spec.TemplateSet.Version = "v2"
+	b, err := New(c, ying)
+	if err != nil {
+		t.Fatalf("fail to get the backend: %v", err)
+	}
+	if v := TemplateVersion(ying); v != "v2" {
+		t.Fatalf("expected template version v2, got %q", v)
+	}
+	isController := true
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: "apps.openyurt.io/v1alpha1",
+		Kind:       "YurtIngress",
+		Name:       "ying",
+		UID:        "uid",
+		Controller: &isController,
+	}
+	pool := &Pool{Name: "hangzhou", Namespace: "ingress-traefik", Replicas: 1}
+	if err := b.CreatePoolResource(c, pool, ownerRef); err != nil {
+		t.Fatalf("fail to create pool resources: %v", err)
+	}
+	dply := &appsv1.Deployment{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-traefik", Name: "hangzhou-traefik"}, dply); err != nil {
+		t.Fatalf("fail to get the controller deployment: %v", err)
+	}
+	if dply.Labels["template-version"] != "v2" {
+		t.Fatalf("expected the deployment rendered from the template set, got labels %v", dply.Labels)
+	}
+	// the templates missing from the set fall back to the built-in ones
+	svc := &corev1.Service{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "ingress-traefik", Name: "hangzhou-traefik"}, svc); err != nil {
+		t.Fatalf("fail to get the controller service: %v", err)
+	}
+}
+
+func TestLoadTemplateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ingress-templates")
+	if err != nil {
+		t.Fatalf("fail to create the template directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	sets, err := LoadTemplateDir(dir)
+	if err != nil || len(sets) != 0 {
+		t.Fatalf("expected no template sets, got %v and %v", sets, err)
+	}
+
+	typeDir := filepath.Join(dir, string(appsv1alpha1.TraefikIngressController))
+	if err := os.MkdirAll(filepath.Join(typeDir, "..data"), 0755); err != nil {
+		t.Fatalf("fail to create the template directory: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(typeDir, deploymentTemplate), []byte(customTraefikDeployment), 0644); err != nil {
+		t.Fatalf("fail to write the template: %v", err)
+	}
+	if _, err := LoadTemplateDir(dir); err == nil {
+		t.Fatalf("expected an error for the template set without version")
+	}
+	if err := ioutil.WriteFile(filepath.Join(typeDir, TemplateVersionKey), []byte("v2\n"), 0644); err != nil {
+		t.Fatalf("fail to write the version: %v", err)
+	}
+	sets, err = LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("fail to load the template sets: %v", err)
+	}
+	if sets[appsv1alpha1.TraefikIngressController] == nil || sets[appsv1alpha1.NginxIngressController] != nil {
+		t.Fatalf("expected the template set of traefik only, got %v", sets)
+	}
+
+	SetDefaultTemplateSets(sets)
+	defer SetDefaultTemplateSets(nil)
+	if v := TemplateVersion(&appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{
+		ControllerType: appsv1alpha1.TraefikIngressController}}); v != "v2" {
+		t.Fatalf("expected template version v2, got %q", v)
+	}
+	if v := TemplateVersion(&appsv1alpha1.YurtIngress{}); v != "" {
+		t.Fatalf("expected no template version for the built-in templates, got %q", v)
+	}
+	b, err := New(nil, &appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{
+		ControllerType: appsv1alpha1.TraefikIngressController}})
+	if err != nil {
+		t.Fatalf("fail to get the backend: %v", err)
+	}
+	if b.(*TraefikBackend).template(deploymentTemplate) != customTraefikDeployment {
+		t.Fatalf("expected the deployment template of the template set")
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon"
+)
+
+// TraefikBackend deploys traefik in the pools. Traefik has no admission webhook,
+// so the webhook certgen image is not used. The common resources are created in Namespace, ingress-traefik by
+// default, and the names of the cluster scoped resources are prefixed with NamePrefix.
+type TraefikBackend struct {
+	Namespace  string
+	NamePrefix string
+	// Templates overrides the built-in templates, nil for the built-in templates.
+	Templates *TemplateSet
+}
+
+var _ Backend = &TraefikBackend{}
+
+// traefikCommonTemplates are the names of the templates of the common resources of traefik in the order of creation.
+var traefikCommonTemplates = []string{
+	namespaceTemplate,
+	clusterRoleTemplate,
+	clusterRoleBindingTemplate,
+	serviceAccountTemplate,
+}
+
+// traefikPoolTemplates are the names of the templates of the resources of traefik of a pool in the order of creation.
+var traefikPoolTemplates = []string{
+	deploymentTemplate,
+	serviceTemplate,
+	ingressClassTemplate,
+}
+
+// IsCommonResourceReady returns whether the namespace and rbac of traefik are created and owned by ownerRef.
+func (b *TraefikBackend) IsCommonResourceReady(cli client.Client, ownerRef *metav1.OwnerReference) bool {
+	objs, err := addon.Render(b.commonContext(), b.templates(traefikCommonTemplates)...)
+	if err != nil {
+		return false
+	}
+	return isCommonObjectsOwned(cli, b.commonInventory(), objs, ownerRef)
+}
+
+// CreateCommonResource applies the namespace and rbac of traefik owned by ownerRef.
+func (b *TraefikBackend) CreateCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(b.commonContext(), b.templates(traefikCommonTemplates)...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := ownCommonObjects(cli, b.commonInventory(), objs, ownerRef); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteCommonResource releases the namespace and rbac of traefik from ownerRef, and deletes them if no other
+// YurtIngress owns them.
+func (b *TraefikBackend) DeleteCommonResource(cli client.Client, ownerRef *metav1.OwnerReference) error {
+	objs, err := addon.Render(b.commonContext(), b.templates(traefikCommonTemplates)...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := releaseCommonObjects(cli, b.commonInventory(), objs, ownerRef); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// CreatePoolResource applies the traefik Deployment, Service and IngressClass of the pool, which are all owned by
+// ownerRef.
+func (b *TraefikBackend) CreatePoolResource(cli client.Client, pool *Pool, ownerRef *metav1.OwnerReference) error {
+	var ownerRefs []metav1.OwnerReference
+	if ownerRef != nil {
+		ownerRefs = append(ownerRefs, *ownerRef)
+	}
+	if err := b.applyPoolResource(cli, pool, ownerRefs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// DeletePoolResource deletes the resources of the pool recorded in its inventory in the reverse order of creation,
+// and the rendered ones, which are not recorded if the pool is created before the inventories are introduced.
+func (b *TraefikBackend) DeletePoolResource(cli client.Client, pool *Pool, cleanup bool) error {
+	if err := addon.NewManager(cli, fieldManager).Delete(context.TODO(), b.poolInventory(pool)); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	objs, err := addon.Render(poolContext(pool), b.templates(traefikPoolTemplates)...)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := deleteObjects(cli, objs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateController applies the resources of the pool again, so the traefik Deployment is updated to the image,
+// replicas and overrides of the pool, their drift is corrected and the resources no longer rendered are pruned.
+func (b *TraefikBackend) UpdateController(cli client.Client, pool *Pool) error {
+	ownerRefs, err := getControllerOwnerReferences(cli, b.template(deploymentTemplate), pool)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	if err := b.applyPoolResource(cli, pool, ownerRefs); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// Scale updates the replicas of the traefik Deployment of the pool.
+func (b *TraefikBackend) Scale(cli client.Client, pool *Pool) error {
+	if err := scaleControllerDeployment(cli, b.template(deploymentTemplate), pool); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// UpdateWebhookCertGenImage does nothing, traefik has no admission webhook.
+func (b *TraefikBackend) UpdateWebhookCertGenImage(cli client.Client, pool *Pool) error {
+	return nil
+}
+
+// EnsureWebhookCertificate does nothing, traefik has no admission webhook.
+func (b *TraefikBackend) EnsureWebhookCertificate(cli client.Client, pool *Pool) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// UpdateService updates the type, ports, external ips and annotations of the traefik service of the pool.
+func (b *TraefikBackend) UpdateService(cli client.Client, pool *Pool) error {
+	if err := updateControllerService(cli,
+		b.template(serviceTemplate),
+		pool); err != nil {
+		klog.Errorf("%v", err)
+		return err
+	}
+	return nil
+}
+
+// GetEndpoints returns the endpoints of the traefik service of the pool.
+func (b *TraefikBackend) GetEndpoints(cli client.Client, pool *Pool) ([]appsv1alpha1.IngressPoolEndpoint, error) {
+	svc, err := renderControllerService(b.template(serviceTemplate), pool)
+	if err != nil {
+		return nil, err
+	}
+	return getServiceEndpoints(cli, client.ObjectKeyFromObject(svc), pool)
+}
+
+// IsPoolReady regards traefik of the pool ready when all the replicas are updated and available,
+// and the traefik service has ready endpoints.
+func (b *TraefikBackend) IsPoolReady(cli client.Client, pool *Pool, dply *appsv1.Deployment) (bool, *appsv1alpha1.IngressNotReadyConditionInfo) {
+	if info := checkControllerDeployment(dply, pool.Replicas); info != nil {
+		return false, info
+	}
+	key, err := renderObjectKey(b.template(serviceTemplate), pool)
+	if err != nil {
+		return false, newNotReadyInfo(appsv1alpha1.IngressPending, appsv1alpha1.IngressNoReadyEndpoints, err.Error())
+	}
+	if info := checkServiceEndpoints(cli, key, pool.Replicas); info != nil {
+		return false, info
+	}
+	return true, nil
+}
+
+// applyPoolResource applies the resources of the pool owned by ownerRefs, or the owners of the inventory of the pool
+// if ownerRefs is nil, and prunes the ones no longer rendered.
+func (b *TraefikBackend) applyPoolResource(cli client.Client, pool *Pool, ownerRefs []metav1.OwnerReference) error {
+	objs, err := renderBuiltinPoolObjects(pool, traefikPoolTemplates, b.template)
+	if err != nil {
+		return err
+	}
+	return addon.NewManager(cli, fieldManager).Apply(context.TODO(), b.poolInventory(pool), objs,
+		&addon.ApplyOptions{OwnerReferences: ownerRefs})
+}
+
+// poolInventory returns the inventory of the resources of the pool.
+func (b *TraefikBackend) poolInventory(pool *Pool) addon.Inventory {
+	return addon.Inventory{Namespace: pool.Namespace, Name: pool.Name + "-traefik-inventory"}
+}
+
+// commonInventory returns the inventory of the common resources, which is kept in the namespace of traefik.
+func (b *TraefikBackend) commonInventory() addon.Inventory {
+	return addon.Inventory{Namespace: b.commonContext()["namespace"], Name: "traefik-inventory"}
+}
+
+func (b *TraefikBackend) commonContext() map[string]string {
+	return commonContext(b.Namespace, b.NamePrefix, traefikNamespace)
+}
+
+// template returns the template of the name of the template set of the backend, or the built-in one.
+func (b *TraefikBackend) template(name string) string {
+	return getTemplate(b.Templates, appsv1alpha1.TraefikIngressController, name)
+}
+
+// templates returns the templates of the names.
+func (b *TraefikBackend) templates(names []string) []string {
+	tmpls := make([]string, 0, len(names))
+	for _, name := range names {
+		tmpls = append(tmpls, b.template(name))
+	}
+	return tmpls
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+// getNodePools returns all the NodePools by their names.
+func getNodePools(c client.Client) (map[string]*appsv1alpha1.NodePool, error) {
+	npList := &appsv1alpha1.NodePoolList{}
+	if err := c.List(context.TODO(), npList); err != nil {
+		return nil, err
+	}
+	nodePools := make(map[string]*appsv1alpha1.NodePool, len(npList.Items))
+	for i := range npList.Items {
+		nodePools[npList.Items[i].Name] = &npList.Items[i]
+	}
+	return nodePools, nil
+}
+
+// getConflictingPools returns the desired pools of the YurtIngress which are owned by the other YurtIngresses deploying
+// the same ingress controllers, by the names of their owners. The webhook forbids such YurtIngresses to enable the same
+// pools, but a NodePool can be relabeled into several pool selectors after they are admitted. A pool is then owned by
+// the YurtIngress which lists it, or else by the oldest one.
+func getConflictingPools(c client.Client, ying *appsv1alpha1.YurtIngress, pools []appsv1alpha1.IngressPool,
+	nodePools map[string]*appsv1alpha1.NodePool) (map[string]string, error) {
+	yings := &appsv1alpha1.YurtIngressList{}
+	if err := c.List(context.TODO(), yings); err != nil {
+		return nil, err
+	}
+	desired := make(map[string]bool, len(pools))
+	for _, pool := range pools {
+		desired[pool.Name] = true
+	}
+	conflicts := make(map[string]string)
+	for i := range yings.Items {
+		other := &yings.Items[i]
+		if other.Name == ying.Name || !backend.IsSameControllers(ying, other) {
+			continue
+		}
+		otherPools, err := backend.DesiredPools(other, nodePools)
+		if err != nil {
+			klog.Errorf("%v", err)
+			continue
+		}
+		for _, pool := range otherPools {
+			if desired[pool.Name] && ownsPoolBefore(other, ying, pool.Name) {
+				conflicts[pool.Name] = other.Name
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// ownsPoolBefore returns whether the pool enabled by both YurtIngresses is owned by a rather than b.
+func ownsPoolBefore(a, b *appsv1alpha1.YurtIngress, poolname string) bool {
+	if listedA, listedB := backend.IsPoolListed(a, poolname), backend.IsPoolListed(b, poolname); listedA != listedB {
+		return listedA
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// checkNodePool returns why the ingress controller of the pool can not be ready because of its NodePool,
+// nil if the NodePool exists and has nodes, or the NodePools are not known since the NodePool resource is disabled.
+func checkNodePool(nodePools map[string]*appsv1alpha1.NodePool, poolname string) *appsv1alpha1.IngressNotReadyConditionInfo {
+	if nodePools == nil {
+		return nil
+	}
+	np, ok := nodePools[poolname]
+	if !ok {
+		return &appsv1alpha1.IngressNotReadyConditionInfo{
+			Type:    appsv1alpha1.IngressFailure,
+			Reason:  appsv1alpha1.IngressNodePoolNotFound,
+			Message: fmt.Sprintf("nodepool %s does not exist", poolname),
+		}
+	}
+	if len(np.Status.Nodes) == 0 {
+		return &appsv1alpha1.IngressNotReadyConditionInfo{
+			Type:    appsv1alpha1.IngressPending,
+			Reason:  appsv1alpha1.IngressNodePoolEmpty,
+			Message: fmt.Sprintf("nodepool %s has no nodes", poolname),
+		}
+	}
+	return nil
+}
+
+// isNodePoolReason returns whether the pool is not ready because its NodePool is missing or empty.
+func isNodePoolReason(reason string) bool {
+	return reason == appsv1alpha1.IngressNodePoolNotFound || reason == appsv1alpha1.IngressNodePoolEmpty
+}
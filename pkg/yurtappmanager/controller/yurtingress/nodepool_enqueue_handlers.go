@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"context"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+// EnqueueYurtIngressForNodePool enqueues the YurtIngresses which enable ingress on the NodePool,
+// when the NodePool is created, deleted, relabeled or its nodes change.
+type EnqueueYurtIngressForNodePool struct {
+	client client.Client
+}
+
+func (e *EnqueueYurtIngressForNodePool) Create(event event.CreateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtIngressesToWorkQueue(event.Object, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForNodePool) Update(event event.UpdateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	oldNp, oldOK := event.ObjectOld.(*appsv1alpha1.NodePool)
+	newNp, newOK := event.ObjectNew.(*appsv1alpha1.NodePool)
+	if oldOK && newOK && len(oldNp.Status.Nodes) == len(newNp.Status.Nodes) &&
+		reflect.DeepEqual(oldNp.Labels, newNp.Labels) {
+		return
+	}
+	// a NodePool which is relabeled may leave the selectors of the YurtIngresses
+	e.addYurtIngressesToWorkQueue(event.ObjectOld, limitingInterface)
+	e.addYurtIngressesToWorkQueue(event.ObjectNew, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForNodePool) Delete(event event.DeleteEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtIngressesToWorkQueue(event.Object, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForNodePool) Generic(event event.GenericEvent, limitingInterface workqueue.RateLimitingInterface) {
+	return
+}
+
+func (e *EnqueueYurtIngressForNodePool) addYurtIngressesToWorkQueue(np client.Object, q workqueue.RateLimitingInterface) {
+	yings := &appsv1alpha1.YurtIngressList{}
+	if err := e.client.List(context.TODO(), yings); err != nil {
+		klog.Errorf("Fail to list YurtIngresses for NodePool %s: %v", np.GetName(), err)
+		return
+	}
+	for i := range yings.Items {
+		if isNodePoolReferred(&yings.Items[i], np) {
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: yings.Items[i].GetName()}})
+		}
+	}
+}
+
+// isNodePoolReferred returns whether the YurtIngress lists the NodePool in its pools, selects it,
+// or has deployed an ingress controller to it.
+func isNodePoolReferred(ying *appsv1alpha1.YurtIngress, np client.Object) bool {
+	for _, pool := range append(getCurrentPools(ying), ying.Spec.Pools...) {
+		if pool.Name == np.GetName() {
+			return true
+		}
+	}
+	if ying.Spec.PoolSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ying.Spec.PoolSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(np.GetLabels()))
+}
+
+var _ handler.EventHandler = &EnqueueYurtIngressForNodePool{}
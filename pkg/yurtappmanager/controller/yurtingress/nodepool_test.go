@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+func newNodePool(name string, labels map[string]string, nodes ...string) *appsv1alpha1.NodePool {
+	return &appsv1alpha1.NodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status:     appsv1alpha1.NodePoolStatus{Nodes: nodes},
+	}
+}
+
+func TestCheckNodePool(t *testing.T) {
+	nodePools := map[string]*appsv1alpha1.NodePool{
+		"hangzhou": newNodePool("hangzhou", nil, "node1"),
+		"beijing":  newNodePool("beijing", nil),
+	}
+	if info := checkNodePool(nodePools, "hangzhou"); info != nil {
+		t.Fatalf("expected the pool with nodes to be available, got %v", info)
+	}
+	if info := checkNodePool(nodePools, "beijing"); info == nil || info.Reason != appsv1alpha1.IngressNodePoolEmpty ||
+		info.Type != appsv1alpha1.IngressPending {
+		t.Fatalf("expected the empty pool to be pending, got %v", info)
+	}
+	if info := checkNodePool(nodePools, "shanghai"); info == nil || info.Reason != appsv1alpha1.IngressNodePoolNotFound ||
+		info.Type != appsv1alpha1.IngressFailure {
+		t.Fatalf("expected the missing pool to fail, got %v", info)
+	}
+	if info := checkNodePool(nil, "shanghai"); info != nil {
+		t.Fatalf("expected no check without the nodepools, got %v", info)
+	}
+
+}
+
+func TestIsNodePoolReferred(t *testing.T) {
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			Pools:        []appsv1alpha1.IngressPool{{Name: "hangzhou"}},
+			PoolSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"ingress": "enabled"}},
+		},
+	}
+	ying.Status.Conditions.IngressReadyPools = []appsv1alpha1.IngressPool{{Name: "beijing"}}
+	tests := map[string]bool{
+		"hangzhou": true,
+		"beijing":  true,
+		"shanghai": false,
+	}
+	for name, expected := range tests {
+		if got := isNodePoolReferred(ying, newNodePool(name, nil)); got != expected {
+			t.Errorf("nodepool %s: expected %v, got %v", name, expected, got)
+		}
+	}
+	if !isNodePoolReferred(ying, newNodePool("shanghai", map[string]string{"ingress": "enabled"})) {
+		t.Errorf("expected the selected nodepool to be referred")
+	}
+}
+
+func TestUpdateStatusWithNodePools(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	ying := &appsv1alpha1.YurtIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ying", UID: "ying-uid"},
+		Spec: appsv1alpha1.YurtIngressSpec{
+			ControllerType: appsv1alpha1.TraefikIngressController,
+			Replicas:       1,
+			Pools:          []appsv1alpha1.IngressPool{{Name: "empty"}, {Name: "deleted"}},
+		},
+	}
+	nodePools := map[string]*appsv1alpha1.NodePool{"empty": newNodePool("empty", nil)}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ying).Build()
+	r := &YurtIngressReconciler{Client: c, Scheme: scheme, nodePoolEnabled: true}
+
+	if err := r.updateStatus(ying, &backend.TraefikBackend{}, ying.Spec.Pools, nodePools,
+		map[string]bool{"empty": true}); err != nil {
+		t.Fatalf("fail to update status: %v", err)
+	}
+	got := &appsv1alpha1.YurtIngress{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "ying"}, got); err != nil {
+		t.Fatalf("fail to get YurtIngress: %v", err)
+	}
+	if got.Status.UnreadyNum != 2 {
+		t.Fatalf("expected both pools not ready, got %+v", got.Status)
+	}
+	if reason := getNotReadyReason(got, "empty"); reason != appsv1alpha1.IngressNodePoolEmpty {
+		t.Fatalf("expected the empty pool reason %s, got %s", appsv1alpha1.IngressNodePoolEmpty, reason)
+	}
+	if reason := getNotReadyReason(got, "deleted"); reason != appsv1alpha1.IngressNodePoolNotFound {
+		t.Fatalf("expected the deleted pool reason %s, got %s", appsv1alpha1.IngressNodePoolNotFound, reason)
+	}
+}
+
+func TestGetConflictingPools(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	edge := map[string]string{"ingress": "enabled"}
+	nodePools := map[string]*appsv1alpha1.NodePool{
+		"hangzhou": newNodePool("hangzhou", edge, "node1"),
+		"beijing":  newNodePool("beijing", edge, "node2"),
+	}
+	newYurtIngress := func(name string, created int64, namespace string, pools ...string) *appsv1alpha1.YurtIngress {
+		ying := &appsv1alpha1.YurtIngress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Unix(created, 0)},
+			Spec: appsv1alpha1.YurtIngressSpec{
+				Namespace:    namespace,
+				PoolSelector: &metav1.LabelSelector{MatchLabels: edge},
+			},
+		}
+		for _, pool := range pools {
+			ying.Spec.Pools = append(ying.Spec.Pools, appsv1alpha1.IngressPool{Name: pool})
+		}
+		return ying
+	}
+	// the NodePools are relabeled into the selectors of all of them after they are admitted
+	older := newYurtIngress("older", 1, "")
+	newer := newYurtIngress("newer", 2, "", "beijing")
+	tenant := newYurtIngress("tenant", 0, "tenant")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(older, newer, tenant).Build()
+
+	conflictsOf := func(ying *appsv1alpha1.YurtIngress) map[string]string {
+		pools, err := backend.DesiredPools(ying, nodePools)
+		if err != nil {
+			t.Fatalf("fail to get desired pools: %v", err)
+		}
+		conflicts, err := getConflictingPools(c, ying, pools, nodePools)
+		if err != nil {
+			t.Fatalf("fail to get conflicting pools: %v", err)
+		}
+		return conflicts
+	}
+	if conflicts := conflictsOf(older); len(conflicts) != 1 || conflicts["beijing"] != "newer" {
+		t.Fatalf("expected the listed pool owned by the YurtIngress listing it, got %v", conflicts)
+	}
+	if conflicts := conflictsOf(newer); len(conflicts) != 1 || conflicts["hangzhou"] != "older" {
+		t.Fatalf("expected the selected pool owned by the older YurtIngress, got %v", conflicts)
+	}
+	if conflicts := conflictsOf(tenant); len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts with the YurtIngresses in other namespaces, got %v", conflicts)
+	}
+
+	r := &YurtIngressReconciler{Client: c, Scheme: scheme}
+	newer.Status.Conditions.IngressReadyPools = []appsv1alpha1.IngressPool{{Name: "beijing"}, {Name: "hangzhou"}}
+	pools := r.removeConflictingPools(newer, []appsv1alpha1.IngressPool{{Name: "beijing"}, {Name: "hangzhou"}},
+		map[string]string{"hangzhou": "older"})
+	if len(pools) != 1 || pools[0].Name != "beijing" {
+		t.Fatalf("expected the owned pools kept, got %v", pools)
+	}
+	if current := getCurrentPools(newer); len(current) != 1 || current[0].Name != "beijing" {
+		t.Fatalf("expected the conflicting pool removed from the status, got %v", current)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+// controllerRevision returns the hash of the configuration of the ingress controller of the pool,
+// whose changes update the pods of the ingress controller.
+func controllerRevision(pool *backend.Pool) string {
+	data, _ := json.Marshal(struct {
+		Image        string
+		Resources    *corev1.ResourceRequirements
+		ExtraArgs    []string
+		NodeSelector map[string]string
+		Tolerations  []corev1.Toleration
+		HostNetwork  bool
+		Config       map[string]string
+		// omitted for the built-in templates, so the revisions before the template sets are kept
+		TemplateVersion string `json:",omitempty"`
+	}{
+		Image:           pool.Image,
+		Resources:       pool.Resources,
+		ExtraArgs:       pool.ExtraArgs,
+		NodeSelector:    pool.NodeSelector,
+		Tolerations:     pool.Tolerations,
+		HostNetwork:     pool.IsHostNetwork(),
+		Config:          pool.Config,
+		TemplateVersion: pool.TemplateVersion,
+	})
+	h := fnv.New32a()
+	h.Write(data)
+	return fmt.Sprintf("%x", h.Sum32())
+}
+
+// getPoolStatus returns the recorded status of the pool, nil if it is not recorded.
+func getPoolStatus(ying *appsv1alpha1.YurtIngress, poolname string) *appsv1alpha1.IngressPoolStatus {
+	for i := range ying.Status.Pools {
+		if ying.Status.Pools[i].Name == poolname {
+			return &ying.Status.Pools[i]
+		}
+	}
+	return nil
+}
+
+// getPoolRevision returns the revision of the ingress controller applied to the pool. The statuses recorded before
+// the revisions are introduced have none, then the ingress controller recorded in the status is the applied one.
+func getPoolRevision(ying *appsv1alpha1.YurtIngress, current *backend.Pool) string {
+	if status := getPoolStatus(ying, current.Name); status != nil && status.ControllerRevision != "" {
+		return status.ControllerRevision
+	}
+	return controllerRevision(current)
+}
+
+// setPoolRevision records the ingress controller applied to the pool. If keep is true, the recorded one is kept.
+func setPoolRevision(ying *appsv1alpha1.YurtIngress, pool *backend.Pool, keep bool) {
+	status := getPoolStatus(ying, pool.Name)
+	if status == nil {
+		ying.Status.Pools = append(ying.Status.Pools, appsv1alpha1.IngressPoolStatus{Name: pool.Name})
+		status = &ying.Status.Pools[len(ying.Status.Pools)-1]
+	}
+	if keep && status.ControllerRevision != "" {
+		return
+	}
+	status.Image = pool.Image
+	status.ControllerRevision = controllerRevision(pool)
+	status.TemplateVersion = pool.TemplateVersion
+}
+
+// planRollout returns the pools to be updated in this reconcile among the changed ones, according to the update
+// strategy. The number of the not ready pools is kept within maxUnavailable, so the next pools are not updated
+// until the ones updated before are ready. The changed pools which are not ready are always updated, since
+// updating them makes no more pools unavailable. The pools whose NodePools are missing or empty are not counted.
+func planRollout(ying *appsv1alpha1.YurtIngress, pools []appsv1alpha1.IngressPool, changed []string) map[string]bool {
+	maxUnavailable := 1
+	strategy := ying.Spec.UpdateStrategy
+	if strategy != nil && strategy.MaxUnavailable != nil {
+		value, err := intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, len(pools), true)
+		if err != nil {
+			klog.Errorf("Invalid maxUnavailable %s of YurtIngress %s: %v", strategy.MaxUnavailable.String(), ying.Name, err)
+		} else if value > 1 {
+			maxUnavailable = value
+		}
+	}
+
+	desired := make(map[string]bool, len(pools))
+	for _, pool := range pools {
+		desired[pool.Name] = true
+	}
+	notReady := make(map[string]bool)
+	for _, pool := range ying.Status.Conditions.IngressNotReadyPools {
+		if desired[pool.Pool.Name] && (pool.Info == nil || !isNodePoolReason(pool.Info.Reason)) {
+			notReady[pool.Pool.Name] = true
+		}
+	}
+	budget := maxUnavailable - len(notReady)
+
+	rollout := make(map[string]bool)
+	for _, name := range orderPools(ying, pools, changed) {
+		switch {
+		case notReady[name]:
+			rollout[name] = true
+		case budget > 0:
+			rollout[name] = true
+			budget--
+		default:
+			klog.V(4).Infof("the update of the ingress controller of pool %s waits for the other pools to be ready", name)
+		}
+	}
+	return rollout
+}
+
+// orderPools sorts the changed pools by the canary pools, the order of the update strategy and the desired pools.
+// Only the changed canary pools are returned as long as the canary pools are set.
+func orderPools(ying *appsv1alpha1.YurtIngress, pools []appsv1alpha1.IngressPool, changed []string) []string {
+	isChanged := make(map[string]bool, len(changed))
+	for _, name := range changed {
+		isChanged[name] = true
+	}
+	var ordered []string
+	add := func(name string) {
+		if isChanged[name] {
+			ordered = append(ordered, name)
+			delete(isChanged, name)
+		}
+	}
+	strategy := ying.Spec.UpdateStrategy
+	if strategy != nil && len(strategy.Canary) > 0 {
+		for _, name := range strategy.Canary {
+			add(name)
+		}
+		return ordered
+	}
+	if strategy != nil {
+		for _, name := range strategy.Order {
+			add(name)
+		}
+	}
+	for _, pool := range pools {
+		add(pool.Name)
+	}
+	return ordered
+}
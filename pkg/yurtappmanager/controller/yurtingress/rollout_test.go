@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func TestPlanRollout(t *testing.T) {
+	pools := []appsv1alpha1.IngressPool{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}}
+	all := []string{"a", "b", "c", "d"}
+	percent := intstr.FromString("50%")
+	two := intstr.FromInt(2)
+	tests := []struct {
+		name     string
+		strategy *appsv1alpha1.IngressUpdateStrategy
+		notReady []string
+		changed  []string
+		expected []string
+	}{
+		{name: "one pool at a time by default", changed: all, expected: []string{"a"}},
+		{name: "percentage of the pools", strategy: &appsv1alpha1.IngressUpdateStrategy{MaxUnavailable: &percent},
+			changed: all, expected: []string{"a", "b"}},
+		{name: "wait for the updated pool to be ready", notReady: []string{"a"}, changed: []string{"b", "c", "d"}},
+		{name: "not ready pools are updated", strategy: &appsv1alpha1.IngressUpdateStrategy{MaxUnavailable: &two},
+			notReady: []string{"c"}, changed: all, expected: []string{"a", "c"}},
+		{name: "ordered pools first", strategy: &appsv1alpha1.IngressUpdateStrategy{Order: []string{"d", "c"}},
+			changed: all, expected: []string{"d"}},
+		{name: "only canary pools", strategy: &appsv1alpha1.IngressUpdateStrategy{MaxUnavailable: &two, Canary: []string{"c"}},
+			changed: all, expected: []string{"c"}},
+		{name: "removed pools are not counted", notReady: []string{"removed"}, changed: all, expected: []string{"a"}},
+	}
+	for _, tt := range tests {
+		ying := &appsv1alpha1.YurtIngress{Spec: appsv1alpha1.YurtIngressSpec{Pools: pools, UpdateStrategy: tt.strategy}}
+		for _, name := range tt.notReady {
+			ying.Status.Conditions.IngressNotReadyPools = append(ying.Status.Conditions.IngressNotReadyPools,
+				appsv1alpha1.IngressNotReadyPool{Pool: appsv1alpha1.IngressPool{Name: name}})
+		}
+		var got []string
+		for name := range planRollout(ying, pools, tt.changed) {
+			got = append(got, name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestPoolRevision(t *testing.T) {
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			IngressControllerImage: "controller:v2",
+			Pools:                  []appsv1alpha1.IngressPool{{Name: "a"}, {Name: "b"}},
+		},
+		Status: appsv1alpha1.YurtIngressStatus{
+			IngressControllerImage: "controller:v1",
+			Conditions: appsv1alpha1.YurtIngressCondition{
+				IngressReadyPools: []appsv1alpha1.IngressPool{{Name: "a"}, {Name: "b"}},
+			},
+		},
+	}
+	for _, pool := range ying.Spec.Pools {
+		current := newCurrentBackendPool(ying, pool)
+		if getPoolRevision(ying, current) == controllerRevision(newBackendPool(ying, pool)) {
+			t.Fatalf("expected pool %s recorded before the revisions to be changed", pool.Name)
+		}
+		setPoolRevision(ying, current, true)
+	}
+	setPoolRevision(ying, newBackendPool(ying, ying.Spec.Pools[0]), false)
+
+	// the applied revisions are kept after the status images are updated to the spec
+	ying.Status.IngressControllerImage = ying.Spec.IngressControllerImage
+	if status := getPoolStatus(ying, "b"); status == nil || status.Image != "controller:v1" {
+		t.Fatalf("expected pool b to keep the old image, got %v", status)
+	}
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Spec.Pools[1])) ==
+		controllerRevision(newBackendPool(ying, ying.Spec.Pools[1])) {
+		t.Fatalf("expected pool b not updated to be changed")
+	}
+	if updated := getUpdatedNum(ying, ying.Spec.Pools); updated != 1 {
+		t.Fatalf("expected 1 updated pool, got %d", updated)
+	}
+}
+
+func TestTemplateVersionRevision(t *testing.T) {
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			IngressControllerImage: "controller:v1",
+			Pools:                  []appsv1alpha1.IngressPool{{Name: "a"}},
+		},
+	}
+	pool := newBackendPool(ying, ying.Spec.Pools[0])
+	// the revisions of the built-in templates are the same as the ones before the template sets
+	builtin := controllerRevision(pool)
+	if pool.TemplateVersion != "" || builtin != "b660615a" {
+		t.Fatalf("expected the revision of the built-in templates unchanged, got %q of template version %q",
+			builtin, pool.TemplateVersion)
+	}
+	setPoolRevision(ying, pool, false)
+
+	ying.Spec.TemplateSet = &appsv1alpha1.IngressTemplateSetReference{Namespace: "kube-system", Name: "nginx", Version: "v2"}
+	pool = newBackendPool(ying, ying.Spec.Pools[0])
+	if pool.TemplateVersion != "v2" || controllerRevision(pool) == builtin {
+		t.Fatalf("expected the revision to change with the template version")
+	}
+	setPoolRevision(ying, pool, false)
+	if status := getPoolStatus(ying, "a"); status == nil || status.TemplateVersion != "v2" {
+		t.Fatalf("expected the template version recorded in the pool status, got %v", status)
+	}
+	if current := newCurrentBackendPool(ying, ying.Spec.Pools[0]); current.TemplateVersion != "v2" {
+		t.Fatalf("expected the current pool of template version v2, got %q", current.TemplateVersion)
+	}
+}
+
+func TestPerPoolOverrides(t *testing.T) {
+	replicas := int32(3)
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			Replicas:               1,
+			IngressControllerImage: "controller:v1",
+			Pools: []appsv1alpha1.IngressPool{
+				{Name: "small"},
+				{Name: "big", Replicas: &replicas, Image: "controller:v2"},
+			},
+		},
+		Status: appsv1alpha1.YurtIngressStatus{
+			Replicas:               1,
+			IngressControllerImage: "controller:v1",
+			ReadyNum:               2,
+			Conditions: appsv1alpha1.YurtIngressCondition{
+				IngressReadyPools: []appsv1alpha1.IngressPool{{Name: "small"}, {Name: "big"}},
+			},
+		},
+	}
+
+	small := newBackendPool(ying, ying.Spec.Pools[0])
+	if small.Replicas != 1 || small.Image != "controller:v1" {
+		t.Fatalf("expected the pool without overrides to use the shared values, got %v", small)
+	}
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Status.Conditions.IngressReadyPools[0])) !=
+		controllerRevision(small) {
+		t.Fatalf("expected the pool without overrides to be unchanged")
+	}
+
+	big := newBackendPool(ying, ying.Spec.Pools[1])
+	if big.Replicas != 3 || big.Image != "controller:v2" {
+		t.Fatalf("expected the pool overrides to be applied, got %v", big)
+	}
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Status.Conditions.IngressReadyPools[1])) ==
+		controllerRevision(big) {
+		t.Fatalf("expected the pool with a new image to be changed")
+	}
+	// the revision recorded once the pool is updated is compared rather than the one of the shared status
+	setPoolRevision(ying, big, false)
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Status.Conditions.IngressReadyPools[1])) !=
+		controllerRevision(big) {
+		t.Fatalf("expected the updated pool to be unchanged")
+	}
+}
+
+func TestPoolConfig(t *testing.T) {
+	ying := &appsv1alpha1.YurtIngress{
+		Spec: appsv1alpha1.YurtIngressSpec{
+			Config: map[string]string{"proxy-body-size": "8m", "use-gzip": "true"},
+			Pools: []appsv1alpha1.IngressPool{
+				{Name: "small"},
+				{Name: "big", Config: map[string]string{"proxy-body-size": "64m"}},
+			},
+		},
+		Status: appsv1alpha1.YurtIngressStatus{
+			Config: map[string]string{"proxy-body-size": "8m"},
+		},
+	}
+
+	big := newBackendPool(ying, ying.Spec.Pools[1])
+	if big.Config["proxy-body-size"] != "64m" || big.Config["use-gzip"] != "true" {
+		t.Fatalf("expected the pool config to override the shared config, got %v", big.Config)
+	}
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Spec.Pools[0])) ==
+		controllerRevision(newBackendPool(ying, ying.Spec.Pools[0])) {
+		t.Fatalf("expected the pool to be changed by the shared config")
+	}
+
+	ying.Status.Config = ying.Spec.Config
+	if getPoolRevision(ying, newCurrentBackendPool(ying, ying.Spec.Pools[1])) != controllerRevision(big) {
+		t.Fatalf("expected the pool with the applied config to be unchanged")
+	}
+	if pool := newBackendPool(&appsv1alpha1.YurtIngress{}, appsv1alpha1.IngressPool{Name: "empty"}); pool.Config != nil {
+		t.Fatalf("expected no config, got %v", pool.Config)
+	}
+}
@@ -18,9 +18,15 @@ package yurtingress
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -36,8 +42,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/metrics"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
-	yurtapputil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/kubernetes"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/refmanager"
 )
 
@@ -48,22 +57,33 @@ const (
 
 const updateRetries = 5
 
-var concurrentReconciles = 3
+// notReadyRequeueInterval is the interval to check the readiness of the pools again while any pool is not ready.
+const notReadyRequeueInterval = 10 * time.Second
 
 // YurtIngressReconciler reconciles a YurtIngress object
 type YurtIngressReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	// nodePoolEnabled is whether the NodePool resource is enabled, the pools are not checked against
+	// their NodePools otherwise
+	nodePoolEnabled bool
 }
 
 // Add creates a new YurtIngress Controller and adds it to the Manager with default RBAC.
 // The Manager will set fields on the Controller and start it when the Manager is started.
+// It is called once the CRD of YurtIngress is installed and YurtIngress is enabled.
 func Add(mgr manager.Manager, ctx context.Context) error {
-	if !gate.ResourceEnabled(&appsv1alpha1.YurtIngress{}) {
-		return nil
+	c := util.ControllersConfiguration(ctx).YurtIngress
+	if dir := c.TemplateDir; dir != "" {
+		// the template sets are validated at startup, yurt-app-manager fails to start with invalid ones
+		sets, err := backend.LoadTemplateDir(dir)
+		if err != nil {
+			return fmt.Errorf("fail to load the ingress template sets from %s: %v", dir, err)
+		}
+		backend.SetDefaultTemplateSets(sets)
 	}
-	return add(mgr, newReconciler(mgr))
+	return add(mgr, newReconciler(mgr), c.ControllerConfiguration)
 }
 
 // newReconciler returns a new reconcile.Reconciler
@@ -73,18 +93,15 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		recorder: mgr.GetEventRecorderFor(controllerName),
+
+		nodePoolEnabled: gate.ResourceEnabled(&appsv1alpha1.NodePool{}),
 	}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, cfg configv1alpha1.ControllerConfiguration) error {
 	// Create a new controller
-	c, err := controller.New(
-		controllerName,
-		mgr,
-		controller.Options{
-			Reconciler:              r,
-			MaxConcurrentReconciles: concurrentReconciles})
+	c, err := controller.New(controllerName, mgr, util.ControllerOptions(gate.NewReconciler(&appsv1alpha1.YurtIngress{}, r), cfg))
 	if err != nil {
 		return err
 	}
@@ -93,6 +110,10 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	err = c.Watch(&source.Kind{Type: &appsv1alpha1.YurtIngress{}}, &EnqueueYurtIngressForPoolOwners{client: mgr.GetClient()})
+	if err != nil {
+		return err
+	}
 	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, &handler.EnqueueRequestForOwner{
 		IsController: true,
 		OwnerType:    &appsv1alpha1.YurtIngress{},
@@ -100,15 +121,27 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	if err != nil {
 		return err
 	}
+	if r.(*YurtIngressReconciler).nodePoolEnabled {
+		err = c.Watch(&source.Kind{Type: &appsv1alpha1.NodePool{}}, &EnqueueYurtIngressForNodePool{client: mgr.GetClient()})
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // +kubebuilder:rbac:groups=apps.openyurt.io,resources=yurtingresses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.openyurt.io,resources=yurtingresses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps.openyurt.io,resources=nodepools,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=endpoints,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=*
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=*
@@ -125,6 +158,7 @@ func (r *YurtIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	err := r.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
+			metrics.DeleteYurtIngress(req.Name)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -140,53 +174,81 @@ func (r *YurtIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if !instance.ObjectMeta.DeletionTimestamp.IsZero() {
 		return r.cleanupIngressResources(instance)
 	}
+	ingressBackend, err := backend.New(r.Client, instance)
+	if err != nil {
+		klog.Errorf("Fail to get the ingress controller backend of YurtIngress %s: %v", instance.Name, err)
+		return ctrl.Result{}, err
+	}
+
+	var nodePools map[string]*appsv1alpha1.NodePool
+	if r.nodePoolEnabled {
+		if nodePools, err = getNodePools(r.Client); err != nil {
+			klog.Errorf("Fail to list the nodepools: %v", err)
+			return ctrl.Result{}, err
+		}
+	}
+	poolDeployments, err := r.getPoolDeployments(instance)
+	if err != nil {
+		klog.Errorf("Fail to get the ingress controller deployments of YurtIngress %s: %v", instance.Name, err)
+		return ctrl.Result{}, err
+	}
+	// missing pools are the pools whose NodePools are missing or empty, their ingress controller resources
+	// are deleted until the NodePools have nodes if the missing pool policy is Delete
+	deleteMissingPools := instance.Spec.MissingPoolPolicy == appsv1alpha1.DeleteMissingPool
 
 	var desiredPools, currentPools []appsv1alpha1.IngressPool
-	desiredPools = getDesiredPools(instance)
+	desiredPools, err = backend.DesiredPools(instance, nodePools)
+	if err != nil {
+		klog.Errorf("%v", err)
+		return ctrl.Result{}, err
+	}
+	conflicts, err := getConflictingPools(r.Client, instance, desiredPools, nodePools)
+	if err != nil {
+		klog.Errorf("Fail to list the YurtIngresses: %v", err)
+		return ctrl.Result{}, err
+	}
+	desiredPools = r.removeConflictingPools(instance, desiredPools, conflicts)
 	currentPools = getCurrentPools(instance)
-	isYurtIngressCRChanged := false
+	// the pools whose ingress controllers are created or updated in this reconcile
+	updatingPools := make(map[string]bool)
+	// the earliest time when the webhook certificates managed by yurt-app-manager should be rotated
+	var certRenewTime time.Time
 
 	addedPools, removedPools, unchangedPools := getPools(desiredPools, currentPools)
 	if addedPools != nil {
-		klog.V(4).Infof("added pool list is %s", addedPools)
-		isYurtIngressCRChanged = true
+		klog.V(4).Infof("added pool list is %v", addedPools)
 		ownerRef := prepareDeploymentOwnerReferences(instance)
-		if currentPools == nil && !yurtapputil.IsIngressNamespaceReady(r.Client) {
-			if err := yurtapputil.CreateNginxIngressCommonResource(r.Client); err != nil {
+		// the common resources are shared by the YurtIngresses in the same namespace, each of them owns them
+		if !ingressBackend.IsCommonResourceReady(r.Client, ownerRef) {
+			if err := ingressBackend.CreateCommonResource(r.Client, ownerRef); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
-		replicas := instance.Spec.Replicas
-		ingress_controller_image := instance.Spec.IngressControllerImage
-		ingress_webhook_certgen_image := instance.Spec.IngressWebhookCertGenImage
 		for _, pool := range addedPools {
-			if err := yurtapputil.CreateNginxIngressSpecificResource(r.Client, pool.Name, &pool.IngressIPs, ingress_controller_image, ingress_webhook_certgen_image, replicas, ownerRef); err != nil {
+			if deleteMissingPools && checkNodePool(nodePools, pool.Name) != nil {
+				klog.V(4).Infof("NodePool of pool %s is missing or empty, the ingress controller is not created", pool.Name)
+				continue
+			}
+			desired := newBackendPool(instance, pool)
+			if err := ingressBackend.CreatePoolResource(r.Client, desired, ownerRef); err != nil {
 				return ctrl.Result{}, err
 			}
-			notReadyPool := appsv1alpha1.IngressNotReadyPool{Pool: appsv1alpha1.IngressPool{Name: pool.Name, IngressIPs: pool.IngressIPs}, Info: nil}
-			instance.Status.Conditions.IngressNotReadyPools = append(instance.Status.Conditions.IngressNotReadyPools, notReadyPool)
-			instance.Status.UnreadyNum += 1
+			setPoolRevision(instance, desired, false)
+			updatingPools[pool.Name] = true
 		}
 	}
 	if removedPools != nil {
-		klog.V(4).Infof("removed pool list is %s", removedPools)
-		isYurtIngressCRChanged = true
+		klog.V(4).Infof("removed pool list is %v", removedPools)
 		for _, pool := range removedPools {
-			if desiredPools == nil {
-				if err := yurtapputil.DeleteNginxIngressSpecificResource(r.Client, pool.Name, true); err != nil {
-					return ctrl.Result{}, err
-				}
-			} else {
-				if err := yurtapputil.DeleteNginxIngressSpecificResource(r.Client, pool.Name, false); err != nil {
-					return ctrl.Result{}, err
-				}
+			if err := ingressBackend.DeletePoolResource(r.Client, newBackendPool(instance, pool), desiredPools == nil); err != nil {
+				return ctrl.Result{}, err
 			}
 			if desiredPools != nil && !removePoolfromCondition(instance, pool.Name) {
 				klog.V(4).Infof("Pool/%s is not found from conditions!", pool.Name)
 			}
 		}
-		if desiredPools == nil && isOnlyYurtIngressCR(r.Client) {
-			if err := yurtapputil.DeleteNginxIngressCommonResource(r.Client); err != nil {
+		if desiredPools == nil {
+			if err := ingressBackend.DeleteCommonResource(r.Client, prepareDeploymentOwnerReferences(instance)); err != nil {
 				return ctrl.Result{}, err
 			}
 			instance.Status.Conditions.IngressReadyPools = nil
@@ -196,55 +258,195 @@ func (r *YurtIngressReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 	if unchangedPools != nil {
-		klog.V(4).Infof("unchanged pool list is %s", unchangedPools)
-		desiredReplicas := instance.Spec.Replicas
-		currentReplicas := instance.Status.Replicas
-		desiredIngressControllerImage := instance.Spec.IngressControllerImage
-		currentIngressControllerImage := instance.Status.IngressControllerImage
-		desiredNginxWebhookCertGenImage := instance.Spec.IngressWebhookCertGenImage
-		currentNginxWebhookCertGenImage := instance.Status.IngressWebhookCertGenImage
-		if desiredIngressControllerImage != currentIngressControllerImage {
-			klog.V(4).Infof("Ingress controller image is changed!")
-			isYurtIngressCRChanged = true
-			instance.Status.ReadyNum = 0
-			instance.Status.UnreadyNum = int32(len(instance.Spec.Pools))
-			for _, pool := range unchangedPools {
-				if err := yurtapputil.UpdateNginxIngressControllerDeploymment(r.Client, pool.Name, desiredReplicas, desiredIngressControllerImage); err != nil {
+		klog.V(4).Infof("unchanged pool list is %v", unchangedPools)
+		desiredWebhookCertGenImage := instance.Spec.IngressWebhookCertGenImage
+		currentWebhookCertGenImage := instance.Status.IngressWebhookCertGenImage
+		var changedPools []string
+		for _, pool := range unchangedPools {
+			if currentPool := getCurrentPool(instance, pool.Name); currentPool != nil &&
+				getPoolRevision(instance, newCurrentBackendPool(instance, *currentPool)) !=
+					controllerRevision(newBackendPool(instance, pool)) {
+				changedPools = append(changedPools, pool.Name)
+			}
+		}
+		rolloutPools := planRollout(instance, desiredPools, changedPools)
+		for _, pool := range unchangedPools {
+			currentPool := getCurrentPool(instance, pool.Name)
+			if currentPool == nil {
+				continue
+			}
+			desired := newBackendPool(instance, pool)
+			current := newCurrentBackendPool(instance, *currentPool)
+			isPoolChanged := false
+			if deleteMissingPools && checkNodePool(nodePools, pool.Name) != nil {
+				if poolDeployments[pool.Name] != nil {
+					klog.V(4).Infof("NodePool of pool %s is missing or empty, delete the ingress controller", pool.Name)
+					if err := ingressBackend.DeletePoolResource(r.Client, current, false); err != nil {
+						return ctrl.Result{}, err
+					}
+				}
+				continue
+			}
+			notReadyReason := getNotReadyReason(instance, pool.Name)
+			if notReadyReason == appsv1alpha1.IngressControllerNotFound ||
+				(isNodePoolReason(notReadyReason) && poolDeployments[pool.Name] == nil) {
+				klog.V(4).Infof("Ingress controller of pool %s is not found, recreate it", pool.Name)
+				isPoolChanged = true
+				if err := ingressBackend.CreatePoolResource(r.Client, desired,
+					prepareDeploymentOwnerReferences(instance)); err != nil {
 					return ctrl.Result{}, err
 				}
+				setPoolRevision(instance, desired, false)
 			}
-		} else if desiredReplicas != currentReplicas {
-			klog.V(4).Infof("Ingress controller replicas is changed!")
-			isYurtIngressCRChanged = true
-			for _, pool := range unchangedPools {
-				if err := yurtapputil.ScaleNginxIngressControllerDeploymment(r.Client, pool.Name, desiredReplicas); err != nil {
+			// the ingress controllers not updated in this reconcile keep the applied revisions
+			setPoolRevision(instance, current, true)
+			if rolloutPools[pool.Name] {
+				klog.V(4).Infof("Ingress controller of pool %s is changed!", pool.Name)
+				isPoolChanged = true
+				if err := ingressBackend.UpdateController(r.Client, desired); err != nil {
+					return ctrl.Result{}, err
+				}
+				setPoolRevision(instance, desired, false)
+			} else if desired.Replicas != current.Replicas {
+				klog.V(4).Infof("Ingress controller replicas of pool %s is changed!", pool.Name)
+				isPoolChanged = true
+				if err := ingressBackend.Scale(r.Client, desired); err != nil {
 					return ctrl.Result{}, err
 				}
 			}
-		}
-		if desiredNginxWebhookCertGenImage != currentNginxWebhookCertGenImage {
-			klog.V(4).Infof("Nginx ingress controller webhook certgen image is changed!")
-			isYurtIngressCRChanged = true
-			for _, pool := range unchangedPools {
-				if err := yurtapputil.RecreateNginxWebhookJob(r.Client, pool.Name, desiredNginxWebhookCertGenImage); err != nil {
+			if desiredWebhookCertGenImage != currentWebhookCertGenImage {
+				klog.V(4).Infof("Ingress controller webhook certgen image of pool %s is changed!", pool.Name)
+				isPoolChanged = true
+				if err := ingressBackend.UpdateWebhookCertGenImage(r.Client, desired); err != nil {
 					return ctrl.Result{}, err
 				}
 			}
-		}
-		for _, pool := range unchangedPools {
-			currentPool := getCurrentPool(instance, pool.Name)
-			if currentPool != nil {
-				if !isStrArrayEqual(pool.IngressIPs, currentPool.IngressIPs) {
-					klog.V(4).Infof("pool %s ingressIPs is changed", pool.Name)
-					if err := yurtapputil.UpdateNginxServiceExternalIPs(r.Client, pool.Name, pool.IngressIPs); err != nil {
-						return ctrl.Result{}, err
-					}
+			renewTime, err := ingressBackend.EnsureWebhookCertificate(r.Client, desired)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !renewTime.IsZero() && (certRenewTime.IsZero() || renewTime.Before(certRenewTime)) {
+				certRenewTime = renewTime
+			}
+			if !isStrArrayEqual(pool.IngressIPs, currentPool.IngressIPs) ||
+				!apiequality.Semantic.DeepEqual(pool.ServiceAnnotations, currentPool.ServiceAnnotations) ||
+				!apiequality.Semantic.DeepEqual(pool.Service, currentPool.Service) {
+				klog.V(4).Infof("pool %s ingressIPs or service is changed", pool.Name)
+				if err := ingressBackend.UpdateService(r.Client, desired); err != nil {
+					return ctrl.Result{}, err
 				}
 			}
+			if isPoolChanged {
+				updatingPools[pool.Name] = true
+			}
 		}
 	}
-	r.updateStatus(instance, isYurtIngressCRChanged)
-	return ctrl.Result{}, nil
+	if err := r.updateStatus(instance, ingressBackend, desiredPools, nodePools, updatingPools); err != nil {
+		return ctrl.Result{}, err
+	}
+	result := ctrl.Result{}
+	if instance.Status.UnreadyNum > 0 {
+		// the jobs, endpoints and webhooks of the pools are not watched, so check them again later
+		result.RequeueAfter = notReadyRequeueInterval
+	}
+	if !certRenewTime.IsZero() {
+		if d := time.Until(certRenewTime); result.RequeueAfter == 0 || d < result.RequeueAfter {
+			result.RequeueAfter = d
+		}
+		if result.RequeueAfter <= 0 {
+			result.RequeueAfter = notReadyRequeueInterval
+		}
+	}
+	return result, nil
+}
+
+// removeConflictingPools removes the pools owned by other YurtIngresses from the desired pools. They are removed from
+// the status too, but their ingress controllers are not deleted, since they are the same ones as their owners'.
+func (r *YurtIngressReconciler) removeConflictingPools(ying *appsv1alpha1.YurtIngress, pools []appsv1alpha1.IngressPool,
+	conflicts map[string]string) []appsv1alpha1.IngressPool {
+	if len(conflicts) == 0 {
+		return pools
+	}
+	var owned []appsv1alpha1.IngressPool
+	for _, pool := range pools {
+		owner, ok := conflicts[pool.Name]
+		if !ok {
+			owned = append(owned, pool)
+			continue
+		}
+		klog.V(4).Infof("Pool %s of YurtIngress %s is owned by YurtIngress %s", pool.Name, ying.Name, owner)
+		if r.recorder != nil {
+			r.recorder.Eventf(ying, corev1.EventTypeWarning, "PoolConflict",
+				"pool %s is enabled by YurtIngress %s already", pool.Name, owner)
+		}
+		removePoolfromCondition(ying, pool.Name)
+	}
+	return owned
+}
+
+// newBackendPool returns the desired ingress controller of the pool.
+func newBackendPool(ying *appsv1alpha1.YurtIngress, pool appsv1alpha1.IngressPool) *backend.Pool {
+	p := toBackendPool(pool, ying.Spec.Replicas, ying.Spec.IngressControllerImage, ying.Spec.IngressWebhookCertGenImage,
+		ying.Spec.Config)
+	p.Namespace, p.NamePrefix = backend.Namespace(ying)
+	p.TemplateVersion = backend.TemplateVersion(ying)
+	return p
+}
+
+// newCurrentBackendPool returns the ingress controller of the pool recorded in the status.
+func newCurrentBackendPool(ying *appsv1alpha1.YurtIngress, pool appsv1alpha1.IngressPool) *backend.Pool {
+	p := toBackendPool(pool, ying.Status.Replicas, ying.Status.IngressControllerImage, ying.Status.IngressWebhookCertGenImage,
+		ying.Status.Config)
+	p.Namespace, p.NamePrefix = backend.Namespace(ying)
+	if status := getPoolStatus(ying, pool.Name); status != nil {
+		p.TemplateVersion = status.TemplateVersion
+	}
+	return p
+}
+
+// toBackendPool applies the per-pool overrides to the replicas, image and config shared by all the pools.
+func toBackendPool(pool appsv1alpha1.IngressPool, replicas int32, image, webhookCertGenImage string,
+	config map[string]string) *backend.Pool {
+	if pool.Replicas != nil {
+		replicas = *pool.Replicas
+	}
+	if pool.Image != "" {
+		image = pool.Image
+	}
+	var poolConfig map[string]string
+	if len(config)+len(pool.Config) > 0 {
+		poolConfig = make(map[string]string, len(config)+len(pool.Config))
+		for k, v := range config {
+			poolConfig[k] = v
+		}
+		for k, v := range pool.Config {
+			poolConfig[k] = v
+		}
+	}
+	return &backend.Pool{
+		Name:                pool.Name,
+		IngressIPs:          pool.IngressIPs,
+		Replicas:            replicas,
+		Image:               image,
+		WebhookCertGenImage: webhookCertGenImage,
+		Resources:           pool.Resources,
+		ExtraArgs:           pool.ExtraArgs,
+		NodeSelector:        pool.NodeSelector,
+		Tolerations:         pool.Tolerations,
+		ServiceAnnotations:  pool.ServiceAnnotations,
+		Service:             pool.Service,
+		Config:              poolConfig,
+	}
+}
+
+// getNotReadyReason returns the reason recorded in the conditions why the pool is not ready.
+func getNotReadyReason(ying *appsv1alpha1.YurtIngress, poolname string) string {
+	for _, pool := range ying.Status.Conditions.IngressNotReadyPools {
+		if pool.Pool.Name == poolname && pool.Info != nil {
+			return pool.Info.Reason
+		}
+	}
+	return ""
 }
 
 func isStrArrayEqual(strList1, strList2 []string) bool {
@@ -292,10 +494,6 @@ func getPools(desired, current []appsv1alpha1.IngressPool) (added, removed, unch
 	return added, removed, unchanged
 }
 
-func getDesiredPools(ying *appsv1alpha1.YurtIngress) []appsv1alpha1.IngressPool {
-	return ying.Spec.Pools
-}
-
 func getCurrentPools(ying *appsv1alpha1.YurtIngress) []appsv1alpha1.IngressPool {
 	var currentPools []appsv1alpha1.IngressPool
 	currentPools = ying.Status.Conditions.IngressReadyPools
@@ -305,16 +503,6 @@ func getCurrentPools(ying *appsv1alpha1.YurtIngress) []appsv1alpha1.IngressPool
 	return currentPools
 }
 
-func getDesiredPool(ying *appsv1alpha1.YurtIngress, poolname string) *appsv1alpha1.IngressPool {
-	for _, pool := range ying.Spec.Pools {
-		if pool.Name == poolname {
-			return &pool
-		}
-	}
-	klog.V(4).Infof("Can not find desired pool %s", poolname)
-	return nil
-}
-
 func getCurrentPool(ying *appsv1alpha1.YurtIngress, poolname string) *appsv1alpha1.IngressPool {
 	for _, pool := range getCurrentPools(ying) {
 		if pool.Name == poolname {
@@ -359,39 +547,57 @@ func removePoolfromCondition(ying *appsv1alpha1.YurtIngress, poolname string) bo
 	return false
 }
 
-func (r *YurtIngressReconciler) updateStatus(ying *appsv1alpha1.YurtIngress, ingressCRChanged bool) error {
+// updateStatus checks the ingress controllers of all the desired pools, and records which are ready and why the others
+// are not. The pools in updatingPools are not ready, since their ingress controllers are just created or updated,
+// neither are the pools whose NodePools are missing or empty.
+func (r *YurtIngressReconciler) updateStatus(ying *appsv1alpha1.YurtIngress, ingressBackend backend.Backend,
+	pools []appsv1alpha1.IngressPool, nodePools map[string]*appsv1alpha1.NodePool, updatingPools map[string]bool) error {
 	ying.Status.Replicas = ying.Spec.Replicas
 	ying.Status.IngressControllerImage = ying.Spec.IngressControllerImage
 	ying.Status.IngressWebhookCertGenImage = ying.Spec.IngressWebhookCertGenImage
-	if !ingressCRChanged {
-		deployments, err := r.getAllDeployments(ying)
-		if err != nil {
-			klog.V(4).Infof("Fail to get all the ingress controller deployments: %v", err)
-			return err
-		}
-		ying.Status.Conditions.IngressReadyPools = nil
-		ying.Status.Conditions.IngressNotReadyPools = nil
-		ying.Status.ReadyNum = 0
-		for _, dply := range deployments {
-			pool := dply.ObjectMeta.GetLabels()[ingressDeploymentLabel]
-			if dply.Status.ReadyReplicas == ying.Spec.Replicas {
-				klog.V(4).Infof("Ingress on pool %s is ready!", pool)
-				ying.Status.ReadyNum += 1
-				readyPool := getDesiredPool(ying, pool)
-				ying.Status.Conditions.IngressReadyPools = append(ying.Status.Conditions.IngressReadyPools, *readyPool)
-			} else {
-				klog.V(4).Infof("Ingress on pool %s is NOT ready!", pool)
-				condition := getUnreadyDeploymentCondition(dply)
-				if condition == nil {
-					klog.V(4).Infof("Get deployment/%s conditions nil!", dply.GetName())
-				} else {
-					notReadyPool := appsv1alpha1.IngressNotReadyPool{Pool: *getDesiredPool(ying, pool), Info: condition}
-					ying.Status.Conditions.IngressNotReadyPools = append(ying.Status.Conditions.IngressNotReadyPools, notReadyPool)
-				}
+	ying.Status.Config = ying.Spec.Config
+	ying.Status.ObservedGeneration = ying.Generation
+	poolDeployments, err := r.getPoolDeployments(ying)
+	if err != nil {
+		klog.V(4).Infof("Fail to get all the ingress controller deployments: %v", err)
+		return err
+	}
+	lastNotReadyPools := ying.Status.Conditions.IngressNotReadyPools
+	ying.Status.Conditions.IngressReadyPools = nil
+	ying.Status.Conditions.IngressNotReadyPools = nil
+	ying.Status.ReadyNum = 0
+	for _, pool := range pools {
+		var ready bool
+		var info *appsv1alpha1.IngressNotReadyConditionInfo
+		if npInfo := checkNodePool(nodePools, pool.Name); npInfo != nil {
+			info = npInfo
+		} else if updatingPools[pool.Name] {
+			info = &appsv1alpha1.IngressNotReadyConditionInfo{
+				Type:    appsv1alpha1.IngressPending,
+				Reason:  appsv1alpha1.IngressControllerUpdating,
+				Message: "the ingress controller of the pool is being deployed",
 			}
+		} else {
+			ready, info = ingressBackend.IsPoolReady(r.Client, newBackendPool(ying, pool), poolDeployments[pool.Name])
 		}
-		ying.Status.UnreadyNum = int32(len(ying.Spec.Pools)) - ying.Status.ReadyNum
-	}
+		if ready {
+			klog.V(4).Infof("Ingress on pool %s is ready!", pool.Name)
+			ying.Status.ReadyNum += 1
+			ying.Status.Conditions.IngressReadyPools = append(ying.Status.Conditions.IngressReadyPools, pool)
+			continue
+		}
+		klog.V(4).Infof("Ingress on pool %s is NOT ready: %v", pool.Name, info)
+		if info != nil && info.LastTransitionTime.IsZero() {
+			info.LastTransitionTime = getLastTransitionTime(lastNotReadyPools, pool.Name, info)
+		}
+		notReadyPool := appsv1alpha1.IngressNotReadyPool{Pool: pool, Info: info}
+		ying.Status.Conditions.IngressNotReadyPools = append(ying.Status.Conditions.IngressNotReadyPools, notReadyPool)
+	}
+	ying.Status.UnreadyNum = int32(len(pools)) - ying.Status.ReadyNum
+	setYurtIngressConditions(ying)
+	ying.Status.Pools = getPoolStatuses(r.Client, ying, ingressBackend, pools)
+	ying.Status.UpdatedNum = getUpdatedNum(ying, pools)
+	recordMetrics(ying)
 	var updateErr error
 	for i, obj := 0, ying; i < updateRetries; i++ {
 		updateErr = r.Status().Update(context.TODO(), obj)
@@ -404,9 +610,110 @@ func (r *YurtIngressReconciler) updateStatus(ying *appsv1alpha1.YurtIngress, ing
 	return updateErr
 }
 
+// recordMetrics records the readiness of the ingress controllers of the pools of the YurtIngress.
+func recordMetrics(ying *appsv1alpha1.YurtIngress) {
+	ready := make([]string, 0, len(ying.Status.Conditions.IngressReadyPools))
+	for _, pool := range ying.Status.Conditions.IngressReadyPools {
+		ready = append(ready, pool.Name)
+	}
+	notReady := make([]string, 0, len(ying.Status.Conditions.IngressNotReadyPools))
+	for _, pool := range ying.Status.Conditions.IngressNotReadyPools {
+		notReady = append(notReady, pool.Pool.Name)
+	}
+	metrics.SetYurtIngressPools(ying.Name, ready, notReady)
+}
+
+// getLastTransitionTime keeps the transition time of the pool if it is not ready for the same reason as last time.
+func getLastTransitionTime(lastNotReadyPools []appsv1alpha1.IngressNotReadyPool, poolname string,
+	info *appsv1alpha1.IngressNotReadyConditionInfo) metav1.Time {
+	for _, pool := range lastNotReadyPools {
+		if pool.Pool.Name == poolname && pool.Info != nil && pool.Info.Type == info.Type &&
+			pool.Info.Reason == info.Reason && !pool.Info.LastTransitionTime.IsZero() {
+			return pool.Info.LastTransitionTime
+		}
+	}
+	return metav1.Now()
+}
+
+// setYurtIngressConditions sets the Ready and Degraded conditions of the YurtIngress from the pool conditions.
+func setYurtIngressConditions(ying *appsv1alpha1.YurtIngress) {
+	var notReady, failed []string
+	for _, pool := range ying.Status.Conditions.IngressNotReadyPools {
+		notReady = append(notReady, pool.Pool.Name)
+		if pool.Info != nil && pool.Info.Type == appsv1alpha1.IngressFailure {
+			failed = append(failed, pool.Pool.Name)
+		}
+	}
+	ready := metav1.Condition{
+		Type:               appsv1alpha1.YurtIngressReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: ying.Generation,
+		Reason:             "AllPoolsReady",
+		Message:            "the ingress controllers of all the pools are ready",
+	}
+	if len(notReady) > 0 {
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "PoolsNotReady"
+		ready.Message = fmt.Sprintf("the ingress controllers of pools %s are not ready", strings.Join(notReady, ", "))
+	}
+	meta.SetStatusCondition(&ying.Status.IngressConditions, ready)
+	degraded := metav1.Condition{
+		Type:               appsv1alpha1.YurtIngressDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: ying.Generation,
+		Reason:             "NoPoolsFailed",
+		Message:            "no ingress controller of the pools fails",
+	}
+	if len(failed) > 0 {
+		degraded.Status = metav1.ConditionTrue
+		degraded.Reason = "PoolsFailed"
+		degraded.Message = fmt.Sprintf("the ingress controllers of pools %s fail", strings.Join(failed, ", "))
+	}
+	meta.SetStatusCondition(&ying.Status.IngressConditions, degraded)
+}
+
+// getPoolStatuses returns the endpoints of the ingress controllers of the desired pools,
+// together with the recorded revisions of the ingress controllers applied to them.
+func getPoolStatuses(c client.Client, ying *appsv1alpha1.YurtIngress, ingressBackend backend.Backend,
+	pools []appsv1alpha1.IngressPool) []appsv1alpha1.IngressPoolStatus {
+	var statuses []appsv1alpha1.IngressPoolStatus
+	for _, pool := range pools {
+		endpoints, err := ingressBackend.GetEndpoints(c, newBackendPool(ying, pool))
+		if err != nil {
+			klog.Errorf("Fail to get the ingress controller endpoints of pool %s: %v", pool.Name, err)
+		}
+		status := appsv1alpha1.IngressPoolStatus{Name: pool.Name, Endpoints: endpoints}
+		if recorded := getPoolStatus(ying, pool.Name); recorded != nil {
+			status.Image = recorded.Image
+			status.ControllerRevision = recorded.ControllerRevision
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// getUpdatedNum returns the number of the desired pools whose ingress controllers are updated to the spec.
+func getUpdatedNum(ying *appsv1alpha1.YurtIngress, pools []appsv1alpha1.IngressPool) int32 {
+	var updated int32
+	for _, pool := range pools {
+		if status := getPoolStatus(ying, pool.Name); status != nil &&
+			status.ControllerRevision == controllerRevision(newBackendPool(ying, pool)) {
+			updated++
+		}
+	}
+	return updated
+}
+
 func (r *YurtIngressReconciler) cleanupIngressResources(instance *appsv1alpha1.YurtIngress) (ctrl.Result, error) {
-	pools := getDesiredPools(instance)
-	isOnly := isOnlyYurtIngressCR(r.Client)
+	// the pools selected by the pool selector are recorded in the status
+	pools := append([]appsv1alpha1.IngressPool{}, getCurrentPools(instance)...)
+	added, _, _ := getPools(instance.Spec.Pools, pools)
+	pools = append(pools, added...)
+	ingressBackend, err := backend.New(r.Client, instance)
+	if err != nil {
+		// the resources of the pools are still garbage collected through the owner references
+		klog.Errorf("Fail to get the ingress controller backend of YurtIngress %s: %v", instance.Name, err)
+	}
 
 	if controllerutil.ContainsFinalizer(instance, appsv1alpha1.YurtIngressFinalizer) {
 		controllerutil.RemoveFinalizer(instance, appsv1alpha1.YurtIngressFinalizer)
@@ -414,16 +721,15 @@ func (r *YurtIngressReconciler) cleanupIngressResources(instance *appsv1alpha1.Y
 			return ctrl.Result{}, err
 		}
 	}
-	if pools != nil {
+	if ingressBackend != nil {
 		for _, pool := range pools {
-			if err := yurtapputil.DeleteNginxIngressSpecificResource(r.Client, pool.Name, isOnly); err != nil {
+			if err := ingressBackend.DeletePoolResource(r.Client, newBackendPool(instance, pool), true); err != nil {
 				return ctrl.Result{}, err
 			}
 		}
-		if isOnly {
-			if err := yurtapputil.DeleteNginxIngressCommonResource(r.Client); err != nil {
-				return ctrl.Result{}, err
-			}
+		// the common resources are deleted only if no other YurtIngress owns them
+		if err := ingressBackend.DeleteCommonResource(r.Client, prepareDeploymentOwnerReferences(instance)); err != nil {
+			return ctrl.Result{}, err
 		}
 	}
 	return ctrl.Result{}, nil
@@ -444,6 +750,19 @@ func prepareDeploymentOwnerReferences(instance *appsv1alpha1.YurtIngress) *metav
 	return &ownerRef
 }
 
+// getPoolDeployments returns the ingress controller deployments owned by YurtIngress by their pools.
+func (r *YurtIngressReconciler) getPoolDeployments(ying *appsv1alpha1.YurtIngress) (map[string]*appsv1.Deployment, error) {
+	deployments, err := r.getAllDeployments(ying)
+	if err != nil {
+		return nil, err
+	}
+	poolDeployments := make(map[string]*appsv1.Deployment, len(deployments))
+	for _, dply := range deployments {
+		poolDeployments[dply.ObjectMeta.GetLabels()[ingressDeploymentLabel]] = dply
+	}
+	return poolDeployments, nil
+}
+
 // getAllDeployments returns all of deployments owned by YurtIngress
 func (r *YurtIngressReconciler) getAllDeployments(ying *appsv1alpha1.YurtIngress) ([]*appsv1.Deployment, error) {
 	labelSelector := metav1.LabelSelector{
@@ -460,7 +779,11 @@ func (r *YurtIngressReconciler) getAllDeployments(ying *appsv1alpha1.YurtIngress
 	}
 
 	dplyList := &appsv1.DeploymentList{}
-	err = r.Client.List(context.TODO(), dplyList, &client.ListOptions{LabelSelector: selector})
+	listOptions := &client.ListOptions{LabelSelector: selector}
+	if namespace, _ := backend.Namespace(ying); namespace != "" {
+		listOptions.Namespace = namespace
+	}
+	err = r.Client.List(context.TODO(), dplyList, listOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -485,31 +808,3 @@ func (r *YurtIngressReconciler) getAllDeployments(ying *appsv1alpha1.YurtIngress
 	}
 	return claimedDplys, nil
 }
-
-func getUnreadyDeploymentCondition(dply *appsv1.Deployment) (info *appsv1alpha1.IngressNotReadyConditionInfo) {
-	len := len(dply.Status.Conditions)
-	if len == 0 {
-		return nil
-	}
-	var conditionInfo appsv1alpha1.IngressNotReadyConditionInfo
-	condition := dply.Status.Conditions[len-1]
-	if condition.Type == appsv1.DeploymentReplicaFailure {
-		conditionInfo.Type = appsv1alpha1.IngressFailure
-	} else {
-		conditionInfo.Type = appsv1alpha1.IngressPending
-	}
-	conditionInfo.LastTransitionTime = condition.LastTransitionTime
-	conditionInfo.Message = condition.Message
-	conditionInfo.Reason = condition.Reason
-	return &conditionInfo
-}
-
-func isOnlyYurtIngressCR(c client.Client) bool {
-	ingressList := appsv1alpha1.YurtIngressList{}
-	err := c.List(context.TODO(), &ingressList, &client.ListOptions{})
-	if err != nil {
-		klog.V(4).Infof("Get yurtingress list err: %v", err)
-		return false
-	}
-	return len(ingressList.Items) == 1
-}
@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	ying := &appsv1alpha1.YurtIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ying", UID: "ying-uid", Generation: 3},
+		Spec: appsv1alpha1.YurtIngressSpec{
+			ControllerType: appsv1alpha1.TraefikIngressController,
+			Replicas:       1,
+			Pools:          []appsv1alpha1.IngressPool{{Name: "ready"}, {Name: "missing"}, {Name: "updating"}},
+		},
+	}
+	lastTransitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	ying.Status.Conditions.IngressNotReadyPools = []appsv1alpha1.IngressNotReadyPool{{
+		Pool: appsv1alpha1.IngressPool{Name: "missing"},
+		Info: &appsv1alpha1.IngressNotReadyConditionInfo{
+			Type:               appsv1alpha1.IngressPending,
+			Reason:             appsv1alpha1.IngressControllerNotFound,
+			LastTransitionTime: lastTransitionTime,
+		},
+	}}
+	isController := true
+	dply := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ingress-traefik",
+			Name:      "ready-traefik",
+			Labels:    map[string]string{ingressDeploymentLabel: "ready"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps.openyurt.io/v1alpha1",
+				Kind:       "YurtIngress",
+				Name:       "ying",
+				UID:        "ying-uid",
+				Controller: &isController,
+			}},
+		},
+		Status: appsv1.DeploymentStatus{AvailableReplicas: 1, UpdatedReplicas: 1},
+	}
+	eps := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ingress-traefik", Name: "ready-traefik"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.244.0.10"}},
+		}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ying, dply, eps).Build()
+	r := &YurtIngressReconciler{Client: c, Scheme: scheme}
+
+	if err := r.updateStatus(ying, &backend.TraefikBackend{}, ying.Spec.Pools, nil, map[string]bool{"updating": true}); err != nil {
+		t.Fatalf("fail to update status: %v", err)
+	}
+	got := &appsv1alpha1.YurtIngress{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "ying"}, got); err != nil {
+		t.Fatalf("fail to get YurtIngress: %v", err)
+	}
+	if got.Status.ObservedGeneration != 3 || got.Status.ReadyNum != 1 || got.Status.UnreadyNum != 2 {
+		t.Fatalf("unexpected status %+v", got.Status)
+	}
+	if len(got.Status.Conditions.IngressReadyPools) != 1 || got.Status.Conditions.IngressReadyPools[0].Name != "ready" {
+		t.Fatalf("unexpected ready pools %v", got.Status.Conditions.IngressReadyPools)
+	}
+	reasons := make(map[string]*appsv1alpha1.IngressNotReadyConditionInfo)
+	for _, pool := range got.Status.Conditions.IngressNotReadyPools {
+		reasons[pool.Pool.Name] = pool.Info
+	}
+	if info := reasons["missing"]; info == nil || info.Reason != appsv1alpha1.IngressControllerNotFound ||
+		!info.LastTransitionTime.Equal(&lastTransitionTime) {
+		t.Fatalf("expected the missing pool to keep its transition time, got %v", info)
+	}
+	if info := reasons["updating"]; info == nil || info.Reason != appsv1alpha1.IngressControllerUpdating {
+		t.Fatalf("expected the updating pool to be updating, got %v", info)
+	}
+	if getNotReadyReason(got, "missing") != appsv1alpha1.IngressControllerNotFound {
+		t.Fatalf("expected the missing pool to be recreated in the next reconcile")
+	}
+	if !meta.IsStatusConditionFalse(got.Status.IngressConditions, appsv1alpha1.YurtIngressReady) ||
+		!meta.IsStatusConditionFalse(got.Status.IngressConditions, appsv1alpha1.YurtIngressDegraded) {
+		t.Fatalf("unexpected conditions %v", got.Status.IngressConditions)
+	}
+
+	got.Spec.Pools = got.Spec.Pools[:1]
+	if err := r.updateStatus(got, &backend.TraefikBackend{}, got.Spec.Pools, nil, nil); err != nil {
+		t.Fatalf("fail to update status: %v", err)
+	}
+	if got.Status.UnreadyNum != 0 || !meta.IsStatusConditionTrue(got.Status.IngressConditions, appsv1alpha1.YurtIngressReady) {
+		t.Fatalf("expected YurtIngress to be ready, got %+v", got.Status)
+	}
+}
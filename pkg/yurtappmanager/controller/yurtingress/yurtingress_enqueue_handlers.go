@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package yurtingress
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+// EnqueueYurtIngressForPoolOwners enqueues the other YurtIngresses deploying the same ingress controllers,
+// when a YurtIngress is created, deleted or its spec changes, since the owners of their pools may change.
+type EnqueueYurtIngressForPoolOwners struct {
+	client client.Client
+}
+
+func (e *EnqueueYurtIngressForPoolOwners) Create(event event.CreateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtIngressesToWorkQueue(event.Object, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForPoolOwners) Update(event event.UpdateEvent, limitingInterface workqueue.RateLimitingInterface) {
+	if event.ObjectOld.GetGeneration() == event.ObjectNew.GetGeneration() &&
+		event.ObjectOld.GetDeletionTimestamp().IsZero() == event.ObjectNew.GetDeletionTimestamp().IsZero() {
+		return
+	}
+	e.addYurtIngressesToWorkQueue(event.ObjectNew, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForPoolOwners) Delete(event event.DeleteEvent, limitingInterface workqueue.RateLimitingInterface) {
+	e.addYurtIngressesToWorkQueue(event.Object, limitingInterface)
+}
+
+func (e *EnqueueYurtIngressForPoolOwners) Generic(event event.GenericEvent, limitingInterface workqueue.RateLimitingInterface) {
+	return
+}
+
+func (e *EnqueueYurtIngressForPoolOwners) addYurtIngressesToWorkQueue(obj client.Object, q workqueue.RateLimitingInterface) {
+	ying, ok := obj.(*appsv1alpha1.YurtIngress)
+	if !ok {
+		return
+	}
+	yings := &appsv1alpha1.YurtIngressList{}
+	if err := e.client.List(context.TODO(), yings); err != nil {
+		klog.Errorf("Fail to list YurtIngresses for YurtIngress %s: %v", ying.Name, err)
+		return
+	}
+	for i := range yings.Items {
+		if yings.Items[i].Name != ying.Name && backend.IsSameControllers(ying, &yings.Items[i]) {
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: yings.Items[i].GetName()}})
+		}
+	}
+}
+
+var _ handler.EventHandler = &EnqueueYurtIngressForPoolOwners{}
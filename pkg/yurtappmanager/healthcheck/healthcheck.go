@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package healthcheck defines the readiness and liveness checks of yurt-app-manager, each of them is served as
+// a named sub-check of /readyz or /healthz.
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// checkTimeout is the max time a check waits for, which is shorter than the timeout of the probes
+const checkTimeout = time.Second
+
+// Options is the settings of the checks
+type Options struct {
+	// WebhookAddr is the local address of the webhook server
+	WebhookAddr string
+	// WebhookDNSName is the name of the webhook service the serving certificate must be valid for, the name is
+	// not checked if empty
+	WebhookDNSName string
+	// LeaderElection tells whether the leader election is enabled
+	LeaderElection bool
+	// LeaderElectionLease is the namespace/name of the lease of the leader election
+	LeaderElectionLease types.NamespacedName
+	// StuckThreshold is the time a work queue with items makes no progress, or a reconcile runs,
+	// before the reconcile workers are considered stuck
+	StuckThreshold time.Duration
+}
+
+// AddChecks adds the readiness checks of the cache, the webhook server and the leader election,
+// and the liveness check of the reconcile workers to the manager.
+func AddChecks(mgr manager.Manager, opts Options) error {
+	readyz := map[string]healthz.Checker{
+		"cache-sync":      CacheSyncChecker(mgr.GetCache()),
+		"webhook":         WebhookChecker(opts.WebhookAddr, opts.WebhookDNSName),
+		"leader-election": LeaderElectionChecker(opts.LeaderElection, mgr.Elected(), mgr.GetAPIReader(), opts.LeaderElectionLease),
+	}
+	for name, check := range readyz {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			return err
+		}
+	}
+
+	healthz := map[string]healthz.Checker{
+		"ping":       healthz.Ping,
+		"workqueues": NewWorkQueueChecker(metrics.Registry, opts.StuckThreshold).Check,
+	}
+	for name, check := range healthz {
+		if err := mgr.AddHealthzCheck(name, check); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CacheSyncChecker fails until the informers of the cache are started and synced.
+func CacheSyncChecker(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return fmt.Errorf("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// LeaderElectionChecker fails unless the leader election is disabled, the replica is elected, or another
+// replica holds the unexpired lease, that is the leader election is working.
+func LeaderElectionChecker(enabled bool, elected <-chan struct{}, c client.Reader, lease types.NamespacedName) healthz.Checker {
+	return func(req *http.Request) error {
+		if !enabled {
+			return nil
+		}
+		select {
+		case <-elected:
+			return nil
+		default:
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), checkTimeout)
+		defer cancel()
+		l := &coordinationv1.Lease{}
+		if err := c.Get(ctx, lease, l); err != nil {
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("no leader is elected, lease %s is not found", lease)
+			}
+			return fmt.Errorf("fail to get lease %s: %v", lease, err)
+		}
+		if l.Spec.HolderIdentity == nil || *l.Spec.HolderIdentity == "" || l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+			return fmt.Errorf("no leader is elected, lease %s is not held", lease)
+		}
+		expire := l.Spec.RenewTime.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second)
+		if time.Now().After(expire) {
+			return fmt.Errorf("lease %s of leader %s expired at %s", lease, *l.Spec.HolderIdentity, expire.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	utilpointer "k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCacheSyncChecker(t *testing.T) {
+	synced := false
+	check := CacheSyncChecker(&informertest.FakeInformers{Synced: &synced})
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	if err := check(req); err == nil {
+		t.Errorf("expect an error before the caches are synced")
+	}
+	synced = true
+	if err := check(req); err != nil {
+		t.Errorf("expect no error once the caches are synced, got %v", err)
+	}
+}
+
+func TestWebhookChecker(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	addr := server.Listener.Addr().String()
+
+	// the certificate of httptest is valid for example.com
+	if err := WebhookChecker(addr, "example.com")(nil); err != nil {
+		t.Errorf("expect no error for the valid certificate, got %v", err)
+	}
+	if err := WebhookChecker(addr, "")(nil); err != nil {
+		t.Errorf("expect no error without checking the name, got %v", err)
+	}
+	if err := WebhookChecker(addr, "yurt-app-webhook-service.kube-system.svc")(nil); err == nil {
+		t.Errorf("expect an error for the certificate of another name")
+	}
+
+	server.Close()
+	if err := WebhookChecker(addr, "example.com")(nil); err == nil {
+		t.Errorf("expect an error once the webhook server is stopped")
+	}
+}
+
+func TestLeaderElectionChecker(t *testing.T) {
+	key := types.NamespacedName{Namespace: "kube-system", Name: "yurt-app-manager"}
+	newLease := func(renew time.Time) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       utilpointer.StringPtr("other"),
+				LeaseDurationSeconds: utilpointer.Int32Ptr(15),
+				RenewTime:            &metav1.MicroTime{Time: renew},
+			},
+		}
+	}
+	elected := make(chan struct{})
+	closed := make(chan struct{})
+	close(closed)
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	tests := []struct {
+		name    string
+		enabled bool
+		elected chan struct{}
+		lease   *coordinationv1.Lease
+		wantErr bool
+	}{
+		{name: "disabled", elected: elected},
+		{name: "elected", enabled: true, elected: closed},
+		{name: "no lease", enabled: true, elected: elected, wantErr: true},
+		{name: "held by another leader", enabled: true, elected: elected, lease: newLease(time.Now())},
+		{name: "expired lease", enabled: true, elected: elected, lease: newLease(time.Now().Add(-time.Minute)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme)
+			if tt.lease != nil {
+				builder = builder.WithObjects(tt.lease)
+			}
+			err := LeaderElectionChecker(tt.enabled, tt.elected, builder.Build(), key)(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expect error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWorkQueueChecker(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	depth := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: depthMetric}, []string{"name"})
+	workDuration := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: workDurationMetric}, []string{"name"})
+	longestRunning := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: longestRunningMetric}, []string{"name"})
+	registry.MustRegister(depth, workDuration, longestRunning)
+
+	now := time.Now()
+	c := NewWorkQueueChecker(registry, time.Minute)
+	c.now = func() time.Time { return now }
+
+	depth.WithLabelValues("yurtappset-controller").Set(0)
+	depth.WithLabelValues("nodepool-controller").Set(2)
+	workDuration.WithLabelValues("nodepool-controller").Observe(0.1)
+	if err := c.Check(nil); err != nil {
+		t.Fatalf("expect no error at the first check, got %v", err)
+	}
+
+	// the items of the nodepool controller are processed
+	now = now.Add(2 * time.Minute)
+	workDuration.WithLabelValues("nodepool-controller").Observe(0.1)
+	if err := c.Check(nil); err != nil {
+		t.Fatalf("expect no error while the items are processed, got %v", err)
+	}
+
+	// no item of the nodepool controller is processed
+	now = now.Add(2 * time.Minute)
+	err := c.Check(nil)
+	if err == nil || !strings.Contains(err.Error(), "2 items of nodepool-controller") {
+		t.Fatalf("expect the nodepool controller to be stuck, got %v", err)
+	}
+
+	// the queue is drained, and a reconcile of the yurtappset controller runs for too long
+	depth.WithLabelValues("nodepool-controller").Set(0)
+	longestRunning.WithLabelValues("yurtappset-controller").Set(300)
+	err = c.Check(nil)
+	if err == nil || strings.Contains(err.Error(), "nodepool-controller") || !strings.Contains(err.Error(), "yurtappset-controller has been running for 5m0s") {
+		t.Fatalf("expect only the yurtappset controller to be stuck, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// WebhookChecker fails unless the webhook server is serving on addr with a certificate which is currently valid,
+// and valid for dnsName if it is not empty.
+func WebhookChecker(addr, dnsName string) healthz.Checker {
+	return func(_ *http.Request) error {
+		dialer := &net.Dialer{Timeout: checkTimeout}
+		// the certificate is verified below, the CA which signs it may not be known by yurt-app-manager
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			return fmt.Errorf("webhook server is not serving on %s: %v", addr, err)
+		}
+		defer conn.Close()
+
+		certs := conn.ConnectionState().PeerCertificates
+		if len(certs) == 0 {
+			return fmt.Errorf("webhook server on %s serves no certificate", addr)
+		}
+		cert, now := certs[0], time.Now()
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("serving certificate of the webhook server is valid from %s to %s",
+				cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+		}
+		if dnsName != "" {
+			if err := cert.VerifyHostname(dnsName); err != nil {
+				return fmt.Errorf("serving certificate of the webhook server is invalid: %v", err)
+			}
+		}
+		return nil
+	}
+}
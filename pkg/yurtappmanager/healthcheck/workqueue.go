@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	depthMetric          = metrics.WorkQueueSubsystem + "_" + metrics.DepthKey
+	workDurationMetric   = metrics.WorkQueueSubsystem + "_" + metrics.WorkDurationKey
+	longestRunningMetric = metrics.WorkQueueSubsystem + "_" + metrics.LongestRunningProcessorKey
+)
+
+// queueState is the state of a work queue observed from the workqueue metrics
+type queueState struct {
+	depth float64
+	// done is the number of the items processed
+	done uint64
+	// longestRunning is the seconds the longest running reconcile has been running for
+	longestRunning float64
+}
+
+// queueProgress is the last progress of a work queue
+type queueProgress struct {
+	done uint64
+	time time.Time
+}
+
+// WorkQueueChecker detects the stuck reconcile workers from the workqueue metrics of the controllers,
+// a work queue is stuck if it has items but no item is processed within the threshold, or one of its
+// reconciles has been running for longer than the threshold.
+type WorkQueueChecker struct {
+	gatherer  prometheus.Gatherer
+	threshold time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	progress map[string]*queueProgress
+}
+
+// NewWorkQueueChecker creates a WorkQueueChecker on the workqueue metrics gathered from gatherer.
+func NewWorkQueueChecker(gatherer prometheus.Gatherer, threshold time.Duration) *WorkQueueChecker {
+	return &WorkQueueChecker{
+		gatherer:  gatherer,
+		threshold: threshold,
+		now:       time.Now,
+		progress:  map[string]*queueProgress{},
+	}
+}
+
+// Check fails if any work queue is stuck.
+func (c *WorkQueueChecker) Check(_ *http.Request) error {
+	queues, err := c.gather()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	var stuck []string
+	for name, q := range queues {
+		if running := time.Duration(q.longestRunning * float64(time.Second)); running > c.threshold {
+			stuck = append(stuck, fmt.Sprintf("a reconcile of %s has been running for %s", name, running.Round(time.Second)))
+		}
+
+		p, ok := c.progress[name]
+		if !ok || q.depth == 0 || q.done != p.done {
+			c.progress[name] = &queueProgress{done: q.done, time: now}
+			continue
+		}
+		if idle := now.Sub(p.time); idle > c.threshold {
+			stuck = append(stuck, fmt.Sprintf("%d items of %s are not processed for %s", int64(q.depth), name, idle.Round(time.Second)))
+		}
+	}
+	if len(stuck) != 0 {
+		sort.Strings(stuck)
+		return fmt.Errorf("reconcile workers are stuck: %s", strings.Join(stuck, "; "))
+	}
+	return nil
+}
+
+// gather gets the states of the work queues by their names
+func (c *WorkQueueChecker) gather() (map[string]*queueState, error) {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("fail to gather the workqueue metrics: %v", err)
+	}
+	queues := map[string]*queueState{}
+	state := func(m *dto.Metric) *queueState {
+		var name string
+		for _, l := range m.GetLabel() {
+			if l.GetName() == "name" {
+				name = l.GetValue()
+			}
+		}
+		if queues[name] == nil {
+			queues[name] = &queueState{}
+		}
+		return queues[name]
+	}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			switch f.GetName() {
+			case depthMetric:
+				state(m).depth = m.GetGauge().GetValue()
+			case workDurationMetric:
+				state(m).done = m.GetHistogram().GetSampleCount()
+			case longestRunningMetric:
+				state(m).longestRunning = m.GetGauge().GetValue()
+			}
+		}
+	}
+	return queues, nil
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the metrics of the yurt-app-manager controllers and webhooks, which are registered on
+// the controller-runtime metrics registry and exported with its default metrics.
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "yurt_app_manager"
+
+var (
+	nodePoolNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "nodepool",
+		Name:      "nodes",
+		Help:      "Number of the nodes in the nodepool.",
+	}, []string{"nodepool"})
+	nodePoolReadyNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "nodepool",
+		Name:      "ready_nodes",
+		Help:      "Number of the ready nodes in the nodepool.",
+	}, []string{"nodepool"})
+	nodePoolUnreadyNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "nodepool",
+		Name:      "unready_nodes",
+		Help:      "Number of the unready nodes in the nodepool.",
+	}, []string{"nodepool"})
+	nodePoolAttributeSyncFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nodepool",
+		Name:      "attribute_sync_failures_total",
+		Help:      "Number of the failures to sync the labels, annotations and taints of the nodepool to its nodes.",
+	}, []string{"nodepool"})
+
+	workloadDesiredReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workload",
+		Name:      "desired_replicas",
+		Help:      "Number of the desired replicas of the YurtAppSet or YurtAppDaemon in the pool.",
+	}, []string{"kind", "namespace", "name", "pool"})
+	workloadReadyReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workload",
+		Name:      "ready_replicas",
+		Help:      "Number of the ready replicas of the YurtAppSet or YurtAppDaemon in the pool.",
+	}, []string{"kind", "namespace", "name", "pool"})
+	workloadUpdatedReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workload",
+		Name:      "updated_replicas",
+		Help:      "Number of the replicas of the YurtAppSet or YurtAppDaemon in the pool which run the updated revision.",
+	}, []string{"kind", "namespace", "name", "pool"})
+	workloadRevision = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "workload",
+		Name:      "revision_info",
+		Help:      "The updated revision of the YurtAppSet or YurtAppDaemon, whose value is always 1.",
+	}, []string{"kind", "namespace", "name", "revision"})
+	workloadRolloutDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "workload",
+		Name:      "rollout_duration_seconds",
+		Help:      "Duration from a new revision of the YurtAppSet or YurtAppDaemon observed to all the replicas of it updated and ready.",
+		Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"kind"})
+
+	yurtIngressReadyPools = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "yurtingress",
+		Name:      "ready_pools",
+		Help:      "Number of the pools whose ingress controllers of the YurtIngress are ready.",
+	}, []string{"name"})
+	yurtIngressPoolReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "yurtingress",
+		Name:      "pool_ready",
+		Help:      "Whether the ingress controller of the YurtIngress in the pool is ready, 1 for ready and 0 for not ready.",
+	}, []string{"name", "pool"})
+
+	webhookAdmissionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "admission_duration_seconds",
+		Help:      "Duration of the admission requests handled by the webhook handler.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"path"})
+	webhookAdmissionRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "admission_rejections_total",
+		Help:      "Number of the admission requests rejected by the webhook handler.",
+	}, []string{"path"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		nodePoolNodes,
+		nodePoolReadyNodes,
+		nodePoolUnreadyNodes,
+		nodePoolAttributeSyncFailures,
+		workloadDesiredReplicas,
+		workloadReadyReplicas,
+		workloadUpdatedReplicas,
+		workloadRevision,
+		workloadRolloutDuration,
+		yurtIngressReadyPools,
+		yurtIngressPoolReady,
+		webhookAdmissionDuration,
+		webhookAdmissionRejections,
+	)
+}
+
+// SetNodePoolNodes records the number of the ready and unready nodes of the nodepool.
+func SetNodePoolNodes(nodepool string, ready, unready int32) {
+	nodePoolNodes.WithLabelValues(nodepool).Set(float64(ready + unready))
+	nodePoolReadyNodes.WithLabelValues(nodepool).Set(float64(ready))
+	nodePoolUnreadyNodes.WithLabelValues(nodepool).Set(float64(unready))
+}
+
+// IncNodePoolAttributeSyncFailures counts a failure to sync the attributes of the nodepool to a node.
+func IncNodePoolAttributeSyncFailures(nodepool string) {
+	nodePoolAttributeSyncFailures.WithLabelValues(nodepool).Inc()
+}
+
+// DeleteNodePool deletes the metrics of the deleted nodepool.
+func DeleteNodePool(nodepool string) {
+	nodePoolNodes.DeleteLabelValues(nodepool)
+	nodePoolReadyNodes.DeleteLabelValues(nodepool)
+	nodePoolUnreadyNodes.DeleteLabelValues(nodepool)
+	nodePoolAttributeSyncFailures.DeleteLabelValues(nodepool)
+}
+
+// PoolReplicas is the replicas of a YurtAppSet or YurtAppDaemon in a pool.
+type PoolReplicas struct {
+	Desired int32
+	Ready   int32
+	Updated int32
+}
+
+// workload is the state of the metrics of a YurtAppSet or YurtAppDaemon, so the series of the removed pools and
+// the old revisions are deleted.
+type workload struct {
+	pools    sets.String
+	revision string
+	// rolloutStart is the time the revision is observed, zero if the revision is rolled out.
+	rolloutStart time.Time
+}
+
+var (
+	workloadsLock sync.Mutex
+	workloads     = make(map[string]*workload)
+)
+
+func workloadKey(kind, namespace, name string) string {
+	return strings.Join([]string{kind, namespace, name}, "/")
+}
+
+// getWorkload returns the state of the metrics of the workload, it should be called with workloadsLock held.
+func getWorkload(kind, namespace, name string) *workload {
+	key := workloadKey(kind, namespace, name)
+	w, ok := workloads[key]
+	if !ok {
+		w = &workload{pools: sets.NewString()}
+		workloads[key] = w
+	}
+	return w
+}
+
+// SetWorkloadReplicas records the replicas of the YurtAppSet or YurtAppDaemon in its pools, the pools not in
+// pools any more are deleted.
+func SetWorkloadReplicas(kind, namespace, name string, pools map[string]PoolReplicas) {
+	workloadsLock.Lock()
+	defer workloadsLock.Unlock()
+	w := getWorkload(kind, namespace, name)
+	current := sets.NewString()
+	for pool, replicas := range pools {
+		current.Insert(pool)
+		workloadDesiredReplicas.WithLabelValues(kind, namespace, name, pool).Set(float64(replicas.Desired))
+		workloadReadyReplicas.WithLabelValues(kind, namespace, name, pool).Set(float64(replicas.Ready))
+		workloadUpdatedReplicas.WithLabelValues(kind, namespace, name, pool).Set(float64(replicas.Updated))
+	}
+	for _, pool := range w.pools.Difference(current).UnsortedList() {
+		deleteWorkloadPool(kind, namespace, name, pool)
+	}
+	w.pools = current
+}
+
+func deleteWorkloadPool(kind, namespace, name, pool string) {
+	workloadDesiredReplicas.DeleteLabelValues(kind, namespace, name, pool)
+	workloadReadyReplicas.DeleteLabelValues(kind, namespace, name, pool)
+	workloadUpdatedReplicas.DeleteLabelValues(kind, namespace, name, pool)
+}
+
+// SetWorkloadRevision records the updated revision of the YurtAppSet or YurtAppDaemon, and whether all the replicas
+// of it are updated and ready. The rollout duration is observed when a revision which is observed not rolled out
+// is rolled out.
+func SetWorkloadRevision(kind, namespace, name, revision string, rolledOut bool) {
+	workloadsLock.Lock()
+	defer workloadsLock.Unlock()
+	w := getWorkload(kind, namespace, name)
+	if w.revision != revision {
+		if w.revision != "" {
+			workloadRevision.DeleteLabelValues(kind, namespace, name, w.revision)
+		}
+		w.revision = revision
+		w.rolloutStart = time.Time{}
+		if !rolledOut {
+			w.rolloutStart = time.Now()
+		}
+	}
+	workloadRevision.WithLabelValues(kind, namespace, name, revision).Set(1)
+	if rolledOut && !w.rolloutStart.IsZero() {
+		workloadRolloutDuration.WithLabelValues(kind).Observe(time.Since(w.rolloutStart).Seconds())
+		w.rolloutStart = time.Time{}
+	}
+}
+
+// DeleteWorkload deletes the metrics of the deleted YurtAppSet or YurtAppDaemon.
+func DeleteWorkload(kind, namespace, name string) {
+	workloadsLock.Lock()
+	defer workloadsLock.Unlock()
+	key := workloadKey(kind, namespace, name)
+	w, ok := workloads[key]
+	if !ok {
+		return
+	}
+	for _, pool := range w.pools.UnsortedList() {
+		deleteWorkloadPool(kind, namespace, name, pool)
+	}
+	if w.revision != "" {
+		workloadRevision.DeleteLabelValues(kind, namespace, name, w.revision)
+	}
+	delete(workloads, key)
+}
+
+var (
+	yurtIngressesLock sync.Mutex
+	yurtIngressPools  = make(map[string]sets.String)
+)
+
+// SetYurtIngressPools records the readiness of the ingress controllers of the YurtIngress in its pools, the pools
+// not in ready or notReady any more are deleted.
+func SetYurtIngressPools(name string, ready, notReady []string) {
+	yurtIngressesLock.Lock()
+	defer yurtIngressesLock.Unlock()
+	current := sets.NewString()
+	for _, pool := range ready {
+		current.Insert(pool)
+		yurtIngressPoolReady.WithLabelValues(name, pool).Set(1)
+	}
+	for _, pool := range notReady {
+		current.Insert(pool)
+		yurtIngressPoolReady.WithLabelValues(name, pool).Set(0)
+	}
+	yurtIngressReadyPools.WithLabelValues(name).Set(float64(len(ready)))
+	for _, pool := range yurtIngressPools[name].Difference(current).UnsortedList() {
+		yurtIngressPoolReady.DeleteLabelValues(name, pool)
+	}
+	yurtIngressPools[name] = current
+}
+
+// DeleteYurtIngress deletes the metrics of the deleted YurtIngress.
+func DeleteYurtIngress(name string) {
+	yurtIngressesLock.Lock()
+	defer yurtIngressesLock.Unlock()
+	for _, pool := range yurtIngressPools[name].UnsortedList() {
+		yurtIngressPoolReady.DeleteLabelValues(name, pool)
+	}
+	yurtIngressReadyPools.DeleteLabelValues(name)
+	delete(yurtIngressPools, name)
+}
+
+// ObserveAdmission records an admission request handled by the webhook handler of the path.
+func ObserveAdmission(path string, duration time.Duration, allowed bool) {
+	webhookAdmissionDuration.WithLabelValues(path).Observe(duration.Seconds())
+	if !allowed {
+		webhookAdmissionRejections.WithLabelValues(path).Inc()
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWorkloadMetrics(t *testing.T) {
+	SetWorkloadReplicas("YurtAppSet", "default", "app", map[string]PoolReplicas{
+		"beijing":  {Desired: 2, Ready: 2, Updated: 2},
+		"hangzhou": {Desired: 3, Ready: 1, Updated: 0},
+	})
+	if v := testutil.ToFloat64(workloadReadyReplicas.WithLabelValues("YurtAppSet", "default", "app", "hangzhou")); v != 1 {
+		t.Fatalf("expected 1 ready replica in hangzhou, got %v", v)
+	}
+	SetWorkloadReplicas("YurtAppSet", "default", "app", map[string]PoolReplicas{
+		"beijing": {Desired: 2, Ready: 2, Updated: 2},
+	})
+	if n := testutil.CollectAndCount(workloadDesiredReplicas); n != 1 {
+		t.Fatalf("expected the series of the removed pool to be deleted, got %d series", n)
+	}
+
+	SetWorkloadRevision("YurtAppSet", "default", "app", "app-v1", true)
+	SetWorkloadRevision("YurtAppSet", "default", "app", "app-v2", false)
+	if n := testutil.CollectAndCount(workloadRevision); n != 1 {
+		t.Fatalf("expected the series of the old revision to be deleted, got %d series", n)
+	}
+	if n := testutil.CollectAndCount(workloadRolloutDuration); n != 0 {
+		t.Fatalf("expected no rollout observed, got %d series", n)
+	}
+	time.Sleep(10 * time.Millisecond)
+	SetWorkloadRevision("YurtAppSet", "default", "app", "app-v2", true)
+	SetWorkloadRevision("YurtAppSet", "default", "app", "app-v2", true)
+	if n := testutil.CollectAndCount(workloadRolloutDuration); n != 1 {
+		t.Fatalf("expected the rollout observed, got %d series", n)
+	}
+
+	DeleteWorkload("YurtAppSet", "default", "app")
+	if n := testutil.CollectAndCount(workloadDesiredReplicas) + testutil.CollectAndCount(workloadRevision); n != 0 {
+		t.Fatalf("expected the series of the deleted workload to be deleted, got %d series", n)
+	}
+}
+
+func TestYurtIngressMetrics(t *testing.T) {
+	SetYurtIngressPools("ying", []string{"beijing"}, []string{"hangzhou"})
+	if v := testutil.ToFloat64(yurtIngressReadyPools.WithLabelValues("ying")); v != 1 {
+		t.Fatalf("expected 1 ready pool, got %v", v)
+	}
+	if v := testutil.ToFloat64(yurtIngressPoolReady.WithLabelValues("ying", "hangzhou")); v != 0 {
+		t.Fatalf("expected hangzhou not ready, got %v", v)
+	}
+	SetYurtIngressPools("ying", []string{"beijing", "hangzhou"}, nil)
+	SetYurtIngressPools("ying", []string{"hangzhou"}, nil)
+	if n := testutil.CollectAndCount(yurtIngressPoolReady); n != 1 {
+		t.Fatalf("expected the series of the removed pool to be deleted, got %d series", n)
+	}
+	DeleteYurtIngress("ying")
+	if n := testutil.CollectAndCount(yurtIngressPoolReady) + testutil.CollectAndCount(yurtIngressReadyPools); n != 0 {
+		t.Fatalf("expected the series of the deleted YurtIngress to be deleted, got %d series", n)
+	}
+}
+
+func TestAdmissionMetrics(t *testing.T) {
+	ObserveAdmission("/validate-apps-openyurt-io-v1alpha1-nodepool", time.Millisecond, true)
+	ObserveAdmission("/validate-apps-openyurt-io-v1alpha1-nodepool", time.Millisecond, false)
+	if v := testutil.ToFloat64(webhookAdmissionRejections.WithLabelValues("/validate-apps-openyurt-io-v1alpha1-nodepool")); v != 1 {
+		t.Fatalf("expected 1 rejection, got %v", v)
+	}
+	if n := testutil.CollectAndCount(webhookAdmissionDuration); n != 1 {
+		t.Fatalf("expected the admission duration observed, got %d series", n)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// InventoryKey is the key of the applied objects in the data of an inventory ConfigMap.
+	InventoryKey = "objects"
+	// InventoryLabel is the label of the inventory ConfigMaps, its value is the field manager of the addon.
+	InventoryLabel = "addon.openyurt.io/inventory"
+)
+
+// Inventory is the ConfigMap which records the objects applied by an addon, so that the objects which are
+// no longer rendered can be pruned.
+type Inventory struct {
+	Namespace string
+	Name      string
+}
+
+// ObjectRef identifies an applied object.
+type ObjectRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// NewObjectRef returns the reference to the object.
+func NewObjectRef(obj *unstructured.Unstructured) ObjectRef {
+	return ObjectRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// Object returns an object with only the type and the key of the reference.
+func (r ObjectRef) Object() *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(r.APIVersion, r.Kind))
+	obj.SetNamespace(r.Namespace)
+	obj.SetName(r.Name)
+	return obj
+}
+
+func (r ObjectRef) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// getInventory returns the inventory ConfigMap and the objects recorded in it, the ConfigMap is nil if it does
+// not exist.
+func getInventory(ctx context.Context, c client.Client, inv Inventory) (*corev1.ConfigMap, []ObjectRef, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: inv.Namespace, Name: inv.Name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("fail to get the inventory %s/%s: %v", inv.Namespace, inv.Name, err)
+	}
+	var refs []ObjectRef
+	if data := cm.Data[InventoryKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &refs); err != nil {
+			return nil, nil, fmt.Errorf("fail to decode the inventory %s/%s: %v", inv.Namespace, inv.Name, err)
+		}
+	}
+	return cm, refs, nil
+}
+
+// newInventory returns the inventory ConfigMap recording the objects.
+func newInventory(inv Inventory, fieldManager string, refs []ObjectRef, ownerRefs []metav1.OwnerReference) (*corev1.ConfigMap, error) {
+	if refs == nil {
+		refs = []ObjectRef{}
+	}
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       inv.Namespace,
+			Name:            inv.Name,
+			Labels:          map[string]string{InventoryLabel: fieldManager},
+			OwnerReferences: ownerRefs,
+		},
+		Data: map[string]string{InventoryKey: string(data)},
+	}, nil
+}
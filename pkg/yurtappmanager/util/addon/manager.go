@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Manager applies the objects of the addons with server-side apply, records them in the inventories of the addons,
+// and prunes the recorded objects which are no longer applied. The objects are applied with force, so the drift of
+// the fields set by the addon templates is corrected on every apply, while the fields set by others are kept.
+type Manager struct {
+	client       client.Client
+	fieldManager string
+}
+
+// NewManager returns a Manager which applies the objects as fieldManager.
+func NewManager(c client.Client, fieldManager string) *Manager {
+	return &Manager{client: c, fieldManager: fieldManager}
+}
+
+// ApplyOptions are the options to apply the objects of an addon.
+type ApplyOptions struct {
+	// OwnerReferences are added to the owners of the applied objects and the inventory.
+	// If it is nil, the owners of the existing inventory are used.
+	OwnerReferences []metav1.OwnerReference
+	// CreateOnly returns whether the object is created if it does not exist rather than applied,
+	// such as a Job whose pod template is immutable.
+	CreateOnly func(obj *unstructured.Unstructured) bool
+}
+
+// Apply applies the objects in order, and then prunes the objects recorded in the inventory which are not among
+// them in the reverse order of their recording.
+func (m *Manager) Apply(ctx context.Context, inv Inventory, objs []*unstructured.Unstructured, opts *ApplyOptions) error {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+	cm, recorded, err := getInventory(ctx, m.client, inv)
+	if err != nil {
+		return err
+	}
+	ownerRefs := opts.OwnerReferences
+	if ownerRefs == nil && cm != nil {
+		ownerRefs = cm.OwnerReferences
+	}
+
+	applied := make([]ObjectRef, 0, len(objs))
+	isApplied := make(map[ObjectRef]bool, len(objs))
+	for _, obj := range objs {
+		ref := NewObjectRef(obj)
+		applied = append(applied, ref)
+		isApplied[ref] = true
+	}
+	// record the objects to apply before applying them, so that they are still pruned if applying fails halfway
+	var pruned []ObjectRef
+	for _, ref := range recorded {
+		if !isApplied[ref] {
+			pruned = append(pruned, ref)
+		}
+	}
+	if err := m.saveInventory(ctx, inv, append(append([]ObjectRef{}, pruned...), applied...), ownerRefs); err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		addOwnerReferences(obj, ownerRefs)
+		if opts.CreateOnly != nil && opts.CreateOnly(obj) {
+			if err := m.client.Create(ctx, obj); err != nil {
+				if apierrors.IsAlreadyExists(err) {
+					continue
+				}
+				return fmt.Errorf("fail to create %s: %v", NewObjectRef(obj), err)
+			}
+			klog.V(4).Infof("%s is created", NewObjectRef(obj))
+			continue
+		}
+		if err := m.client.Patch(ctx, obj, client.Apply, client.FieldOwner(m.fieldManager), client.ForceOwnership); err != nil {
+			return fmt.Errorf("fail to apply %s: %v", NewObjectRef(obj), err)
+		}
+		klog.V(5).Infof("%s is applied", NewObjectRef(obj))
+	}
+
+	for i := len(pruned) - 1; i >= 0; i-- {
+		if err := m.delete(ctx, pruned[i]); err != nil {
+			return err
+		}
+		klog.V(4).Infof("%s is pruned", pruned[i])
+	}
+	return m.saveInventory(ctx, inv, applied, ownerRefs)
+}
+
+// Delete deletes the objects recorded in the inventory in the reverse order of their recording,
+// and the inventory itself.
+func (m *Manager) Delete(ctx context.Context, inv Inventory) error {
+	cm, recorded, err := getInventory(ctx, m.client, inv)
+	if err != nil || cm == nil {
+		return err
+	}
+	for i := len(recorded) - 1; i >= 0; i-- {
+		if err := m.delete(ctx, recorded[i]); err != nil {
+			return err
+		}
+		klog.V(4).Infof("%s is deleted", recorded[i])
+	}
+	if err := m.client.Delete(ctx, cm); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("fail to delete the inventory %s/%s: %v", inv.Namespace, inv.Name, err)
+	}
+	return nil
+}
+
+// Objects returns the objects recorded in the inventory.
+func (m *Manager) Objects(ctx context.Context, inv Inventory) ([]ObjectRef, error) {
+	_, recorded, err := getInventory(ctx, m.client, inv)
+	return recorded, err
+}
+
+// Owners returns the owners of the inventory, which are the owners of the recorded objects.
+// It returns nil if the inventory does not exist.
+func (m *Manager) Owners(ctx context.Context, inv Inventory) ([]metav1.OwnerReference, error) {
+	cm, _, err := getInventory(ctx, m.client, inv)
+	if err != nil || cm == nil {
+		return nil, err
+	}
+	return cm.OwnerReferences, nil
+}
+
+// addOwnerReferences adds the owners to the object unless they are among its owners already.
+func addOwnerReferences(obj *unstructured.Unstructured, ownerRefs []metav1.OwnerReference) {
+	refs := obj.GetOwnerReferences()
+	for _, ownerRef := range ownerRefs {
+		found := false
+		for _, ref := range refs {
+			if ref.UID == ownerRef.UID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			refs = append(refs, ownerRef)
+		}
+	}
+	if len(refs) > 0 {
+		obj.SetOwnerReferences(refs)
+	}
+}
+
+func (m *Manager) delete(ctx context.Context, ref ObjectRef) error {
+	policy := metav1.DeletePropagationBackground
+	if err := m.client.Delete(ctx, ref.Object(), &client.DeleteOptions{PropagationPolicy: &policy}); err != nil &&
+		!apierrors.IsNotFound(err) {
+		return fmt.Errorf("fail to delete %s: %v", ref, err)
+	}
+	return nil
+}
+
+func (m *Manager) saveInventory(ctx context.Context, inv Inventory, refs []ObjectRef, ownerRefs []metav1.OwnerReference) error {
+	cm, err := newInventory(inv, m.fieldManager, refs, ownerRefs)
+	if err != nil {
+		return err
+	}
+	if err := m.client.Patch(ctx, cm, client.Apply, client.FieldOwner(m.fieldManager), client.ForceOwnership); err != nil {
+		return fmt.Errorf("fail to save the inventory %s/%s: %v", inv.Namespace, inv.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/addon/fake"
+)
+
+const testTemplate = `
+{{- range .names}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{.}}
+  namespace: {{$.namespace}}
+data:
+  replicas: "{{$.replicas}}"
+{{- end}}
+`
+
+func newTestClient() client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	return fake.NewClient(fakeclient.NewClientBuilder().WithScheme(scheme).Build())
+}
+
+func TestRender(t *testing.T) {
+	objs, err := Render(map[string]interface{}{"names": []string{"a", "b"}, "namespace": "addon", "replicas": int32(2)},
+		testTemplate, "", `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{.namespace}}
+`)
+	if err != nil {
+		t.Fatalf("fail to render: %v", err)
+	}
+	var got []string
+	for _, obj := range objs {
+		got = append(got, NewObjectRef(obj).String())
+	}
+	want := []string{"ConfigMap addon/a", "ConfigMap addon/b", "Namespace addon"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if replicas, _, _ := unstructured.NestedString(objs[0].Object, "data", "replicas"); replicas != "2" {
+		t.Fatalf("expected replicas rendered, got %q", replicas)
+	}
+
+	if _, err := Render(nil, "apiVersion: v1\nkind: ConfigMap\n"); err == nil {
+		t.Fatalf("expected error for object without name")
+	}
+}
+
+func TestManager(t *testing.T) {
+	c := newTestClient()
+	m := NewManager(c, "test-manager")
+	inv := Inventory{Namespace: "addon", Name: "test-inventory"}
+	ownerRefs := []metav1.OwnerReference{{APIVersion: "v1", Kind: "Owner", Name: "owner", UID: "uid"}}
+	render := func(names ...string) []*unstructured.Unstructured {
+		objs, err := Render(map[string]interface{}{"names": names, "namespace": "addon", "replicas": 1}, testTemplate)
+		if err != nil {
+			t.Fatalf("fail to render: %v", err)
+		}
+		return objs
+	}
+	get := func(name string) (*corev1.ConfigMap, error) {
+		cm := &corev1.ConfigMap{}
+		return cm, c.Get(context.TODO(), client.ObjectKey{Namespace: "addon", Name: name}, cm)
+	}
+
+	if err := m.Apply(context.TODO(), inv, render("a", "b"), &ApplyOptions{OwnerReferences: ownerRefs}); err != nil {
+		t.Fatalf("fail to apply: %v", err)
+	}
+	cm, err := get("a")
+	if err != nil {
+		t.Fatalf("fail to get the applied object: %v", err)
+	}
+	if len(cm.OwnerReferences) != 1 || cm.OwnerReferences[0].UID != "uid" {
+		t.Fatalf("unexpected owner references %v", cm.OwnerReferences)
+	}
+
+	// drift is corrected, and the objects no longer rendered are pruned
+	cm.Data["replicas"] = "3"
+	if err := c.Update(context.TODO(), cm); err != nil {
+		t.Fatalf("fail to update: %v", err)
+	}
+	if err := m.Apply(context.TODO(), inv, render("a", "c"), nil); err != nil {
+		t.Fatalf("fail to apply: %v", err)
+	}
+	if cm, err = get("a"); err != nil || cm.Data["replicas"] != "1" {
+		t.Fatalf("expected drift corrected, got %v, %v", cm.Data, err)
+	}
+	if _, err := get("b"); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected object b pruned, got %v", err)
+	}
+	if cm, err = get("c"); err != nil || len(cm.OwnerReferences) != 1 {
+		t.Fatalf("expected object c applied with the owners of the inventory, got %v, %v", cm.OwnerReferences, err)
+	}
+	refs, err := m.Objects(context.TODO(), inv)
+	if err != nil || len(refs) != 2 || refs[0].Name != "a" || refs[1].Name != "c" {
+		t.Fatalf("unexpected inventory %v, %v", refs, err)
+	}
+	if owners, err := m.Owners(context.TODO(), inv); err != nil || len(owners) != 1 || owners[0].UID != "uid" {
+		t.Fatalf("unexpected inventory owners %v, %v", owners, err)
+	}
+
+	// created only objects are not applied again
+	createOnly := &ApplyOptions{CreateOnly: func(obj *unstructured.Unstructured) bool { return obj.GetName() == "a" }}
+	objs := render("a", "c")
+	_ = unstructured.SetNestedField(objs[0].Object, "5", "data", "replicas")
+	if err := m.Apply(context.TODO(), inv, objs, createOnly); err != nil {
+		t.Fatalf("fail to apply: %v", err)
+	}
+	if cm, err = get("a"); err != nil || cm.Data["replicas"] != "1" {
+		t.Fatalf("expected created only object kept, got %v, %v", cm.Data, err)
+	}
+
+	if err := m.Delete(context.TODO(), inv); err != nil {
+		t.Fatalf("fail to delete: %v", err)
+	}
+	for _, name := range []string{"a", "c", inv.Name} {
+		if _, err := get(name); !apierrors.IsNotFound(err) {
+			t.Fatalf("expected %s deleted, got %v", name, err)
+		}
+	}
+	if err := m.Delete(context.TODO(), inv); err != nil {
+		t.Fatalf("expected deleting a missing inventory to succeed, got %v", err)
+	}
+	if owners, err := m.Owners(context.TODO(), inv); err != nil || owners != nil {
+		t.Fatalf("expected no owners of a missing inventory, got %v, %v", owners, err)
+	}
+}
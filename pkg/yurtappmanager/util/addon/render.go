@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Render fills out the multi-document templates with the values, and decodes every document as an object in the
+// order of the templates. The values are typed, so a template can range over a list or index a map of them,
+// and a missing value is rendered as the zero value.
+func Render(values interface{}, tmpls ...string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, tmpl := range tmpls {
+		if strings.TrimSpace(tmpl) == "" {
+			continue
+		}
+		t, err := template.New("addon").Option("missingkey=zero").Parse(tmpl)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse the template: %v", err)
+		}
+		rendered := &bytes.Buffer{}
+		if err := t.Execute(rendered, values); err != nil {
+			return nil, fmt.Errorf("fail to render the template: %v", err)
+		}
+
+		decoder := utilyaml.NewYAMLOrJSONDecoder(rendered, 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("fail to decode the rendered template: %v", err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			if obj.GetKind() == "" || obj.GetName() == "" {
+				return nil, fmt.Errorf("rendered object has no kind or name: %v", obj.Object)
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
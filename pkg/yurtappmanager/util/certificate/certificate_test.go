@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServingCert(t *testing.T) {
+	ca, err := NewCA("webhook-ca", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("fail to create CA: %v", err)
+	}
+	hosts := []string{"webhook", "webhook.ingress-nginx.svc"}
+	cert, err := NewServingCert(ca, "webhook", hosts, time.Hour)
+	if err != nil {
+		t.Fatalf("fail to create serving certificate: %v", err)
+	}
+
+	parsed, err := ParseKeyPair(cert.CertPEM, cert.KeyPEM)
+	if err != nil {
+		t.Fatalf("fail to parse serving certificate: %v", err)
+	}
+	if err := VerifyServingCert(ca.Cert, parsed.Cert, hosts, time.Now()); err != nil {
+		t.Fatalf("expected the serving certificate to be valid: %v", err)
+	}
+	if err := VerifyServingCert(ca.Cert, parsed.Cert, []string{"other.ingress-nginx.svc"}, time.Now()); err == nil {
+		t.Fatalf("expected the serving certificate not to be valid for other hosts")
+	}
+	if err := VerifyServingCert(ca.Cert, parsed.Cert, hosts, time.Now().Add(2*time.Hour)); err == nil {
+		t.Fatalf("expected the serving certificate to expire")
+	}
+	other, err := NewCA("other-ca", time.Hour)
+	if err != nil {
+		t.Fatalf("fail to create CA: %v", err)
+	}
+	if err := VerifyServingCert(other.Cert, parsed.Cert, hosts, time.Now()); err == nil {
+		t.Fatalf("expected the serving certificate not to be signed by other CA")
+	}
+
+	renew := RenewTime(parsed.Cert)
+	if !renew.After(parsed.Cert.NotBefore) || !renew.Before(parsed.Cert.NotAfter) {
+		t.Fatalf("unexpected renew time %v", renew)
+	}
+	if _, err := ParseKeyPair(cert.CertPEM, []byte("invalid")); err == nil {
+		t.Fatalf("expected invalid private key to fail")
+	}
+}
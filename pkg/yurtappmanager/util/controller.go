@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/constant"
+)
+
+// ControllersConfiguration returns the configuration of the controllers in ctx,
+// or the default configuration if ctx has none.
+func ControllersConfiguration(ctx context.Context) *configv1alpha1.ControllersConfiguration {
+	if c, ok := ctx.Value(constant.ContextKeyControllersConfiguration).(*configv1alpha1.ControllersConfiguration); ok && c != nil {
+		return c
+	}
+	c := &configv1alpha1.ControllersConfiguration{}
+	configv1alpha1.SetDefaultsControllersConfiguration(c)
+	return c
+}
+
+// ControllerOptions returns the options of a controller with r as the reconcile.Reconciler from its configuration.
+func ControllerOptions(r reconcile.Reconciler, c configv1alpha1.ControllerConfiguration) controller.Options {
+	return controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: int(c.Workers),
+		RateLimiter:             NewRateLimiter(c.RateLimiter),
+	}
+}
+
+// NewRateLimiter returns the max of a per-item exponential backoff and an overall token bucket,
+// the same as workqueue.DefaultControllerRateLimiter with the configured parameters.
+func NewRateLimiter(c configv1alpha1.RateLimiterConfiguration) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(c.BaseDelay.Duration, c.MaxDelay.Duration),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(c.QPS), int(c.Burst))},
+	)
+}
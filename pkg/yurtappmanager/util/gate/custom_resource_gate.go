@@ -18,8 +18,10 @@ limitations under the License.
 package gate
 
 import (
+	"context"
 	"os"
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +33,7 @@ import (
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis"
 )
@@ -44,8 +47,24 @@ var (
 	discoveryClient discovery.DiscoveryInterface
 
 	isNotNotFound = func(err error) bool { return !errors.IsNotFound(err) }
+
+	// enabledResources is the enabled kinds set by the configuration of yurt-app-manager,
+	// ${CUSTOM_RESOURCE_ENABLE} is used if it is nil
+	enabledResources     []string
+	enabledResourcesLock sync.RWMutex
 )
 
+// SetEnabledResources sets the kinds of the enabled custom resources, all of them are enabled if kinds is empty.
+// It can be called at any time, the controllers and the webhooks of the disabled resources stop handling them.
+func SetEnabledResources(kinds []string) {
+	enabledResourcesLock.Lock()
+	defer enabledResourcesLock.Unlock()
+	enabledResources = kinds
+	if enabledResources == nil {
+		enabledResources = []string{}
+	}
+}
+
 func init() {
 	_ = apis.AddToScheme(internalScheme)
 	cfg, err := config.GetConfig()
@@ -56,7 +75,7 @@ func init() {
 
 // ResourceEnabled help runnable check if the custom resource is valid and enabled
 // 1. If this CRD is not found from kueb-apiserver, it is invalid.
-// 2. If 'CUSTOM_RESOURCE_ENABLE' env is not empty and this CRD kind is not in ${CUSTOM_RESOURCE_ENABLE}.
+// 2. If 'CUSTOM_RESOURCE_ENABLE' env (overridden by the configuration) is not empty and this CRD kind is not in it.
 func ResourceEnabled(obj runtime.Object) bool {
 	gvk, err := apiutil.GVKForObject(obj, internalScheme)
 	if err != nil {
@@ -67,6 +86,28 @@ func ResourceEnabled(obj runtime.Object) bool {
 	return discoveryEnabled(gvk) && envEnabled(gvk)
 }
 
+// ConfigEnabled checks if the custom resource is enabled by the configuration, without checking if its CRD is installed.
+func ConfigEnabled(obj runtime.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, internalScheme)
+	if err != nil {
+		klog.Warningf("custom resource gate not recognized object %T in scheme: %v", obj, err)
+		return false
+	}
+	return envEnabled(gvk)
+}
+
+// NewReconciler returns a reconciler which reconciles with r only while the custom resource obj is enabled
+// by the configuration, so that disabling the resource stops its running controller.
+func NewReconciler(obj runtime.Object, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if !ConfigEnabled(obj) {
+			klog.V(4).Infof("custom resource gate skipped %v for the disabled %T", req, obj)
+			return reconcile.Result{}, nil
+		}
+		return r.Reconcile(ctx, req)
+	})
+}
+
 func discoveryEnabled(gvk schema.GroupVersionKind) bool {
 	if discoveryClient == nil {
 		return true
@@ -100,13 +141,18 @@ func discoveryEnabled(gvk schema.GroupVersionKind) bool {
 
 func envEnabled(gvk schema.GroupVersionKind) bool {
 	limits := strings.TrimSpace(os.Getenv(envCustomResourceEnable))
+	enabledResourcesLock.RLock()
+	if enabledResources != nil {
+		limits = strings.Join(enabledResources, ",")
+	}
+	enabledResourcesLock.RUnlock()
 	if len(limits) == 0 {
 		// all enabled by default
 		return true
 	}
 
 	if !sets.NewString(strings.Split(limits, ",")...).Has(gvk.Kind) {
-		klog.Warningf("custom resource gate not found groupVersionKind %v in CUSTOM_RESOURCE_ENABLE: %v", gvk, limits)
+		klog.V(4).Infof("custom resource gate not found groupVersionKind %v in CUSTOM_RESOURCE_ENABLE: %v", gvk, limits)
 		return false
 	}
 
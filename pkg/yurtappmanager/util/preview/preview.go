@@ -0,0 +1,164 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Action is what would be done to the workload of a pool.
+type Action string
+
+const (
+	ActionCreate    Action = "Create"
+	ActionUpdate    Action = "Update"
+	ActionDelete    Action = "Delete"
+	ActionUnchanged Action = "Unchanged"
+)
+
+// FieldChange is the change of one field of the workload.
+// Old is empty if the field is added, and New is empty if the field is removed.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// WorkloadPreview is what would change of the workload of one pool.
+type WorkloadPreview struct {
+	Pool    string        `json:"pool"`
+	Kind    string        `json:"kind"`
+	Name    string        `json:"name,omitempty"`
+	Action  Action        `json:"action"`
+	Changes []FieldChange `json:"changes,omitempty"`
+	// Error is the reason why the workload could not be rendered.
+	Error string `json:"error,omitempty"`
+}
+
+// comparedFields are the fields of the workloads which are rendered from the templates.
+var comparedFields = [][]string{
+	{"metadata", "labels"},
+	{"metadata", "annotations"},
+	{"spec"},
+}
+
+// Diff returns the changes of the labels, annotations and spec from the current workload to the rendered one,
+// sorted by the field paths. The current workload is nil if it is to be created.
+func Diff(current, rendered runtime.Object) ([]FieldChange, error) {
+	currentMap := map[string]interface{}{}
+	if current != nil {
+		var err error
+		if currentMap, err = runtime.DefaultUnstructuredConverter.ToUnstructured(current); err != nil {
+			return nil, err
+		}
+	}
+	renderedMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(rendered)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for _, path := range comparedFields {
+		changes = diffValue(strings.Join(path, "."), lookup(currentMap, path), lookup(renderedMap, path), changes)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+func lookup(obj map[string]interface{}, path []string) interface{} {
+	var value interface{} = obj
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// diffValue compares the maps field by field, and the other values as a whole.
+func diffValue(path string, old, new interface{}, changes []FieldChange) []FieldChange {
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	// compare a missing map as an empty one, so that the added or removed fields are listed one by one
+	if old == nil && newIsMap {
+		oldMap, oldIsMap = map[string]interface{}{}, true
+	}
+	if new == nil && oldIsMap {
+		newMap, newIsMap = map[string]interface{}{}, true
+	}
+	if oldIsMap && newIsMap {
+		for key, value := range oldMap {
+			changes = diffValue(path+"."+key, value, newMap[key], changes)
+		}
+		for key, value := range newMap {
+			if _, exist := oldMap[key]; !exist {
+				changes = diffValue(path+"."+key, nil, value, changes)
+			}
+		}
+		return changes
+	}
+
+	if isEmpty(old) && isEmpty(new) {
+		return changes
+	}
+	if reflect.DeepEqual(old, new) {
+		return changes
+	}
+	return append(changes, FieldChange{Path: path, Old: old, New: new})
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// String formats the changes of the workload for human beings.
+func (p WorkloadPreview) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "pool %s: %s %s %s\n", p.Pool, p.Action, p.Kind, p.Name)
+	if p.Error != "" {
+		fmt.Fprintf(&b, "  error: %s\n", p.Error)
+	}
+	for _, c := range p.Changes {
+		fmt.Fprintf(&b, "  %s: %v -> %v\n", c.Path, format(c.Old), format(c.New))
+	}
+	return b.String()
+}
+
+func format(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%v", value)
+}
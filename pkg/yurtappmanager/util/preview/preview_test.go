@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDeployment(image string, replicas int32, labels map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "foo-hangzhou-abcde",
+			ResourceVersion: "1",
+			Labels:          labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "nginx", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	current := newDeployment("nginx:1.19", 2, map[string]string{"app": "foo", "rev": "1"})
+	rendered := newDeployment("nginx:1.20", 3, map[string]string{"app": "foo", "pool": "hangzhou"})
+	rendered.ResourceVersion = "2"
+
+	changes, err := Diff(current, rendered)
+	if err != nil {
+		t.Fatalf("fail to diff: %v", err)
+	}
+
+	expected := []string{"metadata.labels.pool", "metadata.labels.rev", "spec.replicas", "spec.template.spec.containers"}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected changes of %v, got %v", expected, changes)
+	}
+	for i, path := range expected {
+		if changes[i].Path != path {
+			t.Fatalf("expected change %d at %s, got %v", i, path, changes[i])
+		}
+	}
+	if changes[1].New != nil || changes[1].Old != "1" {
+		t.Fatalf("expected removed label, got %v", changes[1])
+	}
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	current := newDeployment("nginx:1.19", 2, nil)
+	rendered := newDeployment("nginx:1.19", 2, map[string]string{})
+
+	changes, err := Diff(current, rendered)
+	if err != nil {
+		t.Fatalf("fail to diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+}
+
+func TestDiffCreate(t *testing.T) {
+	changes, err := Diff(nil, newDeployment("nginx:1.19", 2, map[string]string{"app": "foo"}))
+	if err != nil {
+		t.Fatalf("fail to diff: %v", err)
+	}
+	if len(changes) == 0 || changes[0].Path != "metadata.labels.app" || changes[0].Old != nil {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
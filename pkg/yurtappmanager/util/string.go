@@ -16,6 +16,8 @@ limitations under the License.
 
 package util
 
+import "fmt"
+
 func ContainsString(slice []string, s string) bool {
 	for _, item := range slice {
 		if item == s {
@@ -34,3 +36,8 @@ func RemoveString(slice []string, s string) (result []string) {
 	}
 	return
 }
+
+// GetPoolServiceName returns the name of the Service generated for the pool of the YurtAppSet.
+func GetPoolServiceName(yasName, poolName string) string {
+	return fmt.Sprintf("%s-%s", yasName, poolName)
+}
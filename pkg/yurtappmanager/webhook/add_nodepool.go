@@ -18,15 +18,11 @@ package webhook
 
 import (
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
-	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/nodepool/mutating"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/nodepool/validating"
 )
 
 func init() {
-	if !gate.ResourceEnabled(&appsv1alpha1.NodePool{}) {
-		return
-	}
-	addHandlers(mutating.HandlerMap)
-	addHandlers(validating.HandlerMap)
+	addHandlers(&appsv1alpha1.NodePool{}, mutating.HandlerMap)
+	addHandlers(&appsv1alpha1.NodePool{}, validating.HandlerMap)
 }
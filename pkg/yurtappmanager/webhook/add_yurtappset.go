@@ -19,15 +19,11 @@ package webhook
 
 import (
 	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
-	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/yurtappset/mutating"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/yurtappset/validating"
 )
 
 func init() {
-	if !gate.ResourceEnabled(&unitv1alpha1.YurtAppSet{}) {
-		return
-	}
-	addHandlers(mutating.HandlerMap)
-	addHandlers(validating.HandlerMap)
+	addHandlers(&unitv1alpha1.YurtAppSet{}, mutating.HandlerMap)
+	addHandlers(&unitv1alpha1.YurtAppSet{}, validating.HandlerMap)
 }
@@ -18,15 +18,13 @@ package webhook
 
 import (
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
-	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
+	ingressmutating "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/ingress/mutating"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/yurtingress/mutating"
 	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/yurtingress/validating"
 )
 
 func init() {
-	if !gate.ResourceEnabled(&appsv1alpha1.YurtIngress{}) {
-		return
-	}
-	addHandlers(mutating.HandlerMap)
-	addHandlers(validating.HandlerMap)
+	addHandlers(&appsv1alpha1.YurtIngress{}, mutating.HandlerMap)
+	addHandlers(&appsv1alpha1.YurtIngress{}, validating.HandlerMap)
+	addHandlers(&appsv1alpha1.YurtIngress{}, ingressmutating.HandlerMap)
 }
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
+	webhookutil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/util"
+)
+
+// legacyIngressClassAnnotation is the deprecated annotation of the ingress class, which can not be set
+// together with spec.ingressClassName.
+const legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// IngressCreateUpdateHandler routes the Ingress annotated with yurtingress.io/nodepool to the ingress controller
+// of the pool, by setting its ingressClassName to the IngressClass created by YurtIngress for the pool.
+// If more than one YurtIngress enables ingress on the pool, the Ingress chooses one with the annotation
+// yurtingress.io/yurtingress.
+type IngressCreateUpdateHandler struct {
+	Client client.Client
+
+	// Decoder decodes objects
+	Decoder *admission.Decoder
+}
+
+var _ webhookutil.Handler = &IngressCreateUpdateHandler{}
+
+func (h *IngressCreateUpdateHandler) SetOptions(options webhookutil.Options) {
+	h.Client = options.Client
+}
+
+// Handle handles admission requests.
+func (h *IngressCreateUpdateHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ing := &networkingv1.Ingress{}
+	err := h.Decoder.Decode(req, ing)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	pool, ok := ing.Annotations[appsv1alpha1.IngressNodePoolKey]
+	if !ok {
+		return admission.Allowed("")
+	}
+	if _, ok := ing.Annotations[legacyIngressClassAnnotation]; ok {
+		return admission.Denied(fmt.Sprintf("annotation %s can not be set together with %s",
+			legacyIngressClassAnnotation, appsv1alpha1.IngressNodePoolKey))
+	}
+	className, err := getPoolIngressClass(ctx, h.Client, pool, ing.Annotations[appsv1alpha1.IngressYurtIngressKey])
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	klog.V(5).Infof("route ingress %s/%s to nodepool %s", ing.Namespace, ing.Name, pool)
+	ing.Spec.IngressClassName = &className
+
+	marshalled, err := json.Marshal(ing)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	resp := admission.PatchResponseFromRaw(req.AdmissionRequest.Object.Raw,
+		marshalled)
+	if len(resp.Patches) > 0 {
+		klog.V(5).Infof("Admit Ingress %s/%s patches: %v", ing.Namespace, ing.Name, util.DumpJSON(resp.Patches))
+	}
+	return resp
+}
+
+// getPoolIngressClass returns the name of the only IngressClass labeled with the pool,
+// and owned by the YurtIngress yingName if it is not empty.
+func getPoolIngressClass(ctx context.Context, c client.Client, pool, yingName string) (string, error) {
+	classes := &networkingv1.IngressClassList{}
+	if err := c.List(ctx, classes, client.MatchingLabels{appsv1alpha1.IngressNodePoolKey: pool}); err != nil {
+		return "", fmt.Errorf("fail to list the ingress classes of nodepool %s: %v", pool, err)
+	}
+	var names []string
+	for i := range classes.Items {
+		if yingName == "" || isOwnedByYurtIngress(&classes.Items[i], yingName) {
+			names = append(names, classes.Items[i].Name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		if yingName != "" {
+			return "", fmt.Errorf("no ingress class of YurtIngress %s is found for nodepool %s", yingName, pool)
+		}
+		return "", fmt.Errorf("no ingress class is found for nodepool %s, enable YurtIngress on it first", pool)
+	case 1:
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("more than one ingress class is found for nodepool %s, choose the YurtIngress with "+
+			"annotation %s", pool, appsv1alpha1.IngressYurtIngressKey)
+	}
+}
+
+func isOwnedByYurtIngress(class *networkingv1.IngressClass, yingName string) bool {
+	for _, ref := range class.OwnerReferences {
+		if ref.Kind == "YurtIngress" && ref.Name == yingName {
+			return true
+		}
+	}
+	return false
+}
+
+var _ admission.DecoderInjector = &IngressCreateUpdateHandler{}
+
+// InjectDecoder injects the decoder into the IngressCreateUpdateHandler
+func (h *IngressCreateUpdateHandler) InjectDecoder(d *admission.Decoder) error {
+	h.Decoder = d
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutating
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+func newIngressClass(name, pool, ying string) *networkingv1.IngressClass {
+	class := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{appsv1alpha1.IngressNodePoolKey: pool}},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+	if ying != "" {
+		class.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps.openyurt.io/v1alpha1",
+			Kind:       "YurtIngress",
+			Name:       ying,
+			UID:        types.UID(ying),
+		}}
+	}
+	return class
+}
+
+func TestHandle(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newIngressClass("hangzhou-nginx", "hangzhou", ""),
+		newIngressClass("shanghai-nginx", "shanghai", "ying-nginx"),
+		newIngressClass("shanghai-traefik", "shanghai", "ying-traefik"),
+	).Build()
+	decoder, _ := admission.NewDecoder(scheme)
+	h := &IngressCreateUpdateHandler{Client: c, Decoder: decoder}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		allowed     bool
+		patched     bool
+		class       string
+	}{
+		{name: "not annotated", allowed: true},
+		{name: "routed", annotations: map[string]string{appsv1alpha1.IngressNodePoolKey: "hangzhou"}, allowed: true, patched: true,
+			class: "hangzhou-nginx"},
+		{name: "no ingress class", annotations: map[string]string{appsv1alpha1.IngressNodePoolKey: "beijing"}},
+		{name: "ambiguous ingress class", annotations: map[string]string{appsv1alpha1.IngressNodePoolKey: "shanghai"}},
+		{name: "chosen yurtingress", annotations: map[string]string{
+			appsv1alpha1.IngressNodePoolKey:    "shanghai",
+			appsv1alpha1.IngressYurtIngressKey: "ying-traefik",
+		}, allowed: true, patched: true, class: "shanghai-traefik"},
+		{name: "unknown yurtingress", annotations: map[string]string{
+			appsv1alpha1.IngressNodePoolKey:    "shanghai",
+			appsv1alpha1.IngressYurtIngressKey: "ying-haproxy",
+		}},
+		{name: "legacy ingress class", annotations: map[string]string{
+			appsv1alpha1.IngressNodePoolKey: "hangzhou",
+			legacyIngressClassAnnotation:    "nginx",
+		}},
+	}
+	for _, tt := range tests {
+		ing := &networkingv1.Ingress{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Annotations: tt.annotations},
+		}
+		raw, _ := json.Marshal(ing)
+		resp := h.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+		if resp.Allowed != tt.allowed {
+			t.Errorf("%s: expected allowed %v, got %v: %v", tt.name, tt.allowed, resp.Allowed, resp.Result)
+			continue
+		}
+		if patched := len(resp.Patches) > 0; patched != tt.patched {
+			t.Errorf("%s: expected patched %v, got patches %v", tt.name, tt.patched, resp.Patches)
+			continue
+		}
+		if tt.patched && (resp.Patches[0].Path != "/spec/ingressClassName" || resp.Patches[0].Value != tt.class) {
+			t.Errorf("%s: unexpected patches %v", tt.name, resp.Patches)
+		}
+	}
+}
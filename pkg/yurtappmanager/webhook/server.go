@@ -18,17 +18,31 @@ limitations under the License.
 package webhook
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/capabilities"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/metrics"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/gate"
 	webhookutil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/util"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/util/controller"
 )
 
 var (
 	// HandlerMap contains all admission webhook handlers.
 	HandlerMap = map[string]webhookutil.Handler{}
+	// handlerResources is the custom resources of the handlers in HandlerMap, the handler of a path admits
+	// the requests without handling them while its resource is disabled.
+	handlerResources = map[string]runtime.Object{}
 )
 
 func init() {
@@ -42,7 +56,7 @@ func init() {
 	})
 }
 
-func addHandlers(m map[string]webhookutil.Handler) {
+func addHandlers(resource runtime.Object, m map[string]webhookutil.Handler) {
 	for path, handler := range m {
 		if len(path) == 0 {
 			klog.Warningf("Skip handler with empty path.")
@@ -56,10 +70,52 @@ func addHandlers(m map[string]webhookutil.Handler) {
 			klog.V(1).Infof("conflicting webhook builder path %v in handler map", path)
 		}
 		HandlerMap[path] = handler
+		handlerResources[path] = resource
+	}
+}
+
+// gatedHandler admits the requests without handling them while the custom resource of the handler is disabled
+// by the configuration, and records the latency and the rejections of the admission requests handled by it.
+type gatedHandler struct {
+	webhookutil.Handler
+	path     string
+	resource runtime.Object
+}
+
+func (h *gatedHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if h.resource != nil && !gate.ConfigEnabled(h.resource) {
+		return admission.Allowed(fmt.Sprintf("%T is disabled", h.resource))
+	}
+	start := time.Now()
+	resp := h.Handler.Handle(ctx, req)
+	metrics.ObserveAdmission(h.path, time.Since(start), resp.Allowed)
+	return resp
+}
+
+// InjectDecoder injects the decoder into the handler, which is not injected through the wrapper otherwise.
+func (h *gatedHandler) InjectDecoder(d *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(d, h.Handler)
+	return err
+}
+
+// InjectClient injects the client into the handler, which is not injected through the wrapper otherwise.
+func (h *gatedHandler) InjectClient(c client.Client) error {
+	if i, ok := h.Handler.(interface{ InjectClient(client.Client) error }); ok {
+		return i.InjectClient(c)
 	}
+	return nil
 }
 
-func SetupWithManager(mgr manager.Manager) error {
+// SetupWithManager registers the admission handlers on the webhook server of the manager, the handlers of all the
+// custom resources are registered so that they serve the CRDs installed after startup. If manageCerts is true,
+// the serving certificate of the webhook server is generated and rotated by yurt-app-manager itself.
+func SetupWithManager(mgr manager.Manager, manageCerts bool) error {
+	if manageCerts {
+		if err := setupCertController(mgr); err != nil {
+			return err
+		}
+	}
+
 	server := mgr.GetWebhookServer()
 	server.Host = "0.0.0.0"
 	server.Port = webhookutil.GetPort()
@@ -70,13 +126,34 @@ func SetupWithManager(mgr manager.Manager) error {
 		handler.SetOptions(webhookutil.Options{
 			Client: mgr.GetClient(),
 		})
-		server.Register(path, &webhook.Admission{Handler: handler})
+		server.Register(path, &webhook.Admission{Handler: &gatedHandler{path: path, Handler: handler, resource: handlerResources[path]}})
 		klog.V(3).Infof("Registered webhook handler %s", path)
 	}
 
+	// register the conversion webhook, which converts the custom resources between v1alpha1 and v1beta1
+	server.Register(webhookutil.ConversionPath, &conversion.Webhook{})
+	klog.V(3).Infof("Registered conversion webhook %s", webhookutil.ConversionPath)
+
 	return nil
 }
 
+// setupCertController generates the serving certificate before the webhook server starts, which fails without it,
+// and adds the controller rotating it to the manager.
+func setupCertController(mgr manager.Manager) error {
+	// the cache of the manager is not started yet
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	certController := controller.New(c)
+	if _, err := certController.Sync(context.TODO()); err != nil {
+		return fmt.Errorf("fail to generate the webhook certificates: %v", err)
+	}
+	return mgr.Add(certController)
+}
+
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingressclasses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;update;patch
@@ -0,0 +1,385 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller manages the serving certificate of the webhooks of yurt-app-manager itself, so neither
+// cert-manager nor the certgen jobs are needed.
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/certificate"
+	webhookutil "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/webhook/util"
+)
+
+const (
+	caValidity   = 10 * 365 * 24 * time.Hour
+	certValidity = 365 * 24 * time.Hour
+
+	// resyncPeriod is the period to check the certificates and the caBundles, which may be reset by an upgrade
+	// of the webhook configurations.
+	resyncPeriod = time.Minute
+	// retryPeriod is the period to retry after a failure, such as the conflict with another replica.
+	retryPeriod = 10 * time.Second
+	// secretRetries is how many times the Secret is synced again when another replica changes it at the same time.
+	secretRetries = 3
+)
+
+// caOverlapPeriod is how long the previous CA is still trusted after the serving certificate is reissued by
+// the new CA, in which every replica syncs the reissued certificate from the Secret.
+var caOverlapPeriod = 3 * resyncPeriod
+
+// ConversionCRDs is the CRDs whose conversion webhook is configured with the CA and the webhook Service.
+var ConversionCRDs = []string{
+	"nodepools.apps.openyurt.io",
+	"yurtappsets.apps.openyurt.io",
+	"yurtappdaemons.apps.openyurt.io",
+	"yurtingresses.apps.openyurt.io",
+}
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// The keys of the certificate Secret, the serving certificate and its key are written to the certificate
+// directory of the webhook server under the same names.
+const (
+	CACertKey = "ca.crt"
+	CAKeyKey  = "ca.key"
+	// PreviousCACertKey is the CA before the last rotation, which is trusted together with the new CA
+	// until the serving certificates of all the replicas are reissued by the new CA.
+	PreviousCACertKey = "previous-ca.crt"
+)
+
+// Controller generates the CA and the serving certificate of the webhook Service into the Secret, writes them to
+// the certificate directory of the webhook server, and injects the CA into the caBundles of the webhook
+// configurations and the conversion webhooks of the CRDs. The certificates are rotated when a third of their lifetime remains. It runs on every replica,
+// the replicas share the certificates through the Secret.
+type Controller struct {
+	client         client.Client
+	secret         client.ObjectKey
+	service        client.ObjectKey
+	certDir        string
+	mutatingName   string
+	validatingName string
+	crds           []string
+}
+
+// New returns the Controller of the Secret, the Service, the certificate directory and the webhook configurations
+// set by the environment variables of the webhook.
+func New(c client.Client) *Controller {
+	return &Controller{
+		client:         c,
+		secret:         client.ObjectKey{Namespace: webhookutil.GetNamespace(), Name: webhookutil.GetSecretName()},
+		service:        client.ObjectKey{Namespace: webhookutil.GetNamespace(), Name: webhookutil.GetServiceName()},
+		certDir:        webhookutil.GetCertDir(),
+		mutatingName:   webhookutil.GetMutatingWebhookConfigurationName(),
+		validatingName: webhookutil.GetValidatingWebhookConfigurationName(),
+		crds:           ConversionCRDs,
+	}
+}
+
+// Start syncs the certificates until the context is done.
+func (c *Controller) Start(ctx context.Context) error {
+	for {
+		next := resyncPeriod
+		renewTime, err := c.Sync(ctx)
+		if err != nil {
+			klog.Errorf("Fail to sync the webhook certificates: %v", err)
+			next = retryPeriod
+		} else if d := time.Until(renewTime); d < next {
+			next = d
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next):
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica needs the certificates to serve the webhooks.
+func (c *Controller) NeedLeaderElection() bool {
+	return false
+}
+
+func (c *Controller) hosts() []string {
+	return []string{
+		c.service.Name,
+		fmt.Sprintf("%s.%s", c.service.Name, c.service.Namespace),
+		fmt.Sprintf("%s.%s.svc", c.service.Name, c.service.Namespace),
+	}
+}
+
+// Sync ensures the certificates in the Secret, the certificate directory, the webhook configurations and the CRDs,
+// and returns the time when the certificates should be rotated next.
+func (c *Controller) Sync(ctx context.Context) (time.Time, error) {
+	ca, cert, caBundle, err := c.ensureSecret(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// the caBundles are injected before the serving certificate is written, so that a serving certificate
+	// reissued by a new CA is trusted once it is served
+	if err := c.injectMutatingCABundle(ctx, caBundle); err != nil {
+		return time.Time{}, err
+	}
+	if err := c.injectValidatingCABundle(ctx, caBundle); err != nil {
+		return time.Time{}, err
+	}
+	for _, name := range c.crds {
+		if err := c.injectConversion(ctx, name, caBundle); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if err := c.writeCertDir(ca, cert); err != nil {
+		return time.Time{}, err
+	}
+	renewTime := certificate.RenewTime(cert.Cert)
+	if caRenewTime := certificate.RenewTime(ca.Cert); caRenewTime.Before(renewTime) {
+		renewTime = caRenewTime
+	}
+	return renewTime, nil
+}
+
+// ensureSecret syncs the Secret, and returns the CA, the serving certificate and the caBundle to inject.
+// The Secret is synced again if another replica creates or updates it at the same time, and the certificates
+// of that replica are used.
+func (c *Controller) ensureSecret(ctx context.Context) (ca, cert *certificate.KeyPair, caBundle []byte, err error) {
+	for i := 0; ; i++ {
+		ca, cert, caBundle, err = c.syncSecret(ctx)
+		if err == nil || i >= secretRetries || !(apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err)) {
+			break
+		}
+		klog.V(4).Infof("secret %s is changed by another replica, sync it again", c.secret)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("fail to sync the secret %s: %v", c.secret, err)
+	}
+	return ca, cert, caBundle, nil
+}
+
+// syncSecret generates the CA and the serving certificate if they are missing, invalid or to be rotated.
+// A rotated CA is kept in the caBundle together with the new one until the serving certificates are reissued
+// by the new CA: the serving certificate is reissued in the next sync after the caBundles with the new CA are
+// injected, and the previous CA is dropped caOverlapPeriod after that.
+func (c *Controller) syncSecret(ctx context.Context) (ca, cert *certificate.KeyPair, caBundle []byte, err error) {
+	secret := &corev1.Secret{}
+	if err := c.client.Get(ctx, c.secret, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, nil, nil, err
+		}
+		secret = nil
+	}
+	var data map[string][]byte
+	if secret != nil {
+		data = secret.Data
+	}
+
+	now := time.Now()
+	changed, caRotated := false, false
+	previousCAPEM := data[PreviousCACertKey]
+	ca, err = certificate.ParseKeyPair(data[CACertKey], data[CAKeyKey])
+	if err != nil || !ca.Cert.IsCA || now.After(certificate.RenewTime(ca.Cert)) {
+		previousCAPEM = nil
+		if err == nil && ca.Cert.IsCA && now.Before(ca.Cert.NotAfter) {
+			// the serving certificates signed by the rotated CA are still served
+			previousCAPEM, caRotated = ca.CertPEM, true
+		}
+		klog.Infof("generate the webhook CA in secret %s", c.secret)
+		if ca, err = certificate.NewCA(c.service.Name+"-ca", caValidity); err != nil {
+			return nil, nil, nil, err
+		}
+		changed = true
+	}
+	var previousCA *x509.Certificate
+	if len(previousCAPEM) > 0 {
+		if previousCA, err = certificate.ParseCert(previousCAPEM); err != nil || now.After(previousCA.NotAfter) {
+			previousCA, previousCAPEM, changed = nil, nil, true
+		}
+	}
+
+	cert, err = certificate.ParseKeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if err == nil && caRotated && certificate.VerifyServingCert(previousCA, cert.Cert, c.hosts(), now) == nil {
+		klog.Infof("the webhook CA in secret %s is rotated, the serving certificate is reissued next time", c.secret)
+	} else if err != nil || certificate.VerifyServingCert(ca.Cert, cert.Cert, c.hosts(), now) != nil ||
+		now.After(certificate.RenewTime(cert.Cert)) {
+		klog.Infof("generate the webhook serving certificate in secret %s", c.secret)
+		if cert, err = certificate.NewServingCert(ca, c.service.Name, c.hosts(), certValidity); err != nil {
+			return nil, nil, nil, err
+		}
+		changed = true
+	} else if previousCA != nil && now.After(certificate.IssueTime(cert.Cert).Add(caOverlapPeriod)) {
+		klog.Infof("the serving certificate in secret %s is reissued by the new CA, drop the previous CA", c.secret)
+		previousCAPEM, changed = nil, true
+	}
+	caBundle = append(append([]byte{}, ca.CertPEM...), previousCAPEM...)
+	if !changed {
+		return ca, cert, caBundle, nil
+	}
+
+	newData := map[string][]byte{
+		CACertKey:               ca.CertPEM,
+		CAKeyKey:                ca.KeyPEM,
+		corev1.TLSCertKey:       cert.CertPEM,
+		corev1.TLSPrivateKeyKey: cert.KeyPEM,
+	}
+	if len(previousCAPEM) > 0 {
+		newData[PreviousCACertKey] = previousCAPEM
+	}
+	if secret == nil {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: c.secret.Namespace, Name: c.secret.Name},
+			Data:       newData,
+		}
+		if err := c.client.Create(ctx, secret); err != nil {
+			return nil, nil, nil, err
+		}
+		return ca, cert, caBundle, nil
+	}
+	secret.Data = newData
+	if err := c.client.Update(ctx, secret); err != nil {
+		return nil, nil, nil, err
+	}
+	return ca, cert, caBundle, nil
+}
+
+// writeCertDir writes the serving certificate and the CA to the certificate directory, the webhook server
+// reloads the changed certificate.
+func (c *Controller) writeCertDir(ca, cert *certificate.KeyPair) error {
+	if err := os.MkdirAll(c.certDir, 0700); err != nil {
+		return fmt.Errorf("fail to create the certificate directory %s: %v", c.certDir, err)
+	}
+	// the key is written before the certificate, so the webhook server reloads the pair once the certificate is written
+	for _, f := range []struct {
+		name string
+		data []byte
+	}{
+		{corev1.TLSPrivateKeyKey, cert.KeyPEM},
+		{corev1.TLSCertKey, cert.CertPEM},
+		{CACertKey, ca.CertPEM},
+	} {
+		path := filepath.Join(c.certDir, f.name)
+		if data, err := ioutil.ReadFile(path); err == nil && bytes.Equal(data, f.data) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, f.data, 0600); err != nil {
+			return fmt.Errorf("fail to write %s: %v", path, err)
+		}
+		klog.V(4).Infof("%s is written", path)
+	}
+	return nil
+}
+
+func (c *Controller) injectMutatingCABundle(ctx context.Context, caBundle []byte) error {
+	mwc := &admissionv1.MutatingWebhookConfiguration{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: c.mutatingName}, mwc); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("mutatingwebhookconfiguration/%s is not found, skip injecting the CA", c.mutatingName)
+			return nil
+		}
+		return fmt.Errorf("fail to get the mutatingwebhookconfiguration/%s: %v", c.mutatingName, err)
+	}
+	changed := false
+	for i := range mwc.Webhooks {
+		if !bytes.Equal(mwc.Webhooks[i].ClientConfig.CABundle, caBundle) {
+			mwc.Webhooks[i].ClientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.client.Update(ctx, mwc); err != nil {
+		return fmt.Errorf("fail to update the mutatingwebhookconfiguration/%s: %v", c.mutatingName, err)
+	}
+	klog.Infof("the CA of mutatingwebhookconfiguration/%s is injected", c.mutatingName)
+	return nil
+}
+
+func (c *Controller) injectValidatingCABundle(ctx context.Context, caBundle []byte) error {
+	vwc := &admissionv1.ValidatingWebhookConfiguration{}
+	if err := c.client.Get(ctx, client.ObjectKey{Name: c.validatingName}, vwc); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("validatingwebhookconfiguration/%s is not found, skip injecting the CA", c.validatingName)
+			return nil
+		}
+		return fmt.Errorf("fail to get the validatingwebhookconfiguration/%s: %v", c.validatingName, err)
+	}
+	changed := false
+	for i := range vwc.Webhooks {
+		if !bytes.Equal(vwc.Webhooks[i].ClientConfig.CABundle, caBundle) {
+			vwc.Webhooks[i].ClientConfig.CABundle = caBundle
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := c.client.Update(ctx, vwc); err != nil {
+		return fmt.Errorf("fail to update the validatingwebhookconfiguration/%s: %v", c.validatingName, err)
+	}
+	klog.Infof("the CA of validatingwebhookconfiguration/%s is injected", c.validatingName)
+	return nil
+}
+
+// injectConversion injects the CA into the conversion webhook of the CRD. The conversion webhook is configured by the
+// manifests of the CRD, the CRDs without the Webhook conversion strategy are left alone. The CRD is handled as
+// unstructured, so that the apiextensions types are not needed in the scheme.
+func (c *Controller) injectConversion(ctx context.Context, name string, caBundle []byte) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := c.client.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("customresourcedefinition/%s is not found, skip injecting the CA of the conversion", name)
+			return nil
+		}
+		return fmt.Errorf("fail to get the customresourcedefinition/%s: %v", name, err)
+	}
+	if strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy"); strategy != "Webhook" {
+		klog.V(4).Infof("customresourcedefinition/%s has no conversion webhook, skip injecting the CA", name)
+		return nil
+	}
+	encoded := base64.StdEncoding.EncodeToString(caBundle)
+	if current, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig",
+		"caBundle"); current == encoded {
+		return nil
+	}
+	patch := client.MergeFrom(crd.DeepCopy())
+	if err := unstructured.SetNestedField(crd.Object, encoded, "spec", "conversion", "webhook", "clientConfig",
+		"caBundle"); err != nil {
+		return err
+	}
+	if err := c.client.Patch(ctx, crd, patch); err != nil {
+		return fmt.Errorf("fail to inject the CA into the conversion of customresourcedefinition/%s: %v", name, err)
+	}
+	klog.Infof("the CA of the conversion webhook of customresourcedefinition/%s is injected", name)
+	return nil
+}
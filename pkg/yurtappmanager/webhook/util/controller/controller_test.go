@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util/certificate"
+)
+
+func TestSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook-certs")
+	if err != nil {
+		t.Fatalf("fail to create the certificate directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	mwc := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "yurt-app-manager"},
+		Webhooks:   []admissionv1.MutatingWebhook{{Name: "mnodepool.kb.io"}, {Name: "myurtappset.kb.io"}},
+	}
+	vwc := &admissionv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "yurt-app-manager"},
+		Webhooks:   []admissionv1.ValidatingWebhook{{Name: "vnodepool.kb.io"}},
+	}
+	scheme.AddKnownTypeWithName(crdGVK, &unstructured.Unstructured{})
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName("nodepools.apps.openyurt.io")
+	// the conversion webhook is configured by the manifests with a placeholder caBundle
+	_ = unstructured.SetNestedMap(crd.Object, map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"namespace": "kube-system",
+					"name":      "yurt-app-manager",
+					"path":      "/convert",
+				},
+				"caBundle": "Cg==",
+			},
+			"conversionReviewVersions": []interface{}{"v1", "v1beta1"},
+		},
+	}, "spec", "conversion")
+	noneCRD := &unstructured.Unstructured{}
+	noneCRD.SetGroupVersionKind(crdGVK)
+	noneCRD.SetName("yurtingresses.apps.openyurt.io")
+	_ = unstructured.SetNestedField(noneCRD.Object, "None", "spec", "conversion", "strategy")
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mwc, vwc, crd, noneCRD).Build()
+	c := &Controller{
+		client:         cli,
+		secret:         client.ObjectKey{Namespace: "kube-system", Name: "yurt-app-manager"},
+		service:        client.ObjectKey{Namespace: "kube-system", Name: "yurt-app-manager"},
+		certDir:        filepath.Join(dir, "serving-certs"),
+		mutatingName:   "yurt-app-manager",
+		validatingName: "yurt-app-manager",
+		crds:           []string{"nodepools.apps.openyurt.io", "yurtingresses.apps.openyurt.io"},
+	}
+
+	renewTime, err := c.Sync(context.TODO())
+	if err != nil {
+		t.Fatalf("fail to sync the certificates: %v", err)
+	}
+	if !renewTime.After(time.Now()) {
+		t.Fatalf("unexpected renew time %v", renewTime)
+	}
+	secret := &corev1.Secret{}
+	if err := cli.Get(context.TODO(), c.secret, secret); err != nil {
+		t.Fatalf("fail to get the certificate secret: %v", err)
+	}
+	ca, err := certificate.ParseKeyPair(secret.Data[CACertKey], secret.Data[CAKeyKey])
+	if err != nil {
+		t.Fatalf("fail to parse the CA: %v", err)
+	}
+	certPEM, err := ioutil.ReadFile(filepath.Join(c.certDir, corev1.TLSCertKey))
+	if err != nil {
+		t.Fatalf("fail to read the serving certificate: %v", err)
+	}
+	keyPEM, err := ioutil.ReadFile(filepath.Join(c.certDir, corev1.TLSPrivateKeyKey))
+	if err != nil {
+		t.Fatalf("fail to read the serving key: %v", err)
+	}
+	cert, err := certificate.ParseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("fail to parse the serving certificate: %v", err)
+	}
+	if err := certificate.VerifyServingCert(ca.Cert, cert.Cert,
+		[]string{"yurt-app-manager.kube-system.svc"}, time.Now()); err != nil {
+		t.Fatalf("expected the serving certificate valid for the service: %v", err)
+	}
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: "yurt-app-manager"}, mwc); err != nil {
+		t.Fatalf("fail to get the mutatingwebhookconfiguration: %v", err)
+	}
+	for _, w := range mwc.Webhooks {
+		if !bytes.Equal(w.ClientConfig.CABundle, ca.CertPEM) {
+			t.Fatalf("expected the CA injected into the mutating webhook %s", w.Name)
+		}
+	}
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: "yurt-app-manager"}, vwc); err != nil {
+		t.Fatalf("fail to get the validatingwebhookconfiguration: %v", err)
+	}
+	if !bytes.Equal(vwc.Webhooks[0].ClientConfig.CABundle, ca.CertPEM) {
+		t.Fatalf("expected the CA injected into the validating webhook")
+	}
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: "nodepools.apps.openyurt.io"}, crd); err != nil {
+		t.Fatalf("fail to get the customresourcedefinition: %v", err)
+	}
+	if strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy"); strategy != "Webhook" {
+		t.Fatalf("expected the conversion strategy Webhook, got %q", strategy)
+	}
+	if path, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "path"); path != "/convert" {
+		t.Fatalf("expected the conversion webhook path /convert, got %q", path)
+	}
+	caBundle, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+	if caBundle != base64.StdEncoding.EncodeToString(ca.CertPEM) {
+		t.Fatalf("expected the CA injected into the conversion webhook")
+	}
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: "yurtingresses.apps.openyurt.io"}, noneCRD); err != nil {
+		t.Fatalf("fail to get the customresourcedefinition: %v", err)
+	}
+	if strategy, _, _ := unstructured.NestedString(noneCRD.Object, "spec", "conversion", "strategy"); strategy != "None" {
+		t.Fatalf("expected the CRD without the conversion webhook to be left alone, got strategy %q", strategy)
+	}
+
+	// the valid certificates are kept
+	version := secret.ResourceVersion
+	if _, err := c.Sync(context.TODO()); err != nil {
+		t.Fatalf("fail to sync the certificates: %v", err)
+	}
+	if err := cli.Get(context.TODO(), c.secret, secret); err != nil {
+		t.Fatalf("fail to get the certificate secret: %v", err)
+	}
+	if secret.ResourceVersion != version {
+		t.Fatalf("expected the valid certificates to be kept")
+	}
+	crdVersion := crd.GetResourceVersion()
+	if err := cli.Get(context.TODO(), client.ObjectKey{Name: "nodepools.apps.openyurt.io"}, crd); err != nil {
+		t.Fatalf("fail to get the customresourcedefinition: %v", err)
+	}
+	if crd.GetResourceVersion() != crdVersion {
+		t.Fatalf("expected the configured conversion to be kept")
+	}
+
+	// the serving certificate is regenerated for a renamed service, and the CA is kept
+	c.service.Name = "yurt-app-manager-webhook"
+	if _, err := c.Sync(context.TODO()); err != nil {
+		t.Fatalf("fail to sync the certificates: %v", err)
+	}
+	if err := cli.Get(context.TODO(), c.secret, secret); err != nil {
+		t.Fatalf("fail to get the certificate secret: %v", err)
+	}
+	if !bytes.Equal(secret.Data[CACertKey], ca.CertPEM) || bytes.Equal(secret.Data[corev1.TLSCertKey], cert.CertPEM) {
+		t.Fatalf("expected the serving certificate regenerated with the same CA")
+	}
+	if certPEM, err = ioutil.ReadFile(filepath.Join(c.certDir, corev1.TLSCertKey)); err != nil ||
+		!bytes.Equal(certPEM, secret.Data[corev1.TLSCertKey]) {
+		t.Fatalf("expected the regenerated serving certificate written, got %v", err)
+	}
+}
+
+func newTestController(t *testing.T, objs ...client.Object) *Controller {
+	dir, err := ioutil.TempDir("", "webhook-certs")
+	if err != nil {
+		t.Fatalf("fail to create the certificate directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	return &Controller{
+		client:         fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		secret:         client.ObjectKey{Namespace: "kube-system", Name: "yurt-app-manager"},
+		service:        client.ObjectKey{Namespace: "kube-system", Name: "yurt-app-manager"},
+		certDir:        filepath.Join(dir, "serving-certs"),
+		mutatingName:   "yurt-app-manager",
+		validatingName: "yurt-app-manager",
+	}
+}
+
+func newTestSecret(t *testing.T, caValidity time.Duration) (*corev1.Secret, *certificate.KeyPair) {
+	ca, err := certificate.NewCA("yurt-app-manager-ca", caValidity)
+	if err != nil {
+		t.Fatalf("fail to generate the CA: %v", err)
+	}
+	cert, err := certificate.NewServingCert(ca, "yurt-app-manager", []string{"yurt-app-manager",
+		"yurt-app-manager.kube-system", "yurt-app-manager.kube-system.svc"}, certValidity)
+	if err != nil {
+		t.Fatalf("fail to generate the serving certificate: %v", err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "yurt-app-manager"},
+		Data: map[string][]byte{
+			CACertKey:               ca.CertPEM,
+			CAKeyKey:                ca.KeyPEM,
+			corev1.TLSCertKey:       cert.CertPEM,
+			corev1.TLSPrivateKeyKey: cert.KeyPEM,
+		},
+	}, ca
+}
+
+func TestRotateCA(t *testing.T) {
+	// the CA is to be rotated, since less than a third of its lifetime remains
+	secret, oldCA := newTestSecret(t, 20*time.Minute)
+	servingCert := append([]byte{}, secret.Data[corev1.TLSCertKey]...)
+	mwc := &admissionv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "yurt-app-manager"},
+		Webhooks:   []admissionv1.MutatingWebhook{{Name: "mnodepool.kb.io"}},
+	}
+	c := newTestController(t, secret, mwc)
+	sync := func() (*corev1.Secret, []byte) {
+		if _, err := c.Sync(context.TODO()); err != nil {
+			t.Fatalf("fail to sync the certificates: %v", err)
+		}
+		got := &corev1.Secret{}
+		if err := c.client.Get(context.TODO(), c.secret, got); err != nil {
+			t.Fatalf("fail to get the certificate secret: %v", err)
+		}
+		if err := c.client.Get(context.TODO(), client.ObjectKey{Name: c.mutatingName}, mwc); err != nil {
+			t.Fatalf("fail to get the mutatingwebhookconfiguration: %v", err)
+		}
+		return got, mwc.Webhooks[0].ClientConfig.CABundle
+	}
+
+	// the serving certificate of the old CA is kept, and both of the CAs are trusted
+	got, caBundle := sync()
+	if bytes.Equal(got.Data[CACertKey], oldCA.CertPEM) || !bytes.Equal(got.Data[PreviousCACertKey], oldCA.CertPEM) {
+		t.Fatalf("expected the CA rotated and the previous CA kept")
+	}
+	if !bytes.Equal(got.Data[corev1.TLSCertKey], servingCert) {
+		t.Fatalf("expected the serving certificate kept until the new CA is injected")
+	}
+	if !bytes.Equal(caBundle, append(append([]byte{}, got.Data[CACertKey]...), oldCA.CertPEM...)) {
+		t.Fatalf("expected the new and the previous CA injected, got %s", caBundle)
+	}
+	newCA := got.Data[CACertKey]
+
+	// the serving certificate is reissued by the new CA, and the previous CA is still trusted
+	got, caBundle = sync()
+	if bytes.Equal(got.Data[corev1.TLSCertKey], servingCert) {
+		t.Fatalf("expected the serving certificate reissued")
+	}
+	ca, _ := certificate.ParseCert(newCA)
+	cert, _ := certificate.ParseCert(got.Data[corev1.TLSCertKey])
+	if err := certificate.VerifyServingCert(ca, cert, c.hosts(), time.Now()); err != nil {
+		t.Fatalf("expected the serving certificate reissued by the new CA: %v", err)
+	}
+	if !bytes.Equal(got.Data[PreviousCACertKey], oldCA.CertPEM) || !bytes.Contains(caBundle, oldCA.CertPEM) {
+		t.Fatalf("expected the previous CA trusted until the serving certificates are reissued")
+	}
+
+	// the previous CA is dropped once the replicas have synced the reissued certificate
+	defer func(period time.Duration) { caOverlapPeriod = period }(caOverlapPeriod)
+	caOverlapPeriod = -2 * time.Hour
+	got, caBundle = sync()
+	if _, ok := got.Data[PreviousCACertKey]; ok || !bytes.Equal(caBundle, newCA) {
+		t.Fatalf("expected the previous CA dropped, got %s", caBundle)
+	}
+}
+
+// racingClient creates the Secret of another replica right before the Secret is created.
+type racingClient struct {
+	client.Client
+	winner *corev1.Secret
+}
+
+func (c *racingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.winner != nil {
+		winner := c.winner
+		c.winner = nil
+		if err := c.Client.Create(ctx, winner); err != nil {
+			return err
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestCreateSecretRace(t *testing.T) {
+	c := newTestController(t)
+	winner, winnerCA := newTestSecret(t, caValidity)
+	c.client = &racingClient{Client: c.client, winner: winner}
+	ca, _, caBundle, err := c.ensureSecret(context.TODO())
+	if err != nil {
+		t.Fatalf("expected the secret created by another replica to be used, got %v", err)
+	}
+	if !bytes.Equal(ca.CertPEM, winnerCA.CertPEM) || !bytes.Equal(caBundle, winnerCA.CertPEM) {
+		t.Fatalf("expected the CA of the other replica")
+	}
+}
@@ -22,13 +22,33 @@ import (
 	"strconv"
 
 	"k8s.io/klog"
+
+	configv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/config/v1alpha1"
 )
 
+// ConversionPath is the path of the webhook which converts the custom resources between their versions.
+const ConversionPath = "/convert"
+
+// configuration is the webhook settings of the configuration of yurt-app-manager, which take precedence
+// over the environment variables
+var configuration *configv1alpha1.WebhookConfiguration
+
+// SetConfiguration sets the webhook settings returned by the getters of this package.
+func SetConfiguration(c *configv1alpha1.WebhookConfiguration) {
+	configuration = c
+}
+
 func GetHost() string {
+	if configuration != nil {
+		return configuration.Host
+	}
 	return os.Getenv("WEBHOOK_HOST")
 }
 
 func GetNamespace() string {
+	if configuration != nil && len(configuration.Namespace) > 0 {
+		return configuration.Namespace
+	}
 	if ns := os.Getenv("POD_NAMESPACE"); len(ns) > 0 {
 		return ns
 	}
@@ -36,6 +56,9 @@ func GetNamespace() string {
 }
 
 func GetSecretName() string {
+	if configuration != nil && len(configuration.SecretName) > 0 {
+		return configuration.SecretName
+	}
 	if name := os.Getenv("SECRET_NAME"); len(name) > 0 {
 		return name
 	}
@@ -43,6 +66,9 @@ func GetSecretName() string {
 }
 
 func GetServiceName() string {
+	if configuration != nil && len(configuration.ServiceName) > 0 {
+		return configuration.ServiceName
+	}
 	if name := os.Getenv("SERVICE_NAME"); len(name) > 0 {
 		return name
 	}
@@ -50,6 +76,9 @@ func GetServiceName() string {
 }
 
 func GetPort() int {
+	if configuration != nil && configuration.Port > 0 {
+		return int(configuration.Port)
+	}
 	port := 9876
 	if p := os.Getenv("WEBHOOK_PORT"); len(p) > 0 {
 		if p, err := strconv.ParseInt(p, 10, 32); err == nil {
@@ -62,6 +91,9 @@ func GetPort() int {
 }
 
 func GetCertDir() string {
+	if configuration != nil && len(configuration.CertDir) > 0 {
+		return configuration.CertDir
+	}
 	if p := os.Getenv("WEBHOOK_CERT_DIR"); len(p) > 0 {
 		return p
 	}
@@ -69,6 +101,9 @@ func GetCertDir() string {
 }
 
 func GetMutatingWebhookConfigurationName() string {
+	if configuration != nil && len(configuration.MutatingWebhookConfigurationName) > 0 {
+		return configuration.MutatingWebhookConfigurationName
+	}
 	if p := os.Getenv("MUTATING_WEBHOOK_CONFIGURATION_NAME"); len(p) > 0 {
 		return p
 	}
@@ -76,6 +111,9 @@ func GetMutatingWebhookConfigurationName() string {
 }
 
 func GetValidatingWebhookConfigurationName() string {
+	if configuration != nil && len(configuration.ValidatingWebhookConfigurationName) > 0 {
+		return configuration.ValidatingWebhookConfigurationName
+	}
 	if p := os.Getenv("VALIDATING_WEBHOOK_CONFIGURATION_NAME"); len(p) > 0 {
 		return p
 	}
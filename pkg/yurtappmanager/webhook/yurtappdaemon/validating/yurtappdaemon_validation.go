@@ -83,6 +83,9 @@ func validateWorkLoadTemplate(template *unitv1alpha1.WorkloadTemplate, selector
 	} else if templateCount > 1 {
 		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of (statefulSetTemplate/deploymentTemplate)"))
 	}
+	if template.CustomTemplate != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("custom"), "custom template is only supported by YurtAppSet"))
+	}
 
 	if template.StatefulSetTemplate != nil {
 		labels := labels.Set(template.StatefulSetTemplate.Labels)
@@ -18,6 +18,7 @@ limitations under the License.
 package validating
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -27,8 +28,10 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	unversionedvalidation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog"
@@ -39,6 +42,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	unitv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/util"
 )
 
 // ValidateYurtAppSetSpec tests if required fields in the YurtAppSet spec are set.
@@ -108,6 +112,63 @@ func validateYurtAppSetSpec(c client.Client, spec *unitv1alpha1.YurtAppSetSpec,
 
 	}
 
+	if spec.ElasticPlacement != nil {
+		allErrs = append(allErrs, validateElasticPlacement(spec.ElasticPlacement, poolNames, fldPath.Child("elasticPlacement"))...)
+	}
+	if spec.AutonomyCompensation != nil {
+		allErrs = append(allErrs, validateAutonomyCompensation(spec.AutonomyCompensation, poolNames, fldPath.Child("autonomyCompensation"))...)
+	}
+
+	return allErrs
+}
+
+func validateElasticPlacement(strategy *unitv1alpha1.ElasticPlacementStrategy, poolNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(strategy.FallbackPools) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("fallbackPools"), ""))
+	}
+	fallbackPools := sets.String{}
+	for i, name := range strategy.FallbackPools {
+		if !poolNames.Has(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("fallbackPools").Index(i), name,
+				fmt.Sprintf("pool %s is not in topology", name)))
+		}
+		if fallbackPools.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("fallbackPools").Index(i), name))
+		}
+		fallbackPools.Insert(name)
+	}
+	if strategy.UnschedulableThresholdSeconds != nil && *strategy.UnschedulableThresholdSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("unschedulableThresholdSeconds"),
+			*strategy.UnschedulableThresholdSeconds, "must be greater than 0"))
+	}
+	return allErrs
+}
+
+func validateAutonomyCompensation(policy *unitv1alpha1.AutonomyCompensationPolicy, poolNames sets.String, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(policy.CompensationPools) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("compensationPools"), ""))
+	}
+	compensationPools := sets.String{}
+	for i, name := range policy.CompensationPools {
+		if !poolNames.Has(name) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("compensationPools").Index(i), name,
+				fmt.Sprintf("pool %s is not in topology", name)))
+		}
+		if compensationPools.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("compensationPools").Index(i), name))
+		}
+		compensationPools.Insert(name)
+	}
+	if policy.MinReadyNodePercentage != nil && (*policy.MinReadyNodePercentage <= 0 || *policy.MinReadyNodePercentage > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minReadyNodePercentage"),
+			*policy.MinReadyNodePercentage, "must be in the range 1-100"))
+	}
+	if policy.OfflineThresholdSeconds != nil && *policy.OfflineThresholdSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("offlineThresholdSeconds"),
+			*policy.OfflineThresholdSeconds, "must be greater than 0"))
+	}
 	return allErrs
 }
 
@@ -115,6 +176,38 @@ func validateYurtAppSetSpec(c client.Client, spec *unitv1alpha1.YurtAppSetSpec,
 func validateYurtAppSet(c client.Client, yurtAppSet *unitv1alpha1.YurtAppSet) field.ErrorList {
 	allErrs := apivalidation.ValidateObjectMeta(&yurtAppSet.ObjectMeta, true, apimachineryvalidation.NameIsDNSSubdomain, field.NewPath("metadata"))
 	allErrs = append(allErrs, validateYurtAppSetSpec(c, &yurtAppSet.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, validateServiceTemplate(yurtAppSet, field.NewPath("spec", "serviceTemplate"))...)
+	return allErrs
+}
+
+// validateServiceTemplate validates the per-pool Service template of a YurtAppSet.
+func validateServiceTemplate(yurtAppSet *unitv1alpha1.YurtAppSet, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	template := yurtAppSet.Spec.ServiceTemplate
+	if template == nil {
+		return allErrs
+	}
+
+	if _, exist := template.Spec.Selector[unitv1alpha1.PoolNameLabelKey]; exist {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec", "selector"), template.Spec.Selector,
+			fmt.Sprintf("label %s is managed by YurtAppSet", unitv1alpha1.PoolNameLabelKey)))
+	}
+	if template.Spec.Type != "" && template.Spec.Type != v1.ServiceTypeClusterIP &&
+		template.Spec.Type != v1.ServiceTypeNodePort && template.Spec.Type != v1.ServiceTypeLoadBalancer {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("spec", "type"), template.Spec.Type,
+			[]string{string(v1.ServiceTypeClusterIP), string(v1.ServiceTypeNodePort), string(v1.ServiceTypeLoadBalancer)}))
+	}
+	if len(template.Spec.Ports) == 0 && template.Spec.ClusterIP != v1.ClusterIPNone {
+		allErrs = append(allErrs, field.Required(fldPath.Child("spec", "ports"), "ports are required unless the Service is headless"))
+	}
+
+	for i, pool := range yurtAppSet.Spec.Topology.Pools {
+		svcName := util.GetPoolServiceName(yurtAppSet.Name, pool.Name)
+		if errs := apimachineryvalidation.NameIsDNS1035Label(svcName, false); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "topology", "pools").Index(i).Child("name"), pool.Name,
+				fmt.Sprintf("invalid pool Service name %s: %s", svcName, strings.Join(errs, ", "))))
+		}
+	}
 	return allErrs
 }
 
@@ -135,6 +228,12 @@ func convertPodTemplateSpec(template *v1.PodTemplateSpec) (*core.PodTemplateSpec
 
 func validateYurtAppSetSpecUpdate(spec, oldSpec *unitv1alpha1.YurtAppSetSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	// the serviceName of StatefulSet can not be changed, so the per-pool Service can not be added or removed
+	if spec.WorkloadTemplate.StatefulSetTemplate != nil && oldSpec.WorkloadTemplate.StatefulSetTemplate != nil &&
+		(spec.ServiceTemplate == nil) != (oldSpec.ServiceTemplate == nil) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("serviceTemplate"),
+			"may not be added or removed in an update when statefulSetTemplate is used"))
+	}
 	allErrs = append(allErrs, validateWorkloadTemplateUpdate(&spec.WorkloadTemplate, &oldSpec.WorkloadTemplate, fldPath.Child("workloadTemplate"))...)
 	allErrs = append(allErrs, validateYurtAppSetTopology(&spec.Topology, &oldSpec.Topology, fldPath.Child("topology"))...)
 	return allErrs
@@ -174,6 +273,12 @@ func validateWorkloadTemplateUpdate(template, oldTemplate *unitv1alpha1.Workload
 		allErrs = append(allErrs, validateDeploymentUpdate(template.DeploymentTemplate, oldTemplate.DeploymentTemplate,
 			fldPath.Child("deploymentTemplate"))...)
 	}
+	// the pools of the old custom kind could not be found once the kind is changed
+	if oldTemplate.CustomTemplate != nil && (template.CustomTemplate == nil ||
+		schema.FromAPIVersionAndKind(template.CustomTemplate.APIVersion, template.CustomTemplate.Kind).GroupKind() !=
+			schema.FromAPIVersionAndKind(oldTemplate.CustomTemplate.APIVersion, oldTemplate.CustomTemplate.Kind).GroupKind()) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("custom"), "the workload kind of custom template is immutable"))
+	}
 	return allErrs
 }
 
@@ -189,11 +294,18 @@ func validatePoolTemplate(template *unitv1alpha1.WorkloadTemplate, spec *unitv1a
 	if template.DeploymentTemplate != nil {
 		templateCount++
 	}
+	if template.CustomTemplate != nil {
+		templateCount++
+	}
 
 	if templateCount < 1 {
-		allErrs = append(allErrs, field.Required(fldPath, "should provide one of (statefulSetTemplate/deploymentTemplate/daemonSetTemplate)"))
+		allErrs = append(allErrs, field.Required(fldPath, "should provide one of (statefulSetTemplate/deploymentTemplate/custom)"))
 	} else if templateCount > 1 {
-		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of (statefulSetTemplate/deploymentTemplate/daemonSetTemplate)"))
+		allErrs = append(allErrs, field.Invalid(fldPath, template, "should provide only one of (statefulSetTemplate/deploymentTemplate/custom)"))
+	}
+
+	if template.CustomTemplate != nil {
+		allErrs = append(allErrs, validateCustomTemplate(template.CustomTemplate, selector, fldPath.Child("custom"))...)
 	}
 
 	if template.StatefulSetTemplate != nil {
@@ -235,6 +347,36 @@ func validatePoolTemplate(template *unitv1alpha1.WorkloadTemplate, spec *unitv1a
 	return allErrs
 }
 
+// validateCustomTemplate checks the custom template. The pod template of the workload is checked by the
+// apiserver of the workload kind, because its path is only known by the workload registry.
+func validateCustomTemplate(custom *unitv1alpha1.CustomTemplateSpec, selector labels.Selector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if custom.Kind == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("kind"), ""))
+	}
+	if custom.APIVersion == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("apiVersion"), ""))
+	} else if gv, err := schema.ParseGroupVersion(custom.APIVersion); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("apiVersion"), custom.APIVersion, err.Error()))
+	} else if gv.Group == appsv1.GroupName && (custom.Kind == "StatefulSet" || custom.Kind == "Deployment") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("kind"), custom.Kind,
+			"use statefulSetTemplate or deploymentTemplate instead"))
+	}
+
+	template := &unstructured.Unstructured{}
+	if err := json.Unmarshal(custom.Template.Raw, &template.Object); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("template"), string(custom.Template.Raw),
+			fmt.Sprintf("should be a workload object: %v", err)))
+		return allErrs
+	}
+	if !selector.Matches(labels.Set(template.GetLabels())) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("template", "metadata", "labels"), template.GetLabels(),
+			"`selector` does not match template `labels`"))
+	}
+	return allErrs
+}
+
 func validatePodTemplateSpec(template *core.PodTemplateSpec, selector labels.Selector, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if template == nil {
@@ -18,80 +18,372 @@ package validating
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/controller/yurtingress/backend"
+)
+
+const (
+	// minNodePort and maxNodePort are the default --service-node-port-range of the apiserver,
+	// the fixed node ports out of it would only be rejected when the pool service is created.
+	minNodePort = 30000
+	maxNodePort = 32767
 )
 
 // validateYurtIngressSpec validates the yurt ingress spec.
 func validateYurtIngressSpec(c client.Client, ingressName string, spec *appsv1alpha1.YurtIngressSpec, isdelete bool) field.ErrorList {
-	if len(spec.Pools) > 0 {
-		var err error
-		var errmsg string
+	if !isdelete {
+		if allErrs := validateControllerType(spec); len(allErrs) > 0 {
+			return allErrs
+		}
+		if allErrs := validatePoolOverrides(spec); len(allErrs) > 0 {
+			return allErrs
+		}
+		if allErrs := validatePoolSelector(spec); len(allErrs) > 0 {
+			return allErrs
+		}
+		if allErrs := validateUpdateStrategy(spec); len(allErrs) > 0 {
+			return allErrs
+		}
+		if allErrs := validateNamespace(spec); len(allErrs) > 0 {
+			return allErrs
+		}
+		if allErrs := validateTemplateSet(c, spec); len(allErrs) > 0 {
+			return allErrs
+		}
+	}
+	if len(spec.Pools) == 0 && spec.PoolSelector == nil {
+		return nil
+	}
+	var errmsg string
 
-		ingressList := appsv1alpha1.YurtIngressList{}
-		if err = c.List(context.TODO(), &ingressList, &client.ListOptions{}); err != nil {
-			errmsg = "List YurtIngressList error!"
+	nps := appsv1alpha1.NodePoolList{}
+	if err := c.List(context.TODO(), &nps, &client.ListOptions{}); err != nil {
+		errmsg = "List nodepool list error!"
+		klog.Errorf(errmsg)
+		return field.ErrorList([]*field.Error{
+			field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
+	}
+	nodePools := make(map[string]*appsv1alpha1.NodePool, len(nps.Items))
+	for i := range nps.Items {
+		nodePools[nps.Items[i].Name] = &nps.Items[i]
+	}
+
+	// validate whether the nodepool exist
+	if len(nps.Items) > 0 {
+		for _, snp := range spec.Pools { //go through the nodepools setting in yaml
+			if nodePools[snp.Name] == nil {
+				errmsg = snp.Name + " does not exist in the cluster!"
+				klog.Errorf(errmsg)
+				return field.ErrorList([]*field.Error{
+					field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
+			}
+		}
+	}
+	if isdelete {
+		return nil
+	}
+
+	ingressList := appsv1alpha1.YurtIngressList{}
+	if err := c.List(context.TODO(), &ingressList, &client.ListOptions{}); err != nil {
+		errmsg = "List YurtIngressList error!"
+		klog.Errorf(errmsg)
+		return field.ErrorList([]*field.Error{
+			field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
+	}
+	// the effective pools are the listed pools and the nodepools selected by the pool selector, a nodepool is
+	// enabled by at most one yurtingress of the same type of controllers in the same namespace, the ingress
+	// controllers of a nodepool in different namespaces do not conflict
+	ying := &appsv1alpha1.YurtIngress{ObjectMeta: metav1.ObjectMeta{Name: ingressName}, Spec: *spec}
+	pools, err := backend.DesiredPools(ying, nodePools)
+	if err != nil {
+		return field.ErrorList([]*field.Error{
+			field.Invalid(field.NewPath("spec").Child("poolSelector"), spec.PoolSelector, err.Error())})
+	}
+	npIngressMap := make(map[string]string)
+	for i := range ingressList.Items { //go through all the yurtingress
+		ingress := &ingressList.Items[i]
+		if ingress.Name == ingressName || !backend.IsSameControllers(ying, ingress) {
+			continue
+		}
+		ingressPools, err := backend.DesiredPools(ingress, nodePools)
+		if err != nil {
+			klog.Errorf("%v", err)
+			continue
+		}
+		for _, np := range ingressPools { //get all the nodepools with ingress enabled
+			npIngressMap[np.Name] = ingress.Name
+		}
+	}
+	// check if ingress is already enabled in certain nodepool
+	for _, pool := range pools {
+		if owner, ok := npIngressMap[pool.Name]; ok {
+			fldPath := field.NewPath("spec").Child("pools")
+			if !backend.IsPoolListed(ying, pool.Name) {
+				fldPath = field.NewPath("spec").Child("poolSelector")
+			}
+			errmsg = "Nodepool \"" + pool.Name + "\" has been enabled in \"" + owner + "\" already!"
 			klog.Errorf(errmsg)
-			return field.ErrorList([]*field.Error{
-				field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
+			return field.ErrorList([]*field.Error{field.Forbidden(fldPath, errmsg)})
 		}
+	}
+	return nil
+}
 
-		//get all the nodepools with ingress enabled
-		var npIngressMap map[string]string = make(map[string]string)
-		if !isdelete && len(ingressList.Items) > 0 {
-			for _, ingress := range ingressList.Items { //go through all the yurtingress
-				for _, np := range ingress.Spec.Pools { //get all the nodepools with ingress enabled
-					npIngressMap[np.Name] = ingress.ObjectMeta.Name
-				}
+// validateControllerType validates the ingress controller type and its templates.
+func validateControllerType(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec")
+	switch spec.ControllerType {
+	case "", appsv1alpha1.NginxIngressController, appsv1alpha1.TraefikIngressController:
+		if spec.ControllerTemplate != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("controllerTemplate"),
+				"controllerTemplate is only used by the template controller type"))
+		}
+	case appsv1alpha1.TemplateIngressController:
+		if spec.TemplateSet != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("templateSet"),
+				"templateSet is not used by the template controller type, use controllerTemplate instead"))
+		}
+		if spec.ControllerTemplate == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("controllerTemplate"),
+				"controllerTemplate is required by the template controller type"))
+			break
+		}
+		if spec.ControllerTemplate.Namespace == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("controllerTemplate", "namespace"), ""))
+		}
+		if spec.ControllerTemplate.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("controllerTemplate", "name"), ""))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("controllerType"), spec.ControllerType,
+			[]string{string(appsv1alpha1.NginxIngressController), string(appsv1alpha1.TraefikIngressController),
+				string(appsv1alpha1.TemplateIngressController)}))
+	}
+	if spec.ControllerType == appsv1alpha1.TraefikIngressController {
+		// traefik is configured by its arguments rather than a configmap
+		if len(spec.Config) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("config"),
+				"config is not supported by the traefik controller type, use extraArgs of the pools instead"))
+		}
+		for i, pool := range spec.Pools {
+			if len(pool.Config) > 0 {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("pools").Index(i).Child("config"),
+					"config is not supported by the traefik controller type, use extraArgs of the pools instead"))
 			}
 		}
+	}
+	return allErrs
+}
 
-		nps := appsv1alpha1.NodePoolList{}
-		if err = c.List(context.TODO(), &nps, &client.ListOptions{}); err != nil {
-			errmsg = "List nodepool list error!"
-			klog.Errorf(errmsg)
-			return field.ErrorList([]*field.Error{
-				field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
-		}
-
-		// validate whether the nodepool exist
-		if len(nps.Items) > 0 {
-			var found = false
-			for _, snp := range spec.Pools { //go through the nodepools setting in yaml
-				for _, cnp := range nps.Items { //go through the nodepools in cluster
-					if snp.Name == cnp.ObjectMeta.Name {
-						// check if ingress is already enabled in certain nodepool
-						for inp := range npIngressMap {
-							if snp.Name == inp && ingressName != npIngressMap[inp] {
-								errmsg = "Nodepool \"" + snp.Name + "\" has been enabled in \"" + npIngressMap[inp] + "\" already!"
-								klog.Errorf(errmsg)
-								return field.ErrorList([]*field.Error{
-									field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
-							}
-						}
-						found = true
-						break
-					}
-				}
-				if !found {
-					errmsg = snp.Name + " does not exist in the cluster!"
-					klog.Errorf(errmsg)
-					return field.ErrorList([]*field.Error{
-						field.Forbidden(field.NewPath("spec").Child("pools"), errmsg)})
-				}
-				found = false
+// validateTemplateSet validates the reference to the template set, and the templates in it.
+func validateTemplateSet(c client.Client, spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	if spec.TemplateSet == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	fldPath := field.NewPath("spec").Child("templateSet")
+	if spec.TemplateSet.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), ""))
+	}
+	if spec.TemplateSet.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), ""))
+	}
+	if spec.TemplateSet.Version == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("version"), ""))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+	if _, err := backend.LoadTemplateSet(c, getControllerType(spec), spec.TemplateSet); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, spec.TemplateSet.Name, err.Error()))
+	}
+	return allErrs
+}
+
+// validatePoolOverrides validates the per-pool overrides of the ingress controllers.
+func validatePoolOverrides(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, pool := range spec.Pools {
+		fldPath := field.NewPath("spec").Child("pools").Index(i)
+		if pool.Replicas != nil && *pool.Replicas < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("replicas"), *pool.Replicas,
+				"replicas should not be negative"))
+		}
+		for j, arg := range pool.ExtraArgs {
+			if !strings.HasPrefix(arg, "-") {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("extraArgs").Index(j), arg,
+					"extra args should be flags"))
 			}
+		}
+		if pool.Service != nil {
+			allErrs = append(allErrs, validatePoolService(pool.Service, fldPath.Child("service"))...)
+		}
+	}
+	return append(allErrs, validateNodePortsUnique(spec)...)
+}
 
+// validateNodePortsUnique forbids the pools to share node ports, since node ports are allocated cluster wide.
+func validateNodePortsUnique(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	nodePorts := make(map[int32]bool)
+	for i, pool := range spec.Pools {
+		if pool.Service == nil {
+			continue
+		}
+		fldPath := field.NewPath("spec").Child("pools").Index(i).Child("service")
+		ports := map[string]int32{"httpNodePort": pool.Service.HTTPNodePort, "httpsNodePort": pool.Service.HTTPSNodePort}
+		for _, name := range []string{"httpNodePort", "httpsNodePort"} {
+			port := ports[name]
+			if port == 0 {
+				continue
+			}
+			if nodePorts[port] {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Child(name), port))
+			}
+			nodePorts[port] = true
 		}
 	}
-	return nil
+	return allErrs
+}
+
+// validatePoolService validates the service exposure of the ingress controller of a pool.
+func validatePoolService(svc *appsv1alpha1.IngressPoolService, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if svc.Type == corev1.ServiceTypeClusterIP {
+		if svc.HTTPNodePort != 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("httpNodePort"),
+				"node port is not allowed for ClusterIP service"))
+		}
+		if svc.HTTPSNodePort != 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("httpsNodePort"),
+				"node port is not allowed for ClusterIP service"))
+		}
+		if svc.ExternalTrafficPolicy != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("externalTrafficPolicy"),
+				"external traffic policy is not allowed for ClusterIP service"))
+		}
+		return allErrs
+	}
+	if svc.HTTPNodePort != 0 && (svc.HTTPNodePort < minNodePort || svc.HTTPNodePort > maxNodePort) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("httpNodePort"), svc.HTTPNodePort,
+			fmt.Sprintf("node port should be between %d and %d", minNodePort, maxNodePort)))
+	}
+	if svc.HTTPSNodePort != 0 && (svc.HTTPSNodePort < minNodePort || svc.HTTPSNodePort > maxNodePort) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("httpsNodePort"), svc.HTTPSNodePort,
+			fmt.Sprintf("node port should be between %d and %d", minNodePort, maxNodePort)))
+	}
+	return allErrs
+}
+
+// validatePoolSelector validates the label selector of the nodepools on which to enable ingress.
+func validatePoolSelector(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	if spec.PoolSelector == nil {
+		return nil
+	}
+	return metav1validation.ValidateLabelSelector(spec.PoolSelector, field.NewPath("spec").Child("poolSelector"))
+}
+
+// validateUpdateStrategy validates the update strategy of the ingress controllers of the pools.
+func validateUpdateStrategy(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	strategy := spec.UpdateStrategy
+	if strategy == nil {
+		return nil
+	}
+	fldPath := field.NewPath("spec").Child("updateStrategy")
+	if strategy.MaxUnavailable != nil {
+		value, err := intstr.GetScaledValueFromIntOrPercent(strategy.MaxUnavailable, 100, true)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), strategy.MaxUnavailable.String(),
+				err.Error()))
+		} else if value <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("maxUnavailable"), strategy.MaxUnavailable.String(),
+				"maxUnavailable should be positive"))
+		}
+	}
+	// the pools selected by the pool selector are not known until they are reconciled
+	var pools map[string]bool
+	if spec.PoolSelector == nil {
+		pools = make(map[string]bool, len(spec.Pools))
+		for _, pool := range spec.Pools {
+			pools[pool.Name] = true
+		}
+	}
+	allErrs = append(allErrs, validatePoolList(strategy.Order, pools, fldPath.Child("order"))...)
+	allErrs = append(allErrs, validatePoolList(strategy.Canary, pools, fldPath.Child("canary"))...)
+	return allErrs
+}
+
+// validatePoolList validates that the listed pools are enabled and unique, the pools are not checked if nil.
+func validatePoolList(list []string, pools map[string]bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := make(map[string]bool, len(list))
+	for i, pool := range list {
+		if pools != nil && !pools[pool] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i), pool))
+		}
+		if seen[pool] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), pool))
+		}
+		seen[pool] = true
+	}
+	return allErrs
+}
+
+// validateNamespace validates the namespace of the ingress controllers, which is required by the template
+// controller type as it has no default namespace.
+func validateNamespace(spec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	if spec.Namespace == "" {
+		if spec.ControllerType == appsv1alpha1.TemplateIngressController {
+			return field.ErrorList{field.Required(field.NewPath("spec").Child("namespace"),
+				"namespace is required by the template controller type")}
+		}
+		return nil
+	}
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(spec.Namespace) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("namespace"), spec.Namespace, msg))
+	}
+	return allErrs
+}
+
+// getNamespace returns the namespace where the ingress controllers are deployed.
+func getNamespace(spec *appsv1alpha1.YurtIngressSpec) string {
+	namespace, _ := backend.Namespace(&appsv1alpha1.YurtIngress{Spec: *spec})
+	return namespace
+}
+
+func getControllerType(spec *appsv1alpha1.YurtIngressSpec) appsv1alpha1.IngressControllerType {
+	if spec.ControllerType == "" {
+		return appsv1alpha1.NginxIngressController
+	}
+	return spec.ControllerType
 }
 
 func validateYurtIngressSpecUpdate(c client.Client, ingressName string, spec *appsv1alpha1.YurtIngressSpec, oldSpec *appsv1alpha1.YurtIngressSpec) field.ErrorList {
+	// the resources of the deployed ingress controllers are not migrated to another type
+	if getControllerType(spec) != getControllerType(oldSpec) {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("controllerType"),
+			"controllerType is immutable")}
+	}
+	// the common resources of the deployed ingress controllers are owned in the namespace
+	if getNamespace(spec) != getNamespace(oldSpec) {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("namespace"),
+			"namespace is immutable")}
+	}
 	return validateYurtIngressSpec(c, ingressName, spec, false)
 }
 
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validating
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appsv1alpha1 "github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1alpha1"
+)
+
+var edgeLabels = map[string]string{"ingress": "enabled"}
+
+func newTestClient(objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = appsv1alpha1.AddToScheme(scheme)
+	objs = append(objs,
+		&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "hangzhou", Labels: edgeLabels}},
+		&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "beijing", Labels: edgeLabels}},
+		&appsv1alpha1.NodePool{ObjectMeta: metav1.ObjectMeta{Name: "shanghai"}})
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func newYurtIngress(name, namespace string, selector map[string]string, pools ...string) *appsv1alpha1.YurtIngress {
+	ying := &appsv1alpha1.YurtIngress{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       appsv1alpha1.YurtIngressSpec{Namespace: namespace},
+	}
+	if selector != nil {
+		ying.Spec.PoolSelector = &metav1.LabelSelector{MatchLabels: selector}
+	}
+	for _, pool := range pools {
+		ying.Spec.Pools = append(ying.Spec.Pools, appsv1alpha1.IngressPool{Name: pool})
+	}
+	return ying
+}
+
+func TestValidatePoolSelectorConflicts(t *testing.T) {
+	tests := map[string]struct {
+		existing  *appsv1alpha1.YurtIngress
+		ying      *appsv1alpha1.YurtIngress
+		expectErr bool
+	}{
+		"selects a pool listed by another": {
+			existing:  newYurtIngress("listing", "", nil, "hangzhou"),
+			ying:      newYurtIngress("selecting", "", edgeLabels),
+			expectErr: true,
+		},
+		"lists a pool selected by another": {
+			existing:  newYurtIngress("selecting", "", edgeLabels),
+			ying:      newYurtIngress("listing", "", nil, "beijing"),
+			expectErr: true,
+		},
+		"lists a pool not selected by another": {
+			existing: newYurtIngress("selecting", "", edgeLabels),
+			ying:     newYurtIngress("listing", "", nil, "shanghai"),
+		},
+		"updates itself": {
+			existing: newYurtIngress("selecting", "", edgeLabels),
+			ying:     newYurtIngress("selecting", "", edgeLabels, "shanghai"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(test.existing)
+			errs := validateYurtIngressSpec(c, test.ying.Name, &test.ying.Spec, false)
+			if test.expectErr != (len(errs) > 0) {
+				t.Fatalf("expected error %v, got %v", test.expectErr, errs)
+			}
+		})
+	}
+}
+
+func TestValidateOverlappingPoolSelectors(t *testing.T) {
+	existing := newYurtIngress("edge", "", edgeLabels)
+	c := newTestClient(existing)
+
+	// a different selector which selects hangzhou and beijing too
+	overlapping := newYurtIngress("overlapping", "", nil)
+	overlapping.Spec.PoolSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "ingress", Operator: metav1.LabelSelectorOpExists}}}
+	if errs := validateYurtIngressSpec(c, overlapping.Name, &overlapping.Spec, false); len(errs) == 0 {
+		t.Fatalf("expected overlapping pool selectors in the same namespace to be rejected")
+	}
+	// the ingress controllers in another namespace do not conflict
+	tenant := newYurtIngress("tenant", "tenant", edgeLabels)
+	if errs := validateYurtIngressSpec(c, tenant.Name, &tenant.Spec, false); len(errs) > 0 {
+		t.Fatalf("expected overlapping pool selectors in different namespaces to be allowed, got %v", errs)
+	}
+	// neither do the ingress controllers of another type
+	traefik := newYurtIngress("traefik", "", edgeLabels)
+	traefik.Spec.ControllerType = appsv1alpha1.TraefikIngressController
+	if errs := validateYurtIngressSpec(c, traefik.Name, &traefik.Spec, false); len(errs) > 0 {
+		t.Fatalf("expected overlapping pool selectors of different controller types to be allowed, got %v", errs)
+	}
+	// a selector which selects none of the enabled pools does not conflict
+	other := newYurtIngress("other", "", map[string]string{"ingress": "disabled"})
+	if errs := validateYurtIngressSpec(c, other.Name, &other.Spec, false); len(errs) > 0 {
+		t.Fatalf("expected disjoint pool selectors to be allowed, got %v", errs)
+	}
+}
+
+func TestValidateTemplateNamespace(t *testing.T) {
+	ying := newYurtIngress("haproxy", "", nil, "hangzhou")
+	ying.Spec.ControllerType = appsv1alpha1.TemplateIngressController
+	ying.Spec.ControllerTemplate = &appsv1alpha1.IngressControllerTemplate{Namespace: "kube-system", Name: "haproxy"}
+	if errs := validateYurtIngressSpec(newTestClient(), ying.Name, &ying.Spec, false); len(errs) == 0 {
+		t.Fatalf("expected the template controller type without namespace to be rejected")
+	}
+	ying.Spec.Namespace = "haproxy-ingress"
+	if errs := validateYurtIngressSpec(newTestClient(), ying.Name, &ying.Spec, false); len(errs) > 0 {
+		t.Fatalf("expected the template controller type with namespace to be allowed, got %v", errs)
+	}
+}
+
+func TestValidatePoolServiceNodePorts(t *testing.T) {
+	tests := map[string]struct {
+		svc     appsv1alpha1.IngressPoolService
+		invalid bool
+	}{
+		"random node ports":        {svc: appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeNodePort}},
+		"node ports in the range":  {svc: appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeNodePort, HTTPNodePort: 30000, HTTPSNodePort: 32767}},
+		"http node port too small": {svc: appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeNodePort, HTTPNodePort: 8080}, invalid: true},
+		"https node port too big":  {svc: appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeLoadBalancer, HTTPSNodePort: 32768}, invalid: true},
+		"negative node port":       {svc: appsv1alpha1.IngressPoolService{Type: corev1.ServiceTypeNodePort, HTTPNodePort: -1}, invalid: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := validatePoolService(&tt.svc, field.NewPath("spec", "pools").Index(0).Child("service"))
+			if tt.invalid != (len(errs) > 0) {
+				t.Errorf("expected invalid %v, got %v", tt.invalid, errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+//go:build gofuzz
+// +build gofuzz
+
+/*
+Copyright 2022 The OpenYurt Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"reflect"
+
+	fuzz "github.com/AdaLogics/go-fuzz-headers"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/openyurtio/yurt-app-manager/pkg/yurtappmanager/apis/apps/v1beta1"
+)
+
+// FuzzConversion checks that the v1alpha1 objects are converted to v1beta1 and back without any loss.
+func FuzzConversion(data []byte) int {
+	f := fuzz.NewConsumer(data)
+	kind, err := f.GetInt()
+	if err != nil {
+		return 0
+	}
+
+	var spoke, got conversion.Convertible
+	var hub conversion.Hub
+	switch kind % 4 {
+	case 0:
+		spoke, got, hub = &NodePool{}, &NodePool{}, &v1beta1.NodePool{}
+	case 1:
+		spoke, got, hub = &YurtAppSet{}, &YurtAppSet{}, &v1beta1.YurtAppSet{}
+	case 2:
+		spoke, got, hub = &YurtAppDaemon{}, &YurtAppDaemon{}, &v1beta1.YurtAppDaemon{}
+	default:
+		spoke, got, hub = &YurtIngress{}, &YurtIngress{}, &v1beta1.YurtIngress{}
+	}
+	if err := f.GenerateStruct(spoke); err != nil {
+		return 0
+	}
+	// the conversion webhook sets the TypeMeta of the converted objects
+	spoke.(metav1.Type).SetKind("")
+	spoke.(metav1.Type).SetAPIVersion("")
+
+	if err := spoke.ConvertTo(hub); err != nil {
+		panic(fmt.Sprintf("fail to convert to v1beta1: %v", err))
+	}
+	if err := got.ConvertFrom(hub); err != nil {
+		panic(fmt.Sprintf("fail to convert from v1beta1: %v", err))
+	}
+	if !reflect.DeepEqual(spoke, got) {
+		panic(fmt.Sprintf("v1alpha1 is changed by the round trip:\n%#v\n%#v", spoke, got))
+	}
+	return 1
+}
@@ -26,6 +26,7 @@ cd "${GO_SRC}"
 # This removes dependency noises from the modules' go.mod and go.sum files.
 cp "${PROJECT_PATH}/tests/fuzz/yurtappdaemon_fuzzer.go" "${PROJECT_PATH}/pkg/yurtappmanager/controller/yurtappdaemon/yurtappdaemon_fuzzer.go"
 cp "${PROJECT_PATH}/tests/fuzz/yurtappset_fuzzer.go" "${PROJECT_PATH}/pkg/yurtappmanager/controller/yurtappset/yurtappset_fuzzer.go"
+cp "${PROJECT_PATH}/tests/fuzz/conversion_fuzzer.go" "${PROJECT_PATH}/pkg/yurtappmanager/apis/apps/v1alpha1/conversion_fuzzer.go"
 
 # compile fuzz tests for the runtime module
 pushd "${PROJECT_PATH}"
@@ -33,5 +34,6 @@ pushd "${PROJECT_PATH}"
 go get -d github.com/AdaLogics/go-fuzz-headers
 compile_go_fuzzer "${PROJECT_PATH}/pkg/yurtappmanager/controller/yurtappdaemon/" FuzzAppDaemonReconcile fuzz_yurtappdaemon_controller
 compile_go_fuzzer "${PROJECT_PATH}/pkg/yurtappmanager/controller/yurtappset/" FuzzAppSetReconcile fuzz_yurtappset_controller
+compile_go_fuzzer "${PROJECT_PATH}/pkg/yurtappmanager/apis/apps/v1alpha1/" FuzzConversion fuzz_apps_conversion
 
 popd